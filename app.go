@@ -3,29 +3,405 @@ package main
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"os"
+	goruntime "runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 // App struct
 type App struct {
 	ctx context.Context
+
+	mu     sync.Mutex
+	docs   map[string]*Document
+	order  []string // document IDs in the order they were opened
+	active string   // document the single-document methods act on
+	seq    int      // last document sequence number handed out
+
+	settings Settings
+	recent   []string // most recently used files, newest first
+	undo     *undoManager
+	spell    *spellChecker
+
+	clipboard []string // clipboard history, most recent first
+
+	bookmarks map[string][]int // bookmarked lines by file, nil until loaded
+
+	scratches map[string]*scratch // scratch buffers by ID, nil until loaded
+	scratchMu sync.Mutex          // serialises writing and removing scratch files
+
+	startupDocs []Document // opened from the command line
+
+	restored SessionState // session loaded at startup
+	session  SessionState // latest session reported by the frontend
+
+	autosaveInterval time.Duration
+	autosaveReset    chan struct{}
+
+	fileMu     sync.Mutex // serialises our own saves against the change poller
+	autoReload bool
+
+	workspace string       // folder opened with OpenFolder
+	treeWatch *treeWatcher // reports changes in the workspace
+
+	searches  map[string]context.CancelFunc // running folder searches by ID
+	searchSeq int
+
+	tray      *trayIcon // nil when there is no tray to show an icon in
+	hidden    bool      // window hidden to the tray
+	quitting  bool      // quit requested, closing must not hide to the tray
+	signalled bool      // quit on a signal, closing must not ask about unsaved changes
+
+	hotkey     string // registered global hotkey
+	hotkeyStop func() // unregisters it
+
+	recording   []EditorAction   // macro being recorded, nil when not recording
+	recorded    map[string]Macro // recordings not saved under a name, by ID
+	macroSeq    int
+	macroCancel context.CancelFunc // stops the macro playing
+
+	shareCancel context.CancelFunc // stops the ShareText upload
+
+	update       *updateState       // the release the last update check found
+	updateCancel context.CancelFunc // stops DownloadUpdate
+
+	log      *slog.Logger
+	logLevel *slog.LevelVar
+	logs     *logWriter
+
+	shutdownOnce sync.Once
+
+	bg     context.Context // cancelled on shutdown
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
 }
 
 // NewApp creates a new App application struct
 func NewApp() *App {
-	return &App{}
+	log, level, logs := newLogger()
+	return &App{
+		log:              log,
+		logLevel:         level,
+		logs:             logs,
+		docs:             make(map[string]*Document),
+		settings:         defaultSettings(),
+		undo:             newUndoManager(defaultUndoMemory),
+		spell:            newSpellChecker(),
+		autosaveInterval: defaultAutosaveInterval,
+		autosaveReset:    make(chan struct{}, 1),
+	}
 }
 
 // startup is called when the app starts. The context is saved
 // so we can call the runtime methods
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
+	a.log.Info("starting", "os", goruntime.GOOS, "arch", goruntime.GOARCH)
+	// documents opened below already start watchers and indexers under bg
+	bg := a.startBackground(ctx)
+
+	a.mu.Lock()
+	a.settings = loadSettings()
+	a.recent = loadRecentFiles()
+	if a.settings.PersistClipboard {
+		a.clipboard = loadClipboardHistory()
+	}
+	a.restored = loadSession()
+	a.session = a.restored
+	a.mu.Unlock()
+	restoreWindow(ctx, a.restored.Window)
+	a.applyWindowSettings()
+	runtime.OnFileDrop(ctx, func(x, y int, paths []string) {
+		a.HandleFileDrop(paths)
+	})
+
+	if wd, err := os.Getwd(); err == nil {
+		docs := a.openArgs(os.Args[1:], wd)
+		a.mu.Lock()
+		a.startupDocs = docs
+		a.mu.Unlock()
+	}
+	a.mu.Lock()
+	spec := a.settings.GlobalHotkey
+	hotkeyErr := a.registerHotkeyLocked(spec)
+	a.mu.Unlock()
+	if hotkeyErr != nil {
+		a.log.Warn("global hotkey not registered", "hotkey", spec, "err", hotkeyErr)
+	}
+
+	// the frontend announces itself once its listeners are in place
+	runtime.EventsOnce(ctx, "frontend:ready", func(...interface{}) {
+		for _, d := range a.GetStartupDocuments() {
+			runtime.EventsEmit(ctx, "file:opened", d)
+		}
+		if hotkeyErr != nil {
+			runtime.EventsEmit(ctx, "hotkey:error", hotkeyError(spec, hotkeyErr))
+		}
+	})
+
+	a.startTray()
+	// the menu was built before the settings and recent files were loaded
+	a.RefreshMenu()
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		a.autosaveLoop(bg)
+	}()
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		a.watchSignals(bg)
+	}()
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		a.checkForUpdatesLater(bg)
+	}()
+}
+
+// startBackground sets up the context that background work runs under
+// until shutdown cancels it
+func (a *App) startBackground(ctx context.Context) context.Context {
+	bg, cancel := context.WithCancel(ctx)
+	a.mu.Lock()
+	a.bg, a.cancel = bg, cancel
+	a.mu.Unlock()
+	return bg
 }
 
-// Greet returns a greeting for the given name
-func (a *App) OpenFile() string {
-	return fmt.Sprintf("Hello, It's show time!")
+// OpenFile asks the user for a file and opens it as the active document.
+// Cancelling the dialog returns an empty result with no error.
+func (a *App) OpenFile() FileResult {
+	path, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Open File",
+	})
+	if err != nil {
+		return FileResult{Error: &FileError{Code: "dialog", Message: err.Error()}}
+	}
+	if path == "" {
+		return FileResult{}
+	}
+	d, err := a.OpenDocument(path)
+	if err != nil {
+		return FileResult{Path: path, Error: newFileError(path, err)}
+	}
+	return FileResult{
+		ID:         d.ID,
+		Path:       d.Path,
+		Content:    d.Content,
+		Encoding:   d.Encoding,
+		LineEnding: d.LineEnding,
+		Lossy:      d.Lossy,
+		ReadOnly:   d.ReadOnly,
+		LargeFile:  d.LargeFile,
+		Size:       d.Size,
+		Lines:      d.Lines,
+	}
 }
 
-func (a *App) SaveFile(name string, thing string) string {
-	return fmt.Sprintf("Hey  %s!!!!!!", name)
+// SaveFile writes content to name as the active document. When name is
+// empty the user is asked where to save, and the chosen path is returned so
+// the title can follow it. Cancelling that dialog returns an empty result
+// with no error.
+func (a *App) SaveFile(name string, content string) FileResult {
+	a.mu.Lock()
+	d := a.activeLocked()
+	id, title := d.ID, d.Name()
+	a.mu.Unlock()
+	return a.saveAs(id, title, name, content)
+}
+
+// saveAs saves a document to name, asking for a file name when it is empty
+func (a *App) saveAs(id, title, name, content string) FileResult {
+	if name == "" {
+		path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+			Title:           "Save File",
+			DefaultFilename: title,
+		})
+		if err != nil {
+			return FileResult{ID: id, Error: &FileError{Code: "dialog", Message: err.Error()}}
+		}
+		if path == "" {
+			return FileResult{ID: id}
+		}
+		name = path
+	}
+	if err := a.saveDocument(id, name, content); err != nil {
+		return FileResult{ID: id, Path: name, Error: newFileError(name, err)}
+	}
+	d, err := a.GetDocument(id)
+	if err != nil {
+		return FileResult{ID: id, Path: name, Error: newFileError(name, err)}
+	}
+	return FileResult{ID: id, Path: d.Path, Encoding: d.Encoding, LineEnding: d.LineEnding}
+}
+
+// UpdateContent records the active buffer and marks it modified
+func (a *App) UpdateContent(content string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	d := a.activeLocked()
+	if d.LargeFile {
+		return
+	}
+	d.Content = content
+	d.Dirty = true
+	d.version++
+}
+
+// SetDirty lets the frontend mark the active buffer modified or clean
+func (a *App) SetDirty(dirty bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.activeLocked().Dirty = dirty
+}
+
+// IsDirty reports whether the active buffer has unsaved changes
+func (a *App) IsDirty() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if d, ok := a.docs[a.active]; ok {
+		return d.Dirty
+	}
+	return false
+}
+
+// GetEncoding returns the encoding the active buffer will be saved with
+func (a *App) GetEncoding() string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.activeLocked().Encoding
+}
+
+// SetEncoding changes the encoding used by the next save
+func (a *App) SetEncoding(enc string) error {
+	if !validEncoding(enc) {
+		return fmt.Errorf("unsupported encoding %q, expected one of %s", enc, strings.Join(encodings, ", "))
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.activeLocked().Encoding = enc
+	return nil
+}
+
+// GetLineEnding returns the line ending style of the active buffer
+func (a *App) GetLineEnding() string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.activeLocked().LineEnding
+}
+
+// SetLineEnding converts the active buffer to le and returns the converted
+// text. Later saves write le to disk.
+func (a *App) SetLineEnding(le string) (string, error) {
+	if err := validLineEnding(le); err != nil {
+		return "", err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	d := a.activeLocked()
+	converted := convertLineEndings(d.Content, le)
+	if converted != d.Content || d.LineEnding != le {
+		d.Content = converted
+		d.Dirty = true
+		d.version++
+	}
+	d.LineEnding = le
+	return converted, nil
+}
+
+// beforeClose is called when the window is about to close. Each document
+// with unsaved changes is offered for saving, and returning true keeps the
+// window open.
+func (a *App) beforeClose(ctx context.Context) bool {
+	a.mu.Lock()
+	if a.hideToTrayLocked() {
+		a.mu.Unlock()
+		runtime.WindowHide(ctx)
+		return true
+	}
+	if a.signalled {
+		// nobody is there to answer; unsaved changes are kept as recovery
+		// files by shutdown
+		a.mu.Unlock()
+		a.captureWindow(ctx)
+		return false
+	}
+	var dirty []Document
+	for _, id := range a.order {
+		if d := a.docs[id]; d.Dirty {
+			dirty = append(dirty, *d)
+		}
+	}
+	a.mu.Unlock()
+
+	for _, d := range dirty {
+		choice, err := runtime.MessageDialog(ctx, runtime.MessageDialogOptions{
+			Type:          runtime.QuestionDialog,
+			Title:         "Unsaved Changes",
+			Message:       fmt.Sprintf("Save changes to %s before closing?", d.Name()),
+			Buttons:       []string{"Save", "Discard", "Cancel"},
+			DefaultButton: "Save",
+			CancelButton:  "Cancel",
+		})
+		if err != nil {
+			return true
+		}
+
+		switch closeAction(choice) {
+		case closeSave:
+			res := a.saveAs(d.ID, d.Name(), d.Path, d.Content)
+			if res.Error != nil {
+				runtime.MessageDialog(ctx, runtime.MessageDialogOptions{
+					Type:    runtime.ErrorDialog,
+					Title:   "Save Failed",
+					Message: res.Error.Message,
+				})
+				return true
+			}
+			// an empty path means the save dialog was cancelled
+			if res.Path == "" {
+				return true
+			}
+		case closeDiscard:
+			// keep the document so its file is still part of the session
+			a.mu.Lock()
+			if doc, ok := a.docs[d.ID]; ok {
+				doc.Dirty = false
+			}
+			a.mu.Unlock()
+			removeRecovery(d.recoveryKey())
+		default:
+			return true
+		}
+	}
+	a.captureWindow(ctx)
+	return false
+}
+
+type closeChoice int
+
+const (
+	closeCancel closeChoice = iota
+	closeSave
+	closeDiscard
+)
+
+// closeAction maps the unsaved changes dialog answer onto what to do.
+// Windows and Linux only offer Yes/No, so those stand in for Save/Discard
+// and dismissing the dialog cancels.
+func closeAction(button string) closeChoice {
+	switch button {
+	case "Save", "Yes":
+		return closeSave
+	case "Discard", "No":
+		return closeDiscard
+	default:
+		return closeCancel
+	}
 }
@@ -0,0 +1,25 @@
+package main
+
+import "testing"
+
+func TestCloseAction(t *testing.T) {
+	tests := []struct {
+		button string
+		want   closeChoice
+	}{
+		{"Save", closeSave},
+		{"Yes", closeSave},
+		{"Discard", closeDiscard},
+		{"No", closeDiscard},
+		{"", closeCancel},
+		{"Cancel", closeCancel},
+		// the dialog escaped or closed, or a button we do not know
+		{"save", closeCancel},
+		{"Don't Save", closeCancel},
+	}
+	for _, tt := range tests {
+		if got := closeAction(tt.button); got != tt.want {
+			t.Errorf("closeAction(%q) = %d, want %d", tt.button, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// openArgs opens the files named on a command line, resolving relative
+// paths against dir, or the working directory when dir is empty. "-" reads
+// a document from stdin, and a path that does not exist yet becomes an
+// empty document that will be created on save.
+func (a *App) openArgs(args []string, dir string) []Document {
+	var docs []Document
+	for _, arg := range args {
+		switch {
+		case arg == "-":
+			d, err := a.openStdin()
+			if err != nil {
+				runtime.LogErrorf(a.ctx, "reading stdin: %v", err)
+				continue
+			}
+			docs = append(docs, d)
+		case strings.HasPrefix(arg, "-"):
+			// flags are not ours to handle
+		default:
+			path := arg
+			if !filepath.IsAbs(path) && dir != "" {
+				path = filepath.Join(dir, path)
+			}
+			d, err := a.OpenDocument(path)
+			if isFileError(err, "not_found") {
+				d, err = a.newNamedDocument(path)
+			}
+			if err != nil {
+				runtime.LogErrorf(a.ctx, "opening %s: %v", path, err)
+				continue
+			}
+			docs = append(docs, d)
+		}
+	}
+	return docs
+}
+
+// isFileError reports whether err is a FileError with the given code
+func isFileError(err error, code string) bool {
+	var fe *FileError
+	return errors.As(err, &fe) && fe.Code == code
+}
+
+// openStdin reads stdin into a new untitled document
+func (a *App) openStdin() (Document, error) {
+	data, err := io.ReadAll(io.LimitReader(os.Stdin, maxFileSize+1))
+	if err != nil {
+		return Document{}, err
+	}
+	if len(data) > maxFileSize {
+		return Document{}, &FileError{Code: "too_large", Message: "stdin is larger than the file size limit"}
+	}
+	content, enc, lossy := decodeText(data)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	d := a.newDocumentLocked()
+	d.Content = content
+	d.Encoding = enc
+	d.LineEnding = detectLineEnding(content)
+	d.Language = detectLanguage("", languageSample(content)).ID
+	d.Lossy = lossy
+	d.Dirty = len(data) > 0
+	d.version++
+	return *d, nil
+}
+
+// newNamedDocument creates an empty document for a file that does not
+// exist yet, like Notepad does for a missing path on its command line. A
+// path named twice gives the same document, as with OpenDocument.
+func (a *App) newNamedDocument(path string) (Document, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return Document{}, newFileError(path, err)
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, id := range a.order {
+		if d := a.docs[id]; d.Path == abs {
+			a.active = id
+			return *d, nil
+		}
+	}
+	d := a.newDocumentLocked()
+	d.Path = abs
+	d.Language = detectLanguage(abs, "").ID
+	return *d, nil
+}
+
+// GetStartupDocuments returns the documents opened from the command line
+func (a *App) GetStartupDocuments() []Document {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]Document{}, a.startupDocs...)
+}
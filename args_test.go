@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOpenArgsWatches(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("hello\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	a := NewApp()
+	a.startBackground(context.Background())
+	defer func() {
+		a.cancel()
+		a.wg.Wait()
+	}()
+
+	docs := a.openArgs([]string{"notes.txt"}, dir)
+	if len(docs) != 1 {
+		t.Fatalf("openArgs opened %d documents, want 1", len(docs))
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	d := a.docs[docs[0].ID]
+	if d.watcher == nil || d.watcher.path != filepath.Join(dir, "notes.txt") {
+		t.Errorf("document opened from the command line has watcher %+v", d.watcher)
+	}
+}
+
+func TestOpenArgsMissingFile(t *testing.T) {
+	a := NewApp()
+	rel := filepath.Join("does-not-exist", "new.md")
+	docs := a.openArgs([]string{rel, rel}, "")
+	if len(docs) != 2 {
+		t.Fatalf("openArgs opened %d documents, want 2", len(docs))
+	}
+	want, err := filepath.Abs(rel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if docs[0].Path != want || docs[0].Language != "markdown" {
+		t.Errorf("missing file opened as %q (%s), want %q (markdown)", docs[0].Path, docs[0].Language, want)
+	}
+	if docs[1].ID != docs[0].ID {
+		t.Errorf("naming a missing file twice gave documents %s and %s", docs[0].ID, docs[1].ID)
+	}
+}
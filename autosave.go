@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// defaultAutosaveInterval is used until the frontend picks another one
+const defaultAutosaveInterval = 30 * time.Second
+
+// Recovery is a leftover autosave the frontend can offer to restore
+type Recovery struct {
+	Found   bool      `json:"found"`
+	Path    string    `json:"path"`
+	Content string    `json:"content"`
+	SavedAt time.Time `json:"savedAt"`
+}
+
+// recoveryPath returns where autosaves of the document with the given
+// recovery key are kept
+func recoveryPath(key string) (string, error) {
+	sum := sha256.Sum256([]byte(key))
+	return configPath("recovery", hex.EncodeToString(sum[:8])+".txt")
+}
+
+// removeRecovery deletes the autosave for key, if any
+func removeRecovery(key string) {
+	if rp, err := recoveryPath(key); err == nil {
+		os.Remove(rp)
+	}
+}
+
+// SetAutosaveInterval changes how often the buffer is autosaved.
+// Zero turns autosave off.
+func (a *App) SetAutosaveInterval(seconds int) error {
+	if seconds < 0 {
+		return errors.New("autosave interval must not be negative")
+	}
+	a.mu.Lock()
+	a.autosaveInterval = time.Duration(seconds) * time.Second
+	a.mu.Unlock()
+
+	// wake the loop so the new interval takes effect now
+	select {
+	case a.autosaveReset <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// CheckRecovery looks for an autosave of the active document that is newer
+// than the file on disk
+func (a *App) CheckRecovery() Recovery {
+	a.mu.Lock()
+	d := a.activeLocked()
+	path, key := d.Path, d.recoveryKey()
+	a.mu.Unlock()
+
+	rp, err := recoveryPath(key)
+	if err != nil {
+		return Recovery{}
+	}
+	info, err := os.Stat(rp)
+	if err != nil {
+		return Recovery{}
+	}
+	if path != "" {
+		if orig, err := os.Stat(path); err == nil && !info.ModTime().After(orig.ModTime()) {
+			return Recovery{}
+		}
+	}
+	data, err := os.ReadFile(rp)
+	if err != nil {
+		return Recovery{}
+	}
+	return Recovery{Found: true, Path: path, Content: string(data), SavedAt: info.ModTime()}
+}
+
+// autosaveLoop writes changed buffers to their recovery files on every tick
+// until ctx is cancelled
+func (a *App) autosaveLoop(ctx context.Context) {
+	for {
+		a.mu.Lock()
+		interval := a.autosaveInterval
+		a.mu.Unlock()
+
+		var tick <-chan time.Time
+		if interval > 0 {
+			tick = time.After(interval)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-a.autosaveReset:
+			continue
+		case <-tick:
+		}
+		a.autosave()
+	}
+}
+
+// autosave writes every dirty document that changed since its last autosave
+func (a *App) autosave() {
+	type pending struct {
+		doc     *Document
+		key     string
+		content string
+		version uint64
+	}
+	a.mu.Lock()
+	var todo []pending
+	for _, d := range a.docs {
+		if d.Dirty && d.version != d.autosaved {
+			todo = append(todo, pending{d, d.recoveryKey(), d.Content, d.version})
+		}
+	}
+	a.mu.Unlock()
+
+	for _, p := range todo {
+		rp, err := recoveryPath(p.key)
+		if err != nil {
+			return
+		}
+		if err := os.MkdirAll(filepath.Dir(rp), 0o700); err != nil {
+			return
+		}
+		if err := writeFile(rp, []byte(p.content), 0o644); err != nil {
+			a.log.Warn("autosave failed", "document", p.key, "err", err)
+			continue
+		}
+		a.mu.Lock()
+		p.doc.autosaved = p.version
+		a.mu.Unlock()
+	}
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// backupModes are the allowed values of Settings.BackupMode
+var backupModes = []string{"none", "simple", "versioned"}
+
+const (
+	defaultBackupKeep = 10
+	// backupStamp names versioned backups; a -N suffix tells apart saves
+	// made within the same second
+	backupStamp = "20060102-150405"
+)
+
+// BackupInfo describes one backup of a file
+type BackupInfo struct {
+	Path    string    `json:"path"`
+	Kind    string    `json:"kind"` // simple or versioned
+	Size    int64     `json:"size"`
+	Created time.Time `json:"created"`
+}
+
+// backupDir returns the folder holding the versioned backups of path.
+// Files are grouped by a hash of their full path so same-named files in
+// different folders keep separate histories.
+func backupDir(path string) (string, error) {
+	sum := sha256.Sum256([]byte(path))
+	return configPath("backups", hex.EncodeToString(sum[:8]))
+}
+
+// backupFile copies the current contents of path aside before it is
+// overwritten, according to the backup settings. A file that does not exist
+// yet needs no backup.
+func backupFile(path string, s Settings) error {
+	if s.BackupMode == "" || s.BackupMode == "none" {
+		return nil
+	}
+	src, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return backupError(path, err)
+	}
+	defer src.Close()
+
+	switch s.BackupMode {
+	case "simple":
+		data, err := io.ReadAll(src)
+		if err != nil {
+			return backupError(path, err)
+		}
+		if err := writeFile(path+".bak", data, 0o644); err != nil {
+			return backupError(path, err)
+		}
+		return nil
+	case "versioned":
+		dir, err := backupDir(path)
+		if err != nil {
+			return backupError(path, err)
+		}
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return backupError(path, err)
+		}
+		dst, err := createBackup(dir, filepath.Base(path), time.Now())
+		if err != nil {
+			return backupError(path, err)
+		}
+		_, err = io.Copy(dst, src)
+		if cerr := dst.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(dst.Name())
+			return backupError(path, err)
+		}
+		pruneBackups(path, s.BackupKeep)
+		return nil
+	}
+	return fmt.Errorf("unknown backup mode %q", s.BackupMode)
+}
+
+// createBackup claims a new backup file named after base and now. Names
+// already used in the same second are skipped, including ones pruned since,
+// so a later save always sorts after an earlier one.
+func createBackup(dir, base string, now time.Time) (*os.File, error) {
+	stamp := base + "." + now.Format(backupStamp)
+	n := 0
+	if entries, err := os.ReadDir(dir); err == nil {
+		for _, e := range entries {
+			rest, ok := strings.CutPrefix(e.Name(), stamp)
+			if !ok {
+				continue
+			}
+			used, _ := strconv.Atoi(strings.TrimPrefix(rest, "-"))
+			n = max(n, used+1)
+		}
+	}
+	for ; ; n++ {
+		name := stamp
+		if n > 0 {
+			name += "-" + strconv.Itoa(n)
+		}
+		f, err := os.OpenFile(filepath.Join(dir, name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if !errors.Is(err, fs.ErrExist) {
+			return f, err
+		}
+	}
+}
+
+func backupError(path string, err error) error {
+	return &FileError{Code: "backup", Message: fmt.Sprintf("could not back up %s before saving: %v", path, err)}
+}
+
+// versionedBackups lists the versioned backups of path, newest first
+func versionedBackups(path string) []BackupInfo {
+	dir, err := backupDir(path)
+	if err != nil {
+		return nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	type version struct {
+		info BackupInfo
+		seq  int
+	}
+	prefix := filepath.Base(path) + "."
+	var versions []version
+	for _, e := range entries {
+		stamp, ok := strings.CutPrefix(e.Name(), prefix)
+		if !ok || e.IsDir() || len(stamp) < len(backupStamp) {
+			continue
+		}
+		created, err := time.ParseInLocation(backupStamp, stamp[:len(backupStamp)], time.Local)
+		if err != nil {
+			continue
+		}
+		seq := 0
+		if rest := stamp[len(backupStamp):]; rest != "" {
+			if seq, err = strconv.Atoi(strings.TrimPrefix(rest, "-")); err != nil || rest[0] != '-' {
+				continue
+			}
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		versions = append(versions, version{
+			info: BackupInfo{Path: filepath.Join(dir, e.Name()), Kind: "versioned", Size: info.Size(), Created: created},
+			seq:  seq,
+		})
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		a, b := versions[i], versions[j]
+		if !a.info.Created.Equal(b.info.Created) {
+			return a.info.Created.After(b.info.Created)
+		}
+		return a.seq > b.seq
+	})
+	backups := make([]BackupInfo, len(versions))
+	for i, v := range versions {
+		backups[i] = v.info
+	}
+	return backups
+}
+
+// pruneBackups deletes all but the newest keep versioned backups of path
+func pruneBackups(path string, keep int) {
+	if keep < 1 {
+		keep = defaultBackupKeep
+	}
+	backups := versionedBackups(path)
+	for i := keep; i < len(backups); i++ {
+		os.Remove(backups[i].Path)
+	}
+}
+
+// ListBackups returns the backups of path, newest first, with the simple
+// .bak copy ahead of the versioned ones
+func (a *App) ListBackups(path string) []BackupInfo {
+	backups := []BackupInfo{}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return backups
+	}
+	if info, err := os.Stat(abs + ".bak"); err == nil && info.Mode().IsRegular() {
+		backups = append(backups, BackupInfo{Path: abs + ".bak", Kind: "simple", Size: info.Size(), Created: info.ModTime()})
+	}
+	return append(backups, versionedBackups(abs)...)
+}
+
+// RestoreBackup returns the text of a backup so the frontend can put it in
+// the editor. Saving it then backs up the version it replaces, so a restore
+// can itself be undone.
+func (a *App) RestoreBackup(backupPath string) (string, error) {
+	data, err := readTextFile(backupPath, maxFileSize)
+	if err != nil {
+		return "", newFileError(backupPath, err)
+	}
+	text, _, _ := decodeText(data)
+	return text, nil
+}
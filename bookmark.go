@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ToggleBookmark sets or clears the bookmark on a 1-based line of a file
+// and returns the file's bookmarks
+func (a *App) ToggleBookmark(path string, line int) ([]int, error) {
+	if line < 1 {
+		return nil, fmt.Errorf("invalid line %d", line)
+	}
+	key, err := filepath.Abs(path)
+	if err != nil {
+		return nil, newFileError(path, err)
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	marks := a.bookmarksLocked()
+	lines := marks[key]
+	i := sort.SearchInts(lines, line)
+	if i < len(lines) && lines[i] == line {
+		lines = append(lines[:i], lines[i+1:]...)
+	} else {
+		lines = append(lines[:i], append([]int{line}, lines[i:]...)...)
+	}
+	if len(lines) == 0 {
+		delete(marks, key)
+	} else {
+		marks[key] = lines
+	}
+	if err := saveBookmarks(marks); err != nil {
+		return nil, err
+	}
+	return append([]int{}, lines...), nil
+}
+
+// GetBookmarks returns the bookmarked lines of a file in order
+func (a *App) GetBookmarks(path string) []int {
+	key, err := filepath.Abs(path)
+	if err != nil {
+		return []int{}
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]int{}, a.bookmarksLocked()[key]...)
+}
+
+// ClearBookmarks removes every bookmark of a file
+func (a *App) ClearBookmarks(path string) error {
+	key, err := filepath.Abs(path)
+	if err != nil {
+		return newFileError(path, err)
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	marks := a.bookmarksLocked()
+	if _, ok := marks[key]; !ok {
+		return nil
+	}
+	delete(marks, key)
+	return saveBookmarks(marks)
+}
+
+// NextBookmark returns the first bookmarked line after fromLine, wrapping
+// around to the top of the file, or 0 when the file has no bookmarks
+func (a *App) NextBookmark(path string, fromLine int) int {
+	lines := a.GetBookmarks(path)
+	if len(lines) == 0 {
+		return 0
+	}
+	if i := sort.SearchInts(lines, fromLine+1); i < len(lines) {
+		return lines[i]
+	}
+	return lines[0]
+}
+
+// bookmarksLocked returns the bookmarks by file, loading them on first use.
+// a.mu must be held.
+func (a *App) bookmarksLocked() map[string][]int {
+	if a.bookmarks == nil {
+		a.bookmarks = loadBookmarks()
+	}
+	return a.bookmarks
+}
+
+// trimBookmarksLocked drops the bookmarks past the end of a file that now
+// has only lines lines. a.mu must be held.
+func (a *App) trimBookmarksLocked(path string, lines int) {
+	marks := a.bookmarksLocked()
+	kept := marks[path]
+	n := sort.SearchInts(kept, lines+1)
+	if n == len(kept) {
+		return
+	}
+	if n == 0 {
+		delete(marks, path)
+	} else {
+		marks[path] = kept[:n]
+	}
+	saveBookmarks(marks)
+}
+
+// moveBookmarksLocked hands the bookmarks of from, or of the files below it
+// when it is a folder, over to the same files under to. a.mu must be held.
+func (a *App) moveBookmarksLocked(from, to string) {
+	marks := a.bookmarksLocked()
+	moved := false
+	for path, lines := range marks {
+		rel, ok := underPath(from, path)
+		if !ok {
+			continue
+		}
+		delete(marks, path)
+		marks[filepath.Join(to, rel)] = lines
+		moved = true
+	}
+	if moved {
+		saveBookmarks(marks)
+	}
+}
+
+// lineCount returns the number of lines in text
+func lineCount(text string) int {
+	return strings.Count(text, "\n") + 1
+}
+
+// loadBookmarks reads the saved bookmarks
+func loadBookmarks() map[string][]int {
+	marks := map[string][]int{}
+	path, err := configPath("bookmarks.json")
+	if err != nil {
+		return marks
+	}
+	if err := readJSON(path, &marks); err != nil || marks == nil {
+		return map[string][]int{}
+	}
+	for p, lines := range marks {
+		sort.Ints(lines)
+		marks[p] = lines
+	}
+	return marks
+}
+
+// saveBookmarks persists the bookmarks by file
+func saveBookmarks(marks map[string][]int) error {
+	path, err := configPath("bookmarks.json")
+	if err != nil {
+		return err
+	}
+	return writeJSON(path, marks, 0o644)
+}
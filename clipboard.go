@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"unicode/utf8"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	// maxClipboardHistory is how many copies are remembered
+	maxClipboardHistory = 20
+	// maxClipboardEntry caps the size of a single remembered copy
+	maxClipboardEntry = 1 << 20
+)
+
+// truncateUTF8 cuts s to at most n bytes without splitting a rune
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// CopyToClipboard puts text on the OS clipboard and remembers it in the
+// clipboard history. Oversized copies are only partly remembered but always
+// copied in full.
+func (a *App) CopyToClipboard(text string) error {
+	if err := runtime.ClipboardSetText(a.ctx, text); err != nil {
+		return err
+	}
+
+	entry := truncateUTF8(text, maxClipboardEntry)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.clipboard) > 0 && a.clipboard[0] == entry {
+		return nil
+	}
+	a.clipboard = append([]string{entry}, a.clipboard...)
+	if len(a.clipboard) > maxClipboardHistory {
+		a.clipboard = a.clipboard[:maxClipboardHistory]
+	}
+	if a.settings.PersistClipboard {
+		saveClipboardHistory(a.clipboard)
+	}
+	return nil
+}
+
+// GetClipboardHistory returns remembered copies, most recent first
+func (a *App) GetClipboardHistory() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]string{}, a.clipboard...)
+}
+
+// PasteFromHistory returns a remembered copy and puts it back on the OS
+// clipboard
+func (a *App) PasteFromHistory(index int) (string, error) {
+	a.mu.Lock()
+	if index < 0 || index >= len(a.clipboard) {
+		n := len(a.clipboard)
+		a.mu.Unlock()
+		return "", fmt.Errorf("clipboard history has %d entries, no entry %d", n, index)
+	}
+	text := a.clipboard[index]
+	a.mu.Unlock()
+
+	if err := runtime.ClipboardSetText(a.ctx, text); err != nil {
+		return "", err
+	}
+	return text, nil
+}
+
+// loadClipboardHistory reads the persisted clipboard history
+func loadClipboardHistory() []string {
+	path, err := configPath("clipboard.json")
+	if err != nil {
+		return nil
+	}
+	var history []string
+	if err := readJSON(path, &history); err != nil {
+		return nil
+	}
+	if len(history) > maxClipboardHistory {
+		history = history[:maxClipboardHistory]
+	}
+	return history
+}
+
+// saveClipboardHistory persists the clipboard history. The file is only
+// private to the user, since copies may hold passwords and the like.
+func saveClipboardHistory(history []string) error {
+	path, err := configPath("clipboard.json")
+	if err != nil {
+		return err
+	}
+	return writeJSON(path, history, 0o600)
+}
+
+// removeClipboardHistory deletes the persisted clipboard history
+func removeClipboardHistory() {
+	if path, err := configPath("clipboard.json"); err == nil {
+		os.Remove(path)
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// codecModes are the modes accepted by EncodeDecode
+var codecModes = []string{
+	"base64-encode", "base64-decode",
+	"url-encode", "url-decode",
+	"html-escape", "html-unescape",
+	"hex-dump",
+}
+
+// EncodeDecode converts text with one of the codec modes. Decoding that
+// produces something other than UTF-8 text is refused unless force is set,
+// in which case invalid bytes become U+FFFD.
+func (a *App) EncodeDecode(text string, mode string, force bool) (string, error) {
+	switch mode {
+	case "base64-encode":
+		return base64.StdEncoding.EncodeToString([]byte(text)), nil
+	case "base64-decode":
+		data, err := decodeBase64(text)
+		if err != nil {
+			return "", err
+		}
+		return decodedText(data, force)
+	case "url-encode":
+		return url.QueryEscape(text), nil
+	case "url-decode":
+		s, err := url.QueryUnescape(text)
+		if err != nil {
+			var ee url.EscapeError
+			if errors.As(err, &ee) {
+				return "", fmt.Errorf("malformed percent-encoding %q", string(ee))
+			}
+			return "", err
+		}
+		return decodedText([]byte(s), force)
+	case "html-escape":
+		return html.EscapeString(text), nil
+	case "html-unescape":
+		return html.UnescapeString(text), nil
+	case "hex-dump":
+		return hex.Dump([]byte(text)), nil
+	default:
+		return "", fmt.Errorf("unknown mode %q, expected one of %s", mode, strings.Join(codecModes, ", "))
+	}
+}
+
+// decodeBase64 accepts standard or URL-safe base64, padded or not, and
+// ignores line breaks and other whitespace
+func decodeBase64(text string) ([]byte, error) {
+	s := strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, text)
+	enc := base64.StdEncoding
+	if strings.ContainsAny(s, "-_") {
+		enc = base64.URLEncoding
+	}
+	if !strings.HasSuffix(s, "=") && len(s)%4 != 0 {
+		enc = enc.WithPadding(base64.NoPadding)
+	}
+	data, err := enc.Strict().DecodeString(s)
+	if err != nil {
+		var ce base64.CorruptInputError
+		if errors.As(err, &ce) {
+			if int(ce) >= len(s) || s[ce] == '=' || len(s)%4 != 0 {
+				return nil, fmt.Errorf("invalid base64: bad padding or truncated input (%d characters)", len(s))
+			}
+			return nil, fmt.Errorf("invalid base64: unexpected %q at position %d", s[ce], int(ce)+1)
+		}
+		return nil, fmt.Errorf("invalid base64: %w", err)
+	}
+	return data, nil
+}
+
+// decodedText returns decoded bytes as a string, refusing binary data
+// unless force is set
+func decodedText(data []byte, force bool) (string, error) {
+	if utf8.Valid(data) {
+		return string(data), nil
+	}
+	if !force {
+		return "", fmt.Errorf("decoded %d bytes are binary data, not UTF-8 text; force the conversion to show it with replacement characters", len(data))
+	}
+	return strings.ToValidUTF8(string(data), string(utf8.RuneError)), nil
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxColumnSequence bounds the numbers GenerateColumnSequence makes at once
+const maxColumnSequence = 100000
+
+// Column positions below are 1-based and count runes, with a tab taking
+// up to the next multiple of the tab width the way the editor draws it.
+// A block runs from its start column up to but not including its end
+// column, as the editor reports a column selection.
+
+// ExtractColumnBlock returns the part of each line from startLine to
+// endLine that lies between startCol and endCol. Lines that stop short are
+// padded with spaces, so every line of the block is equally wide.
+func (a *App) ExtractColumnBlock(text string, startLine, endLine, startCol, endCol int) []string {
+	a.mu.Lock()
+	width := a.settings.TabWidth
+	a.mu.Unlock()
+
+	if startLine > endLine {
+		startLine, endLine = endLine, startLine
+	}
+	if startCol > endCol {
+		startCol, endCol = endCol, startCol
+	}
+	startLine, startCol = max(startLine, 1), max(startCol, 1)
+	endCol = max(endCol, startCol)
+	lines, _, _ := splitLines(text)
+	endLine = min(endLine, len(lines))
+	block := []string{}
+	for i := startLine; i <= endLine; i++ {
+		cells := padCells(expandTabs(lines[i-1], width), endCol-1)
+		block = append(block, string(cells[startCol-1:endCol-1]))
+	}
+	return block
+}
+
+// ApplyColumnBlock puts the lines of block into text one under the other,
+// the first at startLine and startCol. Mode insert shifts what follows to
+// the right and replace overwrites as many columns as the block is wide.
+// Lines too short to reach startCol are padded with spaces and lines are
+// added at the end if the block runs past it.
+func (a *App) ApplyColumnBlock(text string, startLine, startCol int, block []string, mode string) (string, error) {
+	if mode != "insert" && mode != "replace" {
+		return "", fmt.Errorf("unknown column mode %q, expected insert or replace", mode)
+	}
+	a.mu.Lock()
+	width := a.settings.TabWidth
+	a.mu.Unlock()
+
+	startLine, startCol = max(startLine, 1), max(startCol, 1)
+	lines, sep, trailing := splitLines(text)
+	if text == "" {
+		lines = []string{""}
+	}
+	blockWidth := 0
+	for _, b := range block {
+		blockWidth = max(blockWidth, utf8.RuneCountInString(b))
+	}
+	for i, b := range block {
+		n := startLine - 1 + i
+		for n >= len(lines) {
+			lines = append(lines, "")
+		}
+		prefix, rest := splitAtColumn(lines[n], startCol-1, width)
+		if mode == "replace" {
+			_, rest = splitAtColumn(lines[n], startCol-1+blockWidth, width)
+		}
+		piece := string(padCells([]rune(b), blockWidth))
+		if rest == "" {
+			// no trailing whitespace from padding at the end of a line
+			piece = strings.TrimRight(piece, " ")
+			if piece == "" {
+				prefix = strings.TrimRight(prefix, " ")
+			}
+		}
+		lines[n] = prefix + piece + rest
+	}
+	out := strings.Join(lines, sep)
+	if trailing {
+		out += sep
+	}
+	return out, nil
+}
+
+// GenerateColumnSequence returns count numbers from start going up by
+// step, for inserting down a column. padding is none, or zeros or spaces
+// to pad every number out to the width of the widest.
+func (a *App) GenerateColumnSequence(start, step, count int, padding string) ([]string, error) {
+	if padding != "" && padding != "none" && padding != "zeros" && padding != "spaces" {
+		return nil, fmt.Errorf("unknown padding %q, expected none, zeros or spaces", padding)
+	}
+	if count < 0 || count > maxColumnSequence {
+		return nil, fmt.Errorf("count must be between 0 and %d", maxColumnSequence)
+	}
+	seq := make([]string, count)
+	widest := 0
+	for i := range seq {
+		seq[i] = strconv.Itoa(start + i*step)
+		widest = max(widest, len(seq[i]))
+	}
+	for i, s := range seq {
+		switch pad := widest - len(s); {
+		case pad == 0:
+		case padding == "spaces":
+			seq[i] = strings.Repeat(" ", pad) + s
+		case padding == "zeros":
+			// the zeros go after the sign, -05 rather than 0-5
+			if digits, neg := strings.CutPrefix(s, "-"); neg {
+				seq[i] = "-" + strings.Repeat("0", pad) + digits
+			} else {
+				seq[i] = strings.Repeat("0", pad) + s
+			}
+		}
+	}
+	return seq, nil
+}
+
+// splitAtColumn splits line at the 0-based column col, padding it with
+// spaces when it is shorter. A tab that straddles col is replaced with the
+// spaces it stood for, so both halves keep their columns.
+func splitAtColumn(line string, col, width int) (string, string) {
+	v := 0
+	for i, r := range line {
+		if v == col {
+			return line[:i], line[i:]
+		}
+		w := 1
+		if r == '\t' {
+			w = width - v%width
+		}
+		if v+w > col {
+			// a tab across the split point
+			return line[:i] + strings.Repeat(" ", col-v), strings.Repeat(" ", v+w-col) + line[i+1:]
+		}
+		v += w
+	}
+	return line + strings.Repeat(" ", col-v), ""
+}
+
+// padCells pads cells with spaces to at least n
+func padCells(cells []rune, n int) []rune {
+	for len(cells) < n {
+		cells = append(cells, ' ')
+	}
+	return cells
+}
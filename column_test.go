@@ -0,0 +1,67 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestExtractColumnBlock(t *testing.T) {
+	tests := []struct {
+		name                                 string
+		text                                 string
+		startLine, endLine, startCol, endCol int
+		want                                 []string
+	}{
+		{"plain", "abcdef\nghijkl", 1, 2, 2, 4, []string{"bc", "hi"}},
+		{"reversed", "abcdef\nghijkl", 2, 1, 4, 2, []string{"bc", "hi"}},
+		{"short lines padded", "abcdef\ngh\n", 1, 2, 2, 5, []string{"bcd", "h  "}},
+		{"past the end", "ab", 1, 5, 1, 3, []string{"ab"}},
+		{"zero columns", "x", 1, 1, 0, 0, []string{""}},
+		{"zero line and negative end", "x\ny", 0, 2, 0, -2, []string{"", ""}},
+		{"negative", "x\ny\nz", -1, 3, -1, -2, []string{"", "", ""}},
+		{"multibyte", "héllo\nwörld", 1, 2, 2, 4, []string{"él", "ör"}},
+		{"tab expanded", "\tx\nab\tc", 1, 2, 3, 6, []string{"  x", "  c"}},
+	}
+	a := NewApp()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := a.ExtractColumnBlock(tt.text, tt.startLine, tt.endLine, tt.startCol, tt.endCol)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ExtractColumnBlock(%q, %d, %d, %d, %d) = %q, want %q", tt.text, tt.startLine, tt.endLine, tt.startCol, tt.endCol, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyColumnBlock(t *testing.T) {
+	tests := []struct {
+		name                string
+		text                string
+		startLine, startCol int
+		block               []string
+		mode                string
+		want                string
+	}{
+		{"insert", "abc\ndef\n", 1, 2, []string{"X", "Y"}, "insert", "aXbc\ndYef\n"},
+		{"replace", "abc\ndef", 1, 2, []string{"XY", "Z"}, "replace", "aXY\ndZ"},
+		{"pads short lines", "a\n", 1, 4, []string{"X"}, "insert", "a  X\n"},
+		{"adds lines", "a", 1, 1, []string{"X", "Y"}, "insert", "Xa\nY"},
+		{"multibyte", "héllo", 1, 3, []string{"ü"}, "replace", "héülo"},
+		{"splits a tab", "a\tb", 1, 3, []string{"X"}, "insert", "a X  b"},
+	}
+	a := NewApp()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := a.ApplyColumnBlock(tt.text, tt.startLine, tt.startCol, tt.block, tt.mode)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("ApplyColumnBlock = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if _, err := a.ApplyColumnBlock("x", 1, 1, nil, "overwrite"); err == nil {
+		t.Error("ApplyColumnBlock accepted an unknown mode")
+	}
+}
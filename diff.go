@@ -0,0 +1,350 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// DiffOptions controls which differences between lines are ignored
+type DiffOptions struct {
+	IgnoreWhitespace bool `json:"ignoreWhitespace"`
+	IgnoreCase       bool `json:"ignoreCase"`
+}
+
+// DiffHunk is a run of changed lines. Start is the 1-based first line of the
+// hunk on each side; on a side with Count 0 it is the line the change sits
+// before.
+type DiffHunk struct {
+	Type       string   `json:"type"` // add, remove or modify
+	LeftStart  int      `json:"leftStart"`
+	LeftCount  int      `json:"leftCount"`
+	RightStart int      `json:"rightStart"`
+	RightCount int      `json:"rightCount"`
+	Left       []string `json:"left"`
+	Right      []string `json:"right"`
+}
+
+// DiffResult is a line diff of two texts
+type DiffResult struct {
+	Hunks     []DiffHunk `json:"hunks"`
+	Added     int        `json:"added"`
+	Removed   int        `json:"removed"`
+	Identical bool       `json:"identical"`
+}
+
+// diffCostLimit bounds the edit distance searched before the differ settles
+// for a good split instead of the best one, keeping very different inputs
+// from going quadratic
+const diffCostLimit = 256
+
+// DiffDocuments compares two texts line by line
+func (a *App) DiffDocuments(leftContent, rightContent string, opts DiffOptions) DiffResult {
+	return diffText(leftContent, rightContent, opts)
+}
+
+// DiffFiles compares two files on disk
+func (a *App) DiffFiles(leftPath, rightPath string, opts DiffOptions) (DiffResult, error) {
+	left, err := readDiffFile(leftPath)
+	if err != nil {
+		return DiffResult{}, err
+	}
+	right, err := readDiffFile(rightPath)
+	if err != nil {
+		return DiffResult{}, err
+	}
+	return diffText(left, right, opts), nil
+}
+
+// DiffSaved compares the saved copy of a document on the left with its
+// current content on the right
+func (a *App) DiffSaved(docID string, opts DiffOptions) (DiffResult, error) {
+	a.mu.Lock()
+	d, err := a.docLocked(docID)
+	if err != nil {
+		a.mu.Unlock()
+		return DiffResult{}, err
+	}
+	path, content := d.Path, d.Content
+	a.mu.Unlock()
+
+	if path == "" {
+		return diffText("", content, opts), nil
+	}
+	saved, err := readDiffFile(path)
+	if err != nil {
+		return DiffResult{}, err
+	}
+	return diffText(saved, content, opts), nil
+}
+
+func readDiffFile(path string) (string, error) {
+	data, err := readTextFile(path, maxFileSize)
+	if err != nil {
+		return "", newFileError(path, err)
+	}
+	text, _, _ := decodeText(data)
+	return text, nil
+}
+
+// diffLines splits text into lines, an empty text having none
+func diffLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines, _, _ := splitLines(text)
+	return lines
+}
+
+func diffText(leftContent, rightContent string, opts DiffOptions) DiffResult {
+	left, right := diffLines(leftContent), diffLines(rightContent)
+
+	// lines are compared as small integers so the differ never touches
+	// the strings again
+	ids := make(map[string]int, len(left)+len(right))
+	intern := func(lines []string) []int {
+		out := make([]int, len(lines))
+		for i, l := range lines {
+			key := l
+			if opts.IgnoreWhitespace {
+				key = strings.Map(func(r rune) rune {
+					if unicode.IsSpace(r) {
+						return -1
+					}
+					return r
+				}, key)
+			}
+			if opts.IgnoreCase {
+				key = strings.ToLower(key)
+			}
+			id, ok := ids[key]
+			if !ok {
+				id = len(ids)
+				ids[key] = id
+			}
+			out[i] = id
+		}
+		return out
+	}
+
+	a, b := intern(left), intern(right)
+	removed, added := make([]bool, len(a)), make([]bool, len(b))
+
+	// a line missing from the other side can never match, so only lines
+	// present on both sides go through the differ
+	inA, inB := make([]bool, len(ids)), make([]bool, len(ids))
+	for _, id := range a {
+		inA[id] = true
+	}
+	for _, id := range b {
+		inB[id] = true
+	}
+	sa, ia := common(a, inB, removed)
+	sb, ib := common(b, inA, added)
+
+	d := &differ{a: sa, b: sb, removed: make([]bool, len(sa)), added: make([]bool, len(sb))}
+	d.compare(0, len(sa), 0, len(sb))
+	for k, i := range ia {
+		removed[i] = d.removed[k]
+	}
+	for k, j := range ib {
+		added[j] = d.added[k]
+	}
+	return diffResult(left, right, removed, added)
+}
+
+// common returns the lines of ids that occur on the other side along with
+// their original indexes, marking every other line as changed
+func common(ids []int, other []bool, changed []bool) ([]int, []int) {
+	var kept, index []int
+	for i, id := range ids {
+		if other[id] {
+			kept = append(kept, id)
+			index = append(index, i)
+		} else {
+			changed[i] = true
+		}
+	}
+	return kept, index
+}
+
+// differ is a linear-space Myers diff over interned lines. It marks which
+// lines on each side are not part of the common subsequence.
+type differ struct {
+	a, b           []int
+	removed, added []bool
+	vf, vb         []int
+}
+
+func (d *differ) compare(aLo, aHi, bLo, bHi int) {
+	for aLo < aHi && bLo < bHi && d.a[aLo] == d.b[bLo] {
+		aLo++
+		bLo++
+	}
+	for aLo < aHi && bLo < bHi && d.a[aHi-1] == d.b[bHi-1] {
+		aHi--
+		bHi--
+	}
+	switch {
+	case aLo == aHi:
+		for j := bLo; j < bHi; j++ {
+			d.added[j] = true
+		}
+		return
+	case bLo == bHi:
+		for i := aLo; i < aHi; i++ {
+			d.removed[i] = true
+		}
+		return
+	}
+
+	x, y, ok := d.split(aLo, aHi, bLo, bHi)
+	if !ok || (x == aLo && y == bLo) || (x == aHi && y == bHi) {
+		for i := aLo; i < aHi; i++ {
+			d.removed[i] = true
+		}
+		for j := bLo; j < bHi; j++ {
+			d.added[j] = true
+		}
+		return
+	}
+	d.compare(aLo, x, bLo, y)
+	d.compare(x, aHi, y, bHi)
+}
+
+// split finds a point on a shortest edit path through a[aLo:aHi] and
+// b[bLo:bHi] by running the search forwards and backwards until they meet
+func (d *differ) split(aLo, aHi, bLo, bHi int) (int, int, bool) {
+	n, m := aHi-aLo, bHi-bLo
+	maxD := (n + m + 1) / 2
+	offset, size := maxD, 2*maxD+2
+	if cap(d.vf) < size {
+		d.vf = make([]int, size)
+		d.vb = make([]int, size)
+	}
+	vf, vb := d.vf[:size], d.vb[:size]
+	for i := range vf {
+		vf[i] = -1
+		vb[i] = -1
+	}
+	vf[offset+1] = 0
+	vb[offset+1] = 0
+
+	delta := n - m
+	front := delta%2 != 0
+	// diagonals that ran off the edge of the grid are skipped
+	var k1start, k1end, k2start, k2end int
+	for step := 0; step < maxD; step++ {
+		if step > diffCostLimit {
+			return d.furthest(aLo, bLo, n, m, step, offset)
+		}
+		for k1 := -step + k1start; k1 <= step-k1end; k1 += 2 {
+			i := offset + k1
+			var x int
+			if k1 == -step || (k1 != step && vf[i-1] < vf[i+1]) {
+				x = vf[i+1]
+			} else {
+				x = vf[i-1] + 1
+			}
+			y := x - k1
+			for x < n && y < m && d.a[aLo+x] == d.b[bLo+y] {
+				x++
+				y++
+			}
+			vf[i] = x
+			switch {
+			case x > n:
+				k1end += 2
+			case y > m:
+				k1start += 2
+			case front:
+				if j := offset + delta - k1; j >= 0 && j < size && vb[j] != -1 && x >= n-vb[j] {
+					return aLo + x, bLo + y, true
+				}
+			}
+		}
+		for k2 := -step + k2start; k2 <= step-k2end; k2 += 2 {
+			j := offset + k2
+			var x int
+			if k2 == -step || (k2 != step && vb[j-1] < vb[j+1]) {
+				x = vb[j+1]
+			} else {
+				x = vb[j-1] + 1
+			}
+			y := x - k2
+			for x < n && y < m && d.a[aHi-x-1] == d.b[bHi-y-1] {
+				x++
+				y++
+			}
+			vb[j] = x
+			switch {
+			case x > n:
+				k2end += 2
+			case y > m:
+				k2start += 2
+			case !front:
+				if i := offset + delta - k2; i >= 0 && i < size && vf[i] != -1 {
+					x1 := vf[i]
+					y1 := x1 - (i - offset)
+					if x1 >= n-x {
+						return aLo + x1, bLo + y1, true
+					}
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// furthest gives up on an optimal split and returns the forward point that
+// got the furthest through both texts
+func (d *differ) furthest(aLo, bLo, n, m, step, offset int) (int, int, bool) {
+	bestX, bestY := -1, -1
+	for k := -step; k <= step; k++ {
+		x := d.vf[offset+k]
+		y := x - k
+		if x < 0 || x > n || y < 0 || y > m {
+			continue
+		}
+		if x+y > bestX+bestY {
+			bestX, bestY = x, y
+		}
+	}
+	if bestX < 0 {
+		return 0, 0, false
+	}
+	return aLo + bestX, bLo + bestY, true
+}
+
+// diffResult groups the changed lines into hunks
+func diffResult(left, right []string, removed, added []bool) DiffResult {
+	res := DiffResult{Hunks: []DiffHunk{}}
+	i, j := 0, 0
+	for i < len(left) || j < len(right) {
+		if i < len(left) && j < len(right) && !removed[i] && !added[j] {
+			i++
+			j++
+			continue
+		}
+		h := DiffHunk{LeftStart: i + 1, RightStart: j + 1}
+		for ; i < len(left) && removed[i]; i++ {
+			h.Left = append(h.Left, left[i])
+		}
+		for ; j < len(right) && added[j]; j++ {
+			h.Right = append(h.Right, right[j])
+		}
+		h.LeftCount, h.RightCount = len(h.Left), len(h.Right)
+		switch {
+		case h.LeftCount == 0:
+			h.Type = "add"
+		case h.RightCount == 0:
+			h.Type = "remove"
+		default:
+			h.Type = "modify"
+		}
+		res.Added += h.RightCount
+		res.Removed += h.LeftCount
+		res.Hunks = append(res.Hunks, h)
+	}
+	res.Identical = len(res.Hunks) == 0
+	return res
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffText(t *testing.T) {
+	tests := []struct {
+		name           string
+		left, right    string
+		opts           DiffOptions
+		want           []DiffHunk
+		added, removed int
+	}{
+		{"identical", "a\nb\n", "a\nb\n", DiffOptions{}, []DiffHunk{}, 0, 0},
+		{"both empty", "", "", DiffOptions{}, []DiffHunk{}, 0, 0},
+		{"from empty", "", "a\nb", DiffOptions{}, []DiffHunk{
+			{Type: "add", LeftStart: 1, RightStart: 1, RightCount: 2, Right: []string{"a", "b"}},
+		}, 2, 0},
+		{"add in the middle", "a\nc", "a\nb\nc", DiffOptions{}, []DiffHunk{
+			{Type: "add", LeftStart: 2, RightStart: 2, RightCount: 1, Right: []string{"b"}},
+		}, 1, 0},
+		{"remove at the end", "a\nb\nc", "a\nb", DiffOptions{}, []DiffHunk{
+			{Type: "remove", LeftStart: 3, LeftCount: 1, RightStart: 3, Left: []string{"c"}},
+		}, 0, 1},
+		{"modify", "a\nb\nc\nd", "a\nB\nc\nD\ne", DiffOptions{}, []DiffHunk{
+			{Type: "modify", LeftStart: 2, LeftCount: 1, RightStart: 2, RightCount: 1, Left: []string{"b"}, Right: []string{"B"}},
+			{Type: "modify", LeftStart: 4, LeftCount: 1, RightStart: 4, RightCount: 2, Left: []string{"d"}, Right: []string{"D", "e"}},
+		}, 3, 2},
+		{"line endings do not matter", "a\r\nb\r\n", "a\nb\n", DiffOptions{}, []DiffHunk{}, 0, 0},
+		{"whitespace counts", "a b\nc", "a  b\nc", DiffOptions{}, []DiffHunk{
+			{Type: "modify", LeftStart: 1, LeftCount: 1, RightStart: 1, RightCount: 1, Left: []string{"a b"}, Right: []string{"a  b"}},
+		}, 1, 1},
+		{"ignore whitespace", "a b\n\tc\nd", "a  b\nc \nd", DiffOptions{IgnoreWhitespace: true}, []DiffHunk{}, 0, 0},
+		{"case counts", "Hello", "hello", DiffOptions{}, []DiffHunk{
+			{Type: "modify", LeftStart: 1, LeftCount: 1, RightStart: 1, RightCount: 1, Left: []string{"Hello"}, Right: []string{"hello"}},
+		}, 1, 1},
+		{"ignore case", "Hello\nWORLD", "hello\nworld", DiffOptions{IgnoreCase: true}, []DiffHunk{}, 0, 0},
+		{"ignore both", "Hello  World", "helloworld", DiffOptions{IgnoreWhitespace: true, IgnoreCase: true}, []DiffHunk{}, 0, 0},
+		{"ignored lines keep their text", "A\nx", "a\ny", DiffOptions{IgnoreCase: true}, []DiffHunk{
+			{Type: "modify", LeftStart: 2, LeftCount: 1, RightStart: 2, RightCount: 1, Left: []string{"x"}, Right: []string{"y"}},
+		}, 1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := diffText(tt.left, tt.right, tt.opts)
+			if !reflect.DeepEqual(res.Hunks, tt.want) {
+				t.Errorf("hunks = %+v\nwant %+v", res.Hunks, tt.want)
+			}
+			if res.Added != tt.added || res.Removed != tt.removed || res.Identical != (len(tt.want) == 0) {
+				t.Errorf("added %d removed %d identical %v", res.Added, res.Removed, res.Identical)
+			}
+		})
+	}
+}
+
+// applyHunks rebuilds the right side from the left and the hunks
+func applyHunks(left []string, hunks []DiffHunk) []string {
+	var out []string
+	i := 0
+	for _, h := range hunks {
+		out = append(out, left[i:h.LeftStart-1]...)
+		out = append(out, h.Right...)
+		i = h.LeftStart - 1 + h.LeftCount
+	}
+	return append(out, left[i:]...)
+}
+
+func TestDiffTextRebuildsRight(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for n := 0; n < 200; n++ {
+		left := make([]string, rng.Intn(40))
+		for i := range left {
+			left[i] = fmt.Sprint(rng.Intn(8))
+		}
+		right := append([]string{}, left...)
+		for e := rng.Intn(6); e > 0; e-- {
+			switch p := rng.Intn(len(right) + 1); {
+			case rng.Intn(2) == 0 || p == len(right):
+				right = append(right[:p], append([]string{fmt.Sprint(rng.Intn(8))}, right[p:]...)...)
+			default:
+				right = append(right[:p], right[p+1:]...)
+			}
+		}
+		res := diffText(strings.Join(left, "\n"), strings.Join(right, "\n"), DiffOptions{})
+		if got := applyHunks(left, res.Hunks); strings.Join(got, "\n") != strings.Join(right, "\n") {
+			t.Fatalf("left %q right %q: hunks %+v rebuild %q", left, right, res.Hunks, got)
+		}
+	}
+}
+
+func BenchmarkDiffText(b *testing.B) {
+	rng := rand.New(rand.NewSource(1))
+	left := make([]string, 100000)
+	for i := range left {
+		left[i] = fmt.Sprintf("line %d: %x", i, rng.Int63())
+	}
+	right := append([]string{}, left...)
+	// a few hundred scattered edits, as between two versions of a file
+	for e := 0; e < 300; e++ {
+		right[rng.Intn(len(right))] = fmt.Sprintf("changed %d", e)
+	}
+	leftText, rightText := strings.Join(left, "\n"), strings.Join(right, "\n")
+	b.ResetTimer()
+	for range b.N {
+		diffText(leftText, rightText, DiffOptions{})
+	}
+}
@@ -0,0 +1,391 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// ErrDocumentDirty is returned when closing a document with unsaved changes
+var ErrDocumentDirty = errors.New("document has unsaved changes")
+
+// Document is a buffer open in the editor
+type Document struct {
+	ID         string `json:"id"`
+	Path       string `json:"path"` // empty until first saved
+	Content    string `json:"content"`
+	Dirty      bool   `json:"dirty"`
+	Encoding   string `json:"encoding"`
+	LineEnding string `json:"lineEnding"`
+	Language   string `json:"language"`
+	// Lossy is set when invalid bytes were replaced with U+FFFD on open
+	Lossy bool `json:"lossy,omitempty"`
+	// Title replaces the Untitled-N name of a document that has no file yet
+	Title string `json:"title,omitempty"`
+	// ReadOnly is set when the file cannot be saved without elevation
+	ReadOnly bool `json:"readOnly,omitempty"`
+	// LargeFile documents are too big to hand to the editor. They have no
+	// Content, are read-only and have their lines fetched with ReadChunk.
+	LargeFile bool  `json:"largeFile,omitempty"`
+	Size      int64 `json:"size,omitempty"`
+	Lines     int   `json:"lines,omitempty"` // estimated until indexed
+
+	version   uint64    // bumped on every buffer update
+	autosaved uint64    // version last written to the recovery file
+	number    int       // sequence number used to name untitled documents
+	stamp     fileStamp // on-disk state as of the last open or save
+	watcher   *fileWatcher
+	index     *lineIndex // line offsets of a large file
+}
+
+// DocumentMeta describes an open document without its content
+type DocumentMeta struct {
+	ID         string `json:"id"`
+	Path       string `json:"path"`
+	Name       string `json:"name"`
+	Dirty      bool   `json:"dirty"`
+	Encoding   string `json:"encoding"`
+	LineEnding string `json:"lineEnding"`
+	Language   string `json:"language"`
+	LargeFile  bool   `json:"largeFile,omitempty"`
+	Active     bool   `json:"active"`
+}
+
+// Name is the title shown for the document
+func (d *Document) Name() string {
+	if d.Path == "" {
+		if d.Title != "" {
+			return d.Title
+		}
+		return fmt.Sprintf("Untitled-%d.txt", d.number)
+	}
+	return filepath.Base(d.Path)
+}
+
+// recoveryKey identifies the document's autosave file
+func (d *Document) recoveryKey() string {
+	if d.Path == "" {
+		return "untitled-" + strconv.Itoa(d.number)
+	}
+	return d.Path
+}
+
+// NewDocument creates an empty untitled document and makes it active
+func (a *App) NewDocument() Document {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return *a.newDocumentLocked()
+}
+
+// OpenDocument opens path as a document and makes it active. A path that is
+// already open returns the existing document.
+func (a *App) OpenDocument(path string) (Document, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return Document{}, newFileError(path, err)
+	}
+
+	a.mu.Lock()
+	for _, id := range a.order {
+		if d := a.docs[id]; d.Path == abs {
+			a.active = id
+			a.mu.Unlock()
+			return *d, nil
+		}
+	}
+	limit := int64(a.settings.LargeFileMB) << 20
+	a.mu.Unlock()
+
+	if info, err := os.Stat(abs); err == nil && info.Mode().IsRegular() && info.Size() > limit {
+		return a.openLargeDocument(abs, info.Size())
+	}
+	data, err := readTextFile(abs, limit)
+	if err != nil {
+		a.log.Warn("open failed", "path", abs, "err", err)
+		return Document{}, newFileError(abs, err)
+	}
+	content, enc, lossy := decodeText(data)
+	a.log.Info("opened", "path", abs, "bytes", len(data), "encoding", enc, "lossy", lossy)
+
+	a.mu.Lock()
+	d := a.newDocumentLocked()
+	d.Path = abs
+	d.Content = content
+	d.Encoding = enc
+	d.LineEnding = detectLineEnding(content)
+	d.Language = detectLanguage(abs, languageSample(content)).ID
+	d.Lossy = lossy
+	d.ReadOnly = !writable(abs)
+	a.watchLocked(d)
+	a.trimBookmarksLocked(abs, lineCount(content))
+	doc := *d
+	a.mu.Unlock()
+
+	a.addRecent(abs)
+	return doc, nil
+}
+
+// CloseDocument closes the document with the given ID. ErrDocumentDirty is
+// returned, and the document kept open, if it has unsaved changes.
+func (a *App) CloseDocument(id string) error {
+	return a.closeDocument(id, false)
+}
+
+// DiscardDocument closes the document with the given ID, dropping any
+// unsaved changes
+func (a *App) DiscardDocument(id string) error {
+	return a.closeDocument(id, true)
+}
+
+func (a *App) closeDocument(id string, discard bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	d, err := a.docLocked(id)
+	if err != nil {
+		return err
+	}
+	if d.Dirty && !discard {
+		return ErrDocumentDirty
+	}
+	a.log.Debug("closed", "id", id, "path", d.Path, "discarded", d.Dirty)
+	d.stopWatcher()
+	d.index.close()
+	removeRecovery(d.recoveryKey())
+	a.undo.forget(id)
+	delete(a.docs, id)
+	for i, o := range a.order {
+		if o == id {
+			a.order = append(a.order[:i], a.order[i+1:]...)
+			break
+		}
+	}
+	if a.active == id {
+		a.active = ""
+		if len(a.order) > 0 {
+			a.active = a.order[len(a.order)-1]
+		}
+	}
+	return nil
+}
+
+// ListDocuments returns the open documents in the order they were opened
+func (a *App) ListDocuments() []DocumentMeta {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	metas := make([]DocumentMeta, 0, len(a.order))
+	for _, id := range a.order {
+		d := a.docs[id]
+		metas = append(metas, DocumentMeta{
+			ID:         d.ID,
+			Path:       d.Path,
+			Name:       d.Name(),
+			Dirty:      d.Dirty,
+			Encoding:   d.Encoding,
+			LineEnding: d.LineEnding,
+			Language:   d.Language,
+			LargeFile:  d.LargeFile,
+			Active:     id == a.active,
+		})
+	}
+	return metas
+}
+
+// GetDocument returns the document with the given ID
+func (a *App) GetDocument(id string) (Document, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	d, err := a.docLocked(id)
+	if err != nil {
+		return Document{}, err
+	}
+	return *d, nil
+}
+
+// SetActiveDocument selects the document the single-document methods act on
+func (a *App) SetActiveDocument(id string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, err := a.docLocked(id); err != nil {
+		return err
+	}
+	a.active = id
+	return nil
+}
+
+// UpdateDocument records the editor contents of a document and marks it
+// modified
+func (a *App) UpdateDocument(id string, content string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	d, err := a.docLocked(id)
+	if err != nil {
+		return err
+	}
+	if d.LargeFile {
+		return ErrLargeFile
+	}
+	d.Content = content
+	d.Dirty = true
+	d.version++
+	return nil
+}
+
+// SaveDocument writes content to the document's file. Untitled documents
+// have to go through SaveFile so the user can pick a name.
+func (a *App) SaveDocument(id string, content string) error {
+	return a.saveDocument(id, "", content)
+}
+
+// saveDocument writes content to path, or to the document's own path when
+// path is empty
+func (a *App) saveDocument(id string, path string, content string) error {
+	a.mu.Lock()
+	d, err := a.docLocked(id)
+	if err != nil {
+		a.mu.Unlock()
+		return err
+	}
+	if path == "" {
+		path = d.Path
+	}
+	if path == "" {
+		a.mu.Unlock()
+		return &FileError{Code: "invalid_path", Message: fmt.Sprintf("%s has not been saved yet, use Save As", d.Name())}
+	}
+	if d.LargeFile {
+		a.mu.Unlock()
+		return ErrLargeFile
+	}
+	previous, enc, eol, version := d.recoveryKey(), d.Encoding, d.LineEnding, d.version
+	settings := a.settings
+	a.mu.Unlock()
+
+	content = convertLineEndings(content, eol)
+	content, enc = applyBOMMode(content, enc, settings.UTF8BOM)
+	data, err := encodeText(content, enc)
+	if err != nil {
+		return newFileError(path, err)
+	}
+
+	a.fileMu.Lock()
+	err = backupFile(path, settings)
+	if err == nil {
+		err = writeFile(path, data, 0o644)
+	}
+	if err == nil {
+		a.mu.Lock()
+		// a document closed during the write is left closed
+		if a.docs[id] == d {
+			if d.Path != path && d.Language == plaintext {
+				// Save As gave an untitled buffer a name to go by
+				d.Language = detectLanguage(path, languageSample(content)).ID
+			}
+			if d.Path != "" && d.Path != path {
+				// bookmarks follow the document to its new name
+				a.moveBookmarksLocked(d.Path, path)
+			}
+			d.Path = path
+			d.Encoding = enc
+			d.Lossy = false
+			d.ReadOnly = false
+			if d.version == version {
+				d.Content = content
+				d.Dirty = false
+			} else {
+				// edits that came in during the write are still unsaved, and
+				// go to the recovery file of the new name on the next autosave
+				d.autosaved = 0
+			}
+			a.watchLocked(d)
+		}
+		a.mu.Unlock()
+	}
+	a.fileMu.Unlock()
+	if err != nil {
+		a.log.Error("save failed", "path", path, "err", err)
+		return newFileError(path, err)
+	}
+	a.log.Info("saved", "path", path, "bytes", len(data), "encoding", enc)
+
+	removeRecovery(previous)
+	removeRecovery(path)
+	a.addRecent(path)
+	return nil
+}
+
+// reloadDocument rereads a document from disk, replacing its buffer. Large
+// documents are indexed again instead.
+func (a *App) reloadDocument(id string, path string) (Document, error) {
+	a.mu.Lock()
+	if d, ok := a.docs[id]; ok && d.LargeFile {
+		defer a.mu.Unlock()
+		if info, err := os.Stat(path); err == nil {
+			d.Size = info.Size()
+		}
+		a.indexLocked(d)
+		d.version++
+		return *d, nil
+	}
+	limit := int64(a.settings.LargeFileMB) << 20
+	a.mu.Unlock()
+
+	data, err := readTextFile(path, limit)
+	if err != nil {
+		a.log.Warn("reload failed", "path", path, "err", err)
+		return Document{}, newFileError(path, err)
+	}
+	a.log.Info("reloaded", "path", path, "bytes", len(data))
+	content, enc, lossy := decodeText(data)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	d, err := a.docLocked(id)
+	if err != nil {
+		return Document{}, err
+	}
+	d.Content = content
+	d.Encoding = enc
+	d.LineEnding = detectLineEnding(content)
+	d.Lossy = lossy
+	d.Dirty = false
+	d.version++
+	a.trimBookmarksLocked(d.Path, lineCount(content))
+	return *d, nil
+}
+
+// newDocumentLocked adds an untitled document and makes it active.
+// a.mu must be held.
+func (a *App) newDocumentLocked() *Document {
+	a.seq++
+	d := &Document{
+		ID:         "doc-" + strconv.Itoa(a.seq),
+		Encoding:   EncodingUTF8,
+		LineEnding: defaultLineEnding(),
+		Language:   plaintext,
+		number:     a.seq,
+	}
+	a.docs[d.ID] = d
+	a.order = append(a.order, d.ID)
+	a.active = d.ID
+	return d
+}
+
+// docLocked looks up a document by ID. a.mu must be held.
+func (a *App) docLocked(id string) (*Document, error) {
+	d, ok := a.docs[id]
+	if !ok {
+		return nil, fmt.Errorf("no open document %q", id)
+	}
+	return d, nil
+}
+
+// activeLocked returns the active document, creating an untitled one if
+// nothing is open. a.mu must be held.
+func (a *App) activeLocked() *Document {
+	if d, ok := a.docs[a.active]; ok {
+		return d
+	}
+	return a.newDocumentLocked()
+}
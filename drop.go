@@ -0,0 +1,27 @@
+package main
+
+import "github.com/wailsapp/wails/v2/pkg/runtime"
+
+// DropResult is emitted as file:dropped for each file dropped on the window
+type DropResult struct {
+	Path     string     `json:"path"`
+	Document *Document  `json:"document,omitempty"`
+	Error    *FileError `json:"error,omitempty"`
+}
+
+// HandleFileDrop opens each dropped path as a document. Directories and
+// binary files are refused with an error rather than expanded or loaded.
+func (a *App) HandleFileDrop(paths []string) []DropResult {
+	results := make([]DropResult, 0, len(paths))
+	for _, path := range paths {
+		res := DropResult{Path: path}
+		if d, err := a.OpenDocument(path); err != nil {
+			res.Error = newFileError(path, err)
+		} else {
+			res.Document = &d
+		}
+		runtime.EventsEmit(a.ctx, "file:dropped", res)
+		results = append(results, res)
+	}
+	return results
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// elevatedWriteFlag makes the executable copy a file into place and exit
+// instead of starting the app. SaveAsAdmin runs it with elevation.
+const elevatedWriteFlag = "--elevated-write"
+
+// SaveAsAdmin saves content to path with administrator rights, for files
+// the user cannot write. The user is asked to authorise it by pkexec or a
+// sudo askpass helper on Linux, an administrator prompt on macOS and UAC on
+// Windows; declining returns ErrCancelled.
+func (a *App) SaveAsAdmin(path string, content string) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return newFileError(path, err)
+	}
+
+	// the document's encoding and line endings are kept when it is open
+	enc, eol := EncodingUTF8, ""
+	var doc *Document
+	var version uint64
+	a.mu.Lock()
+	settings := a.settings
+	for _, id := range a.order {
+		if d := a.docs[id]; d.Path == path {
+			doc, enc, eol, version = d, d.Encoding, d.LineEnding, d.version
+			if d.LargeFile {
+				a.mu.Unlock()
+				return ErrLargeFile
+			}
+		}
+	}
+	a.mu.Unlock()
+	if eol != "" {
+		content = convertLineEndings(content, eol)
+	}
+	content, enc = applyBOMMode(content, enc, settings.UTF8BOM)
+	data, err := encodeText(content, enc)
+	if err != nil {
+		return newFileError(path, err)
+	}
+
+	// the helper copies from a private temp file using the usual atomic write
+	tmp, err := os.CreateTemp("", "wailspad-elevated-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	a.fileMu.Lock()
+	err = backupFile(path, settings)
+	if err == nil {
+		err = runElevated(exe, elevatedWriteFlag, tmp.Name(), path)
+	}
+	if err == nil && doc != nil {
+		a.mu.Lock()
+		if a.docs[doc.ID] == doc {
+			doc.Encoding = enc
+			doc.Lossy = false
+			if doc.version == version {
+				doc.Content = content
+				doc.Dirty = false
+			} else {
+				// typed while the prompt was up, so still unsaved
+				doc.autosaved = 0
+			}
+			a.watchLocked(doc)
+		}
+		a.mu.Unlock()
+	}
+	a.fileMu.Unlock()
+	if err != nil {
+		a.log.Error("elevated save failed", "path", path, "err", err)
+		return err
+	}
+	a.log.Info("saved with administrator rights", "path", path, "bytes", len(data))
+	if doc != nil {
+		removeRecovery(doc.recoveryKey())
+	}
+	a.addRecent(path)
+	return nil
+}
+
+// elevatedWrite is the body of the elevated helper. It replaces dst with
+// the contents of src, keeping dst's owner, and returns the exit status.
+func elevatedWrite(src, dst string) int {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	info, statErr := os.Stat(dst)
+	if err := writeFile(dst, data, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if statErr == nil {
+		keepOwner(dst, info)
+	}
+	return 0
+}
@@ -0,0 +1,71 @@
+//go:build !windows
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"syscall"
+)
+
+// runElevated runs exe with args as root, asking the user to authorise it
+func runElevated(exe string, args ...string) error {
+	var cmd *exec.Cmd
+	switch {
+	case runtime.GOOS == "darwin":
+		quoted := make([]string, 0, len(args)+1)
+		for _, s := range append([]string{exe}, args...) {
+			quoted = append(quoted, shellQuote(s))
+		}
+		command := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(strings.Join(quoted, " "))
+		script := fmt.Sprintf(`do shell script "%s" with administrator privileges`, command)
+		cmd = exec.Command("osascript", "-e", script)
+	case hasCommand("pkexec"):
+		cmd = exec.Command("pkexec", append([]string{exe}, args...)...)
+	case hasCommand("sudo") && os.Getenv("SUDO_ASKPASS") != "":
+		cmd = exec.Command("sudo", append([]string{"-A", "--", exe}, args...)...)
+	default:
+		return errors.New("no way to ask for administrator rights, install pkexec or set SUDO_ASKPASS")
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	var exit *exec.ExitError
+	if !errors.As(err, &exit) {
+		return err
+	}
+	msg := strings.TrimSpace(stderr.String())
+	switch code := exit.ExitCode(); {
+	// pkexec exits 126 when its dialog is dismissed
+	case cmd.Args[0] == "pkexec" && code == 126,
+		// osascript reports error -128 when the prompt is cancelled
+		runtime.GOOS == "darwin" && strings.Contains(msg, "(-128)"),
+		cmd.Args[0] == "sudo" && strings.Contains(msg, "no password was provided"):
+		return ErrCancelled
+	case cmd.Args[0] == "pkexec" && code == 127:
+		return &FileError{Code: "permission_denied", Message: "not authorised to save as administrator"}
+	}
+	if msg == "" {
+		return err
+	}
+	return errors.New(msg)
+}
+
+// shellQuote quotes s for /bin/sh
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// keepOwner gives path back to the owner recorded in info, since the
+// elevated write leaves it owned by root
+func keepOwner(path string, info os.FileInfo) {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		os.Chown(path, int(st.Uid), int(st.Gid))
+	}
+}
@@ -0,0 +1,96 @@
+//go:build windows
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	procShellExecuteExW     = shell32.NewProc("ShellExecuteExW")
+	kernel32                = syscall.NewLazyDLL("kernel32.dll")
+	procWaitForSingleObject = kernel32.NewProc("WaitForSingleObject")
+	procGetExitCodeProcess  = kernel32.NewProc("GetExitCodeProcess")
+)
+
+// shellExecuteInfo is SHELLEXECUTEINFOW
+type shellExecuteInfo struct {
+	cbSize       uint32
+	fMask        uint32
+	hwnd         uintptr
+	lpVerb       *uint16
+	lpFile       *uint16
+	lpParameters *uint16
+	lpDirectory  *uint16
+	nShow        int32
+	hInstApp     uintptr
+	lpIDList     uintptr
+	lpClass      *uint16
+	hkeyClass    uintptr
+	dwHotKey     uint32
+	hIcon        uintptr
+	hProcess     syscall.Handle
+}
+
+// runElevated runs exe with args through a UAC prompt and waits for it
+func runElevated(exe string, args ...string) error {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		quoted[i] = syscall.EscapeArg(a)
+	}
+	verb, err := syscall.UTF16PtrFromString("runas")
+	if err != nil {
+		return err
+	}
+	file, err := syscall.UTF16PtrFromString(exe)
+	if err != nil {
+		return err
+	}
+	params, err := syscall.UTF16PtrFromString(strings.Join(quoted, " "))
+	if err != nil {
+		return err
+	}
+
+	const (
+		seeMaskNoCloseProcess = 0x40
+		seeMaskNoUI           = 0x400
+		swHide                = 0
+		errorCancelled        = 1223
+		infinite              = 0xFFFFFFFF
+	)
+	info := shellExecuteInfo{
+		fMask:        seeMaskNoCloseProcess | seeMaskNoUI,
+		lpVerb:       verb,
+		lpFile:       file,
+		lpParameters: params,
+		nShow:        swHide,
+	}
+	info.cbSize = uint32(unsafe.Sizeof(info))
+	ret, _, callErr := procShellExecuteExW.Call(uintptr(unsafe.Pointer(&info)))
+	if ret == 0 {
+		if errno, ok := callErr.(syscall.Errno); ok && errno == errorCancelled {
+			return ErrCancelled
+		}
+		return fmt.Errorf("could not start elevated save: %v", callErr)
+	}
+	defer syscall.CloseHandle(info.hProcess)
+
+	procWaitForSingleObject.Call(uintptr(info.hProcess), infinite)
+	var code uint32
+	if ret, _, err := procGetExitCodeProcess.Call(uintptr(info.hProcess), uintptr(unsafe.Pointer(&code))); ret == 0 {
+		return err
+	}
+	if code != 0 {
+		return errors.New("elevated save failed")
+	}
+	return nil
+}
+
+// keepOwner does nothing on Windows, where the replaced file inherits its
+// ACL from the directory
+func keepOwner(path string, info os.FileInfo) {}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Supported text encodings
+const (
+	EncodingUTF8    = "UTF-8"
+	EncodingUTF8BOM = "UTF-8 BOM"
+	EncodingUTF16LE = "UTF-16LE"
+	EncodingUTF16BE = "UTF-16BE"
+	EncodingLatin1  = "Latin-1"
+)
+
+var encodings = []string{EncodingUTF8, EncodingUTF8BOM, EncodingUTF16LE, EncodingUTF16BE, EncodingLatin1}
+
+// bomModes are the allowed values of Settings.UTF8BOM
+var bomModes = []string{"keep", "always", "never"}
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// validEncoding reports whether enc is one of the supported encodings
+func validEncoding(enc string) bool {
+	for _, e := range encodings {
+		if e == enc {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeText detects the encoding of data and converts it to a UTF-8
+// string. lossy is set when invalid bytes had to be replaced with U+FFFD.
+func decodeText(data []byte) (text string, enc string, lossy bool) {
+	switch {
+	case bytes.HasPrefix(data, bomUTF8):
+		text, lossy = decodeUTF8(data[len(bomUTF8):])
+		return text, EncodingUTF8BOM, lossy
+	case bytes.HasPrefix(data, bomUTF16LE):
+		text, lossy = decodeUTF16(data[len(bomUTF16LE):], false)
+		return text, EncodingUTF16LE, lossy
+	case bytes.HasPrefix(data, bomUTF16BE):
+		text, lossy = decodeUTF16(data[len(bomUTF16BE):], true)
+		return text, EncodingUTF16BE, lossy
+	case utf8.Valid(data):
+		return string(data), EncodingUTF8, false
+	case looksLatin1(data):
+		return decodeLatin1(data), EncodingLatin1, false
+	default:
+		text, lossy = decodeUTF8(data)
+		return text, EncodingUTF8, lossy
+	}
+}
+
+// applyBOMMode returns content and the encoding to save it with under the
+// utf8Bom setting. Leading U+FEFF characters are dropped when the setting
+// decides, so the file does not end up with two marks or one it should lack.
+func applyBOMMode(content, enc, mode string) (string, string) {
+	if mode == "keep" || (enc != "" && enc != EncodingUTF8 && enc != EncodingUTF8BOM) {
+		return content, enc
+	}
+	content = strings.TrimLeft(content, "\uFEFF")
+	if mode == "always" {
+		return content, EncodingUTF8BOM
+	}
+	return content, EncodingUTF8
+}
+
+// encodeText converts UTF-8 text to enc, adding a BOM where the encoding
+// calls for one
+func encodeText(text string, enc string) ([]byte, error) {
+	switch enc {
+	case "", EncodingUTF8:
+		return []byte(text), nil
+	case EncodingUTF8BOM:
+		return append(append([]byte{}, bomUTF8...), text...), nil
+	case EncodingUTF16LE, EncodingUTF16BE:
+		be := enc == EncodingUTF16BE
+		units := utf16.Encode([]rune(text))
+		out := make([]byte, 0, 2+2*len(units))
+		if be {
+			out = append(out, bomUTF16BE...)
+		} else {
+			out = append(out, bomUTF16LE...)
+		}
+		for _, u := range units {
+			if be {
+				out = append(out, byte(u>>8), byte(u))
+			} else {
+				out = append(out, byte(u), byte(u>>8))
+			}
+		}
+		return out, nil
+	case EncodingLatin1:
+		out := make([]byte, 0, len(text))
+		line := 1
+		for _, r := range text {
+			if r > 0xFF {
+				return nil, &FileError{
+					Code:    "encoding",
+					Message: fmt.Sprintf("%q on line %d cannot be saved as %s", r, line, enc),
+				}
+			}
+			if r == '\n' {
+				line++
+			}
+			out = append(out, byte(r))
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("unsupported encoding %q, expected one of %s", enc, strings.Join(encodings, ", "))
+	}
+}
+
+// decodeUTF8 returns data as a string, replacing invalid sequences
+func decodeUTF8(data []byte) (string, bool) {
+	if utf8.Valid(data) {
+		return string(data), false
+	}
+	return strings.ToValidUTF8(string(data), string(utf8.RuneError)), true
+}
+
+// decodeUTF16 decodes BOM-less UTF-16 data. A trailing odd byte or
+// unpaired surrogate is replaced with U+FFFD.
+func decodeUTF16(data []byte, bigEndian bool) (string, bool) {
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		if bigEndian {
+			units[i] = uint16(data[2*i])<<8 | uint16(data[2*i+1])
+		} else {
+			units[i] = uint16(data[2*i+1])<<8 | uint16(data[2*i])
+		}
+	}
+
+	lossy := false
+	runes := make([]rune, 0, len(units))
+	for i := 0; i < len(units); i++ {
+		r := rune(units[i])
+		if utf16.IsSurrogate(r) {
+			if i+1 < len(units) {
+				if pair := utf16.DecodeRune(r, rune(units[i+1])); pair != utf8.RuneError {
+					runes = append(runes, pair)
+					i++
+					continue
+				}
+			}
+			r = utf8.RuneError
+			lossy = true
+		}
+		runes = append(runes, r)
+	}
+	if len(data)%2 != 0 {
+		runes = append(runes, utf8.RuneError)
+		lossy = true
+	}
+	return string(runes), lossy
+}
+
+// looksLatin1 guesses whether non UTF-8 data is Latin-1 text, which never
+// uses the C1 control range 0x80-0x9F
+func looksLatin1(data []byte) bool {
+	for _, b := range data {
+		if b >= 0x80 && b <= 0x9F {
+			return false
+		}
+	}
+	return true
+}
+
+// decodeLatin1 maps each byte onto the code point of the same value
+func decodeLatin1(data []byte) string {
+	var sb strings.Builder
+	sb.Grow(len(data) + len(data)/4)
+	for _, b := range data {
+		sb.WriteRune(rune(b))
+	}
+	return sb.String()
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncodingRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		enc  string
+		text string
+	}{
+		{"utf-8", []byte("héllo 世界\n"), EncodingUTF8, "héllo 世界\n"},
+		{"empty", nil, EncodingUTF8, ""},
+		{"utf-8 bom", append([]byte{0xEF, 0xBB, 0xBF}, "héllo"...), EncodingUTF8BOM, "héllo"},
+		{"utf-8 bom only", []byte{0xEF, 0xBB, 0xBF}, EncodingUTF8BOM, ""},
+		{"utf-16le", []byte{0xFF, 0xFE, 'h', 0, 0xE9, 0, 0x3D, 0xD8, 0x00, 0xDE}, EncodingUTF16LE, "hé\U0001F600"},
+		{"utf-16be", []byte{0xFE, 0xFF, 0, 'h', 0, 0xE9, 0xD8, 0x3D, 0xDE, 0x00}, EncodingUTF16BE, "hé\U0001F600"},
+		{"latin-1", []byte{'c', 'a', 'f', 0xE9, ' ', 0xFF}, EncodingLatin1, "café ÿ"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, enc, lossy := decodeText(tt.data)
+			if text != tt.text || enc != tt.enc || lossy {
+				t.Fatalf("decodeText = %q, %s, lossy %v, want %q, %s", text, enc, lossy, tt.text, tt.enc)
+			}
+			data, err := encodeText(text, enc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(data, tt.data) {
+				t.Fatalf("encodeText = % x, want % x", data, tt.data)
+			}
+			// and once more, the encoding and its BOM surviving the trip
+			again, enc2, _ := decodeText(data)
+			if again != text || enc2 != enc {
+				t.Errorf("second decode = %q, %s", again, enc2)
+			}
+		})
+	}
+}
+
+func TestDecodeLossy(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		enc  string
+		text string
+	}{
+		// 0x80-0x9F rules out Latin-1, leaving broken UTF-8
+		{"invalid utf-8", []byte{'a', 0x81, 'b'}, EncodingUTF8, "a�b"},
+		{"invalid after bom", []byte{0xEF, 0xBB, 0xBF, 'a', 0xC3}, EncodingUTF8BOM, "a�"},
+		{"odd utf-16 byte", []byte{0xFF, 0xFE, 'a', 0, 'b'}, EncodingUTF16LE, "a�"},
+		{"unpaired surrogate", []byte{0xFE, 0xFF, 0xD8, 0x3D, 0, 'a'}, EncodingUTF16BE, "�a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, enc, lossy := decodeText(tt.data)
+			if text != tt.text || enc != tt.enc || !lossy {
+				t.Fatalf("decodeText = %q, %s, lossy %v, want %q, %s, lossy", text, enc, lossy, tt.text, tt.enc)
+			}
+			// what was decoded saves and reopens the same, replacements and all
+			data, err := encodeText(text, enc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if again, enc2, lossy := decodeText(data); again != text || enc2 != enc || lossy {
+				t.Errorf("reopened as %q, %s, lossy %v", again, enc2, lossy)
+			}
+		})
+	}
+}
+
+func TestEncodeLatin1Unrepresentable(t *testing.T) {
+	_, err := encodeText("ok\n世", EncodingLatin1)
+	fe, ok := err.(*FileError)
+	if !ok || fe.Code != "encoding" {
+		t.Fatalf("err = %v, want an encoding FileError", err)
+	}
+	if _, err := encodeText("x", "EBCDIC"); err == nil {
+		t.Error("encodeText accepted an unknown encoding")
+	}
+}
+
+func TestApplyBOMMode(t *testing.T) {
+	bom := string(rune(0xFEFF))
+	tests := []struct {
+		content, enc, mode string
+		want, wantEnc      string
+	}{
+		{bom + "x", EncodingUTF8BOM, "keep", bom + "x", EncodingUTF8BOM},
+		{"x", EncodingUTF8, "always", "x", EncodingUTF8BOM},
+		{bom + "x", EncodingUTF8, "always", "x", EncodingUTF8BOM},
+		{bom + bom + "x", EncodingUTF8BOM, "never", "x", EncodingUTF8},
+		{bom + "x", EncodingUTF16LE, "never", bom + "x", EncodingUTF16LE},
+	}
+	for _, tt := range tests {
+		got, enc := applyBOMMode(tt.content, tt.enc, tt.mode)
+		if got != tt.want || enc != tt.wantEnc {
+			t.Errorf("applyBOMMode(%q, %s, %s) = %q, %s, want %q, %s", tt.content, tt.enc, tt.mode, got, enc, tt.want, tt.wantEnc)
+		}
+	}
+}
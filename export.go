@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"path/filepath"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"github.com/yuin/goldmark"
+)
+
+var exportPage = template.Must(template.New("export").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { margin: 2em auto; max-width: 60em; padding: 0 1em; font-family: {{.FontFamily}}; font-size: {{.FontSize}}px; }
+pre { white-space: pre-wrap; overflow-wrap: anywhere; font-family: inherit; }
+</style>
+</head>
+<body>
+{{if .Markdown}}{{.Body}}{{else}}<pre>{{.Text}}</pre>{{end}}
+</body>
+</html>
+`))
+
+// renderHTML wraps content in a standalone page styled with settings.
+// Markdown sources are rendered, anything else is shown preformatted.
+func renderHTML(title, content string, markdown bool, s Settings) ([]byte, error) {
+	data := struct {
+		Title      string
+		FontFamily template.CSS
+		FontSize   int
+		Markdown   bool
+		Body       template.HTML
+		Text       string
+	}{
+		Title:      title,
+		FontFamily: template.CSS(s.FontFamily),
+		FontSize:   s.FontSize,
+		Markdown:   markdown,
+		Text:       content,
+	}
+	if markdown {
+		var body bytes.Buffer
+		if err := goldmark.Convert([]byte(content), &body); err != nil {
+			return nil, fmt.Errorf("rendering markdown: %w", err)
+		}
+		data.Body = template.HTML(body.String())
+	}
+	var out bytes.Buffer
+	if err := exportPage.Execute(&out, data); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// renderPDF lays content out on A4 pages in the configured font size
+func renderPDF(content string, s Settings) ([]byte, error) {
+	size := pageSizes["A4"]
+	l := pageLayout{Width: size[0], Height: size[1], Top: 72, Right: 72, Bottom: 72, Left: 72, FontSize: float64(s.FontSize) * 0.75}
+	var out bytes.Buffer
+	if err := writePDF(&out, l, l.paginate(content)); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// ExportAs writes a copy of a document as "html" or "pdf". The user is
+// asked for a destination when destPath is empty; ErrCancelled is returned
+// if they cancel. The document itself is left untouched.
+func (a *App) ExportAs(docID string, format string, destPath string) error {
+	a.mu.Lock()
+	d, err := a.docLocked(docID)
+	if err != nil {
+		a.mu.Unlock()
+		return err
+	}
+	if d.LargeFile {
+		// only a window of a large file is ever held in memory
+		a.mu.Unlock()
+		return ErrLargeFile
+	}
+	name, content, markdown, settings := d.Name(), d.Content, d.Language == "markdown", a.settings
+	a.mu.Unlock()
+
+	format = strings.ToLower(format)
+	var filter runtime.FileFilter
+	switch format {
+	case "html":
+		filter = runtime.FileFilter{DisplayName: "HTML (*.html)", Pattern: "*.html;*.htm"}
+	case "pdf":
+		filter = runtime.FileFilter{DisplayName: "PDF (*.pdf)", Pattern: "*.pdf"}
+	default:
+		return fmt.Errorf("unsupported export format %q, expected html or pdf", format)
+	}
+
+	if destPath == "" {
+		destPath, err = runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+			Title:           "Export As " + strings.ToUpper(format),
+			DefaultFilename: strings.TrimSuffix(name, filepath.Ext(name)) + "." + format,
+			Filters:         []runtime.FileFilter{filter},
+		})
+		if err != nil {
+			return err
+		}
+		if destPath == "" {
+			return ErrCancelled
+		}
+	}
+
+	var data []byte
+	if format == "html" {
+		data, err = renderHTML(name, content, markdown, settings)
+	} else {
+		data, err = renderPDF(content, settings)
+	}
+	if err != nil {
+		return err
+	}
+	if err := writeFile(destPath, data, 0o644); err != nil {
+		return newFileError(destPath, err)
+	}
+	return nil
+}
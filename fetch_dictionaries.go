@@ -0,0 +1,89 @@
+//go:build ignore
+
+// fetch_dictionaries downloads the SCOWL hunspell en_US dictionary into
+// dictionaries/, along with the README that carries its copyright and
+// license notice. Run it through go generate and commit what it writes.
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// scowlRelease is the SCOWL release the bundled dictionary comes from
+const scowlRelease = "2020.12.07"
+
+var scowlURL = "https://downloads.sourceforge.net/project/wordlist/speller/" + scowlRelease +
+	"/hunspell-en_US-" + scowlRelease + ".zip"
+
+// scowlFiles are the members of the release kept in dictionaries/
+var scowlFiles = []string{"en_US.aff", "en_US.dic", "README_en_US.txt"}
+
+func main() {
+	if err := fetch(); err != nil {
+		fmt.Fprintln(os.Stderr, "fetch_dictionaries:", err)
+		os.Exit(1)
+	}
+}
+
+func fetch() error {
+	client := &http.Client{Timeout: 2 * time.Minute}
+	resp, err := client.Get(scowlURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", scowlURL, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 64<<20))
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(data)
+	fmt.Printf("%s\nsha256 %s\n", scowlURL, hex.EncodeToString(sum[:]))
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return err
+	}
+	found := make(map[string]bool)
+	for _, f := range zr.File {
+		name := filepath.Base(f.Name)
+		if !slices.Contains(scowlFiles, name) {
+			continue
+		}
+		if err := extract(f, filepath.Join("dictionaries", name)); err != nil {
+			return err
+		}
+		found[name] = true
+	}
+	for _, name := range scowlFiles {
+		if !found[name] {
+			return fmt.Errorf("%s is missing from the release", name)
+		}
+	}
+	return nil
+}
+
+func extract(f *zip.File, dst string) error {
+	r, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dst, data, 0o644)
+}
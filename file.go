@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// maxFileSize is the largest file read whole outside the editor, which goes
+// by the large-file setting instead
+const maxFileSize = 10 << 20
+
+// ErrCancelled is returned when the user backs out of a dialog or prompt
+var ErrCancelled = errors.New("cancelled by the user")
+
+// ErrPermissionDenied matches, with errors.Is, any FileError caused by
+// missing write or read access
+var ErrPermissionDenied = &FileError{Code: "permission_denied", Message: "permission denied"}
+
+// FileResult is returned to the frontend by the file methods
+type FileResult struct {
+	ID         string `json:"id,omitempty"`
+	Path       string `json:"path"`
+	Content    string `json:"content"`
+	Encoding   string `json:"encoding,omitempty"`
+	LineEnding string `json:"lineEnding,omitempty"`
+	// Lossy is set when invalid bytes were replaced with U+FFFD on open
+	Lossy    bool `json:"lossy,omitempty"`
+	ReadOnly bool `json:"readOnly,omitempty"`
+	// LargeFile results carry no content, see ReadChunk
+	LargeFile bool       `json:"largeFile,omitempty"`
+	Size      int64      `json:"size,omitempty"`
+	Lines     int        `json:"lines,omitempty"`
+	Error     *FileError `json:"error,omitempty"`
+}
+
+// FileError is a file operation failure the frontend can show to the user
+type FileError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *FileError) Error() string {
+	return e.Message
+}
+
+// Is matches FileErrors by code
+func (e *FileError) Is(target error) bool {
+	t, ok := target.(*FileError)
+	return ok && t.Code == e.Code
+}
+
+// newFileError maps an os error onto a FileError with a readable message
+func newFileError(path string, err error) *FileError {
+	var fe *FileError
+	if errors.As(err, &fe) {
+		return fe
+	}
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return &FileError{Code: "not_found", Message: fmt.Sprintf("%s no longer exists", path)}
+	case errors.Is(err, fs.ErrExist):
+		return &FileError{Code: "exists", Message: fmt.Sprintf("%s already exists", path)}
+	case errors.Is(err, fs.ErrPermission):
+		return &FileError{Code: "permission_denied", Message: fmt.Sprintf("permission denied: %s", path)}
+	case errors.Is(err, syscall.EROFS):
+		return &FileError{Code: "read_only", Message: fmt.Sprintf("%s is on a read-only file system", path)}
+	case errors.Is(err, syscall.ENOSPC):
+		return &FileError{Code: "disk_full", Message: fmt.Sprintf("no space left on device while writing %s", path)}
+	case errors.Is(err, syscall.ENAMETOOLONG), errors.Is(err, syscall.EINVAL):
+		return &FileError{Code: "invalid_path", Message: fmt.Sprintf("invalid path: %s", path)}
+	default:
+		return &FileError{Code: "io", Message: err.Error()}
+	}
+}
+
+// readFile loads a file, refusing directories and anything over limit bytes
+func readFile(path string, limit int64) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, newFileError(path, err)
+	}
+	if info.IsDir() {
+		return nil, &FileError{Code: "is_directory", Message: fmt.Sprintf("%s is a directory", path)}
+	}
+	if info.Size() > limit {
+		return nil, &FileError{
+			Code:    "too_large",
+			Message: fmt.Sprintf("file too large: %s is %d MB, the limit is %d MB", path, info.Size()>>20, limit>>20),
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, newFileError(path, err)
+	}
+	return data, nil
+}
+
+// binarySniffLen is how much of a file is checked for NUL bytes
+const binarySniffLen = 8000
+
+// looksBinary guesses whether data is binary rather than text. UTF-16 text
+// is full of NUL bytes, so a UTF-16 byte order mark counts as text.
+func looksBinary(data []byte) bool {
+	if bytes.HasPrefix(data, bomUTF16LE) || bytes.HasPrefix(data, bomUTF16BE) {
+		return false
+	}
+	if len(data) > binarySniffLen {
+		data = data[:binarySniffLen]
+	}
+	return bytes.IndexByte(data, 0) >= 0
+}
+
+// readTextFile is readFile that also refuses binary files
+func readTextFile(path string, limit int64) ([]byte, error) {
+	data, err := readFile(path, limit)
+	if err != nil {
+		return nil, err
+	}
+	if looksBinary(data) {
+		return nil, &FileError{Code: "binary", Message: fmt.Sprintf("%s looks like a binary file and cannot be opened as text", path)}
+	}
+	return data, nil
+}
+
+// canWrite checks whether path can be opened for writing
+func canWrite(path string) bool {
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return false
+	}
+	f.Close()
+	return true
+}
+
+// writable reports whether path can be saved in place, which takes write
+// access to the file and, for the temp file of an atomic write, its
+// directory
+func writable(path string) bool {
+	if !canWrite(path) {
+		return false
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), ".wailspad-access-*")
+	if err != nil {
+		return false
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return true
+}
+
+// writeFile atomically replaces path with data. The content goes to a temp
+// file in the same directory which is renamed over the target once synced,
+// so a crash mid-save leaves the old file intact. A new file gets perm, an
+// existing one keeps its own.
+func writeFile(path string, data []byte, perm fs.FileMode) error {
+	if path == "" {
+		return &FileError{Code: "invalid_path", Message: "no file name given"}
+	}
+	dir := filepath.Dir(path)
+	if info, err := os.Stat(dir); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return &FileError{Code: "invalid_path", Message: fmt.Sprintf("directory %s does not exist", dir)}
+		}
+		return newFileError(dir, err)
+	} else if !info.IsDir() {
+		return &FileError{Code: "invalid_path", Message: fmt.Sprintf("%s is not a directory", dir)}
+	}
+
+	if info, err := os.Stat(path); err == nil {
+		if info.IsDir() {
+			return &FileError{Code: "is_directory", Message: fmt.Sprintf("%s is a directory", path)}
+		}
+		// renaming over the file would get past its permissions
+		if !canWrite(path) {
+			return &FileError{Code: "permission_denied", Message: fmt.Sprintf("permission denied: %s", path)}
+		}
+		perm = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return newFileError(path, err)
+	}
+	tmpName := tmp.Name()
+	fail := func(err error) error {
+		tmp.Close()
+		os.Remove(tmpName)
+		return newFileError(path, err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		return fail(err)
+	}
+	if err := tmp.Sync(); err != nil {
+		return fail(err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpName)
+		return newFileError(path, err)
+	}
+	if err := os.Chmod(tmpName, perm); err != nil {
+		os.Remove(tmpName)
+		return newFileError(path, err)
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		os.Remove(tmpName)
+		return newFileError(path, err)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestWriteFilePerm(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("windows has no unix permission bits")
+	}
+	dir := t.TempDir()
+	perm := func(path string) fs.FileMode {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return info.Mode().Perm()
+	}
+
+	private := filepath.Join(dir, "private.json")
+	if err := writeJSON(private, []string{"secret"}, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if got := perm(private); got != 0o600 {
+		t.Errorf("new file perm = %o, want 600", got)
+	}
+
+	script := filepath.Join(dir, "script.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFile(script, []byte("#!/bin/sh\necho hi\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := perm(script); got != 0o755 {
+		t.Errorf("existing file perm = %o, want 755 kept", got)
+	}
+
+	// nothing but the target is left behind in the directory
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("directory holds %d entries after writing, want 2", len(entries))
+	}
+}
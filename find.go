@@ -0,0 +1,360 @@
+package main
+
+import (
+	"errors"
+	"regexp"
+	"regexp/syntax"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxMatches caps how many matches Find sends across the bridge
+const maxMatches = 10000
+
+// FindOptions controls how a query is matched
+type FindOptions struct {
+	CaseSensitive bool `json:"caseSensitive"`
+	WholeWord     bool `json:"wholeWord"`
+	Regex         bool `json:"regex"`
+}
+
+// Match is a single search hit. Line and Column are 1-based and, like
+// Length, count runes.
+type Match struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+	Length int `json:"length"`
+}
+
+// FindResult is returned by Find
+type FindResult struct {
+	Matches   []Match `json:"matches"`
+	Truncated bool    `json:"truncated"`
+	// Total is the number of matches found. Find stops looking once it
+	// has maxMatches, so when Truncated is set Total is that cap and not
+	// the full count, which Count gives.
+	Total int         `json:"total"`
+	Error *QueryError `json:"error,omitempty"`
+}
+
+// QueryError reports a query that could not be used, such as a regular
+// expression that does not compile
+type QueryError struct {
+	Code    string `json:"code"`
+	Pattern string `json:"pattern"`
+	Message string `json:"message"`
+}
+
+func (e *QueryError) Error() string {
+	return e.Message
+}
+
+// matcher finds the matches of a compiled query
+type matcher struct {
+	re        *regexp.Regexp
+	wholeWord bool
+	// lookBehind is set for patterns whose assertions depend on the text
+	// before the match, which a slice of the text would hide from them
+	lookBehind bool
+	// anchored is set for patterns tied to the start or end of a line or
+	// the text, which match differently in a larger or smaller piece of it
+	anchored bool
+}
+
+// newMatcher compiles query according to opts
+func newMatcher(query string, opts FindOptions) (*matcher, error) {
+	if query == "" {
+		return nil, &QueryError{Code: "empty", Message: "search query is empty"}
+	}
+	pattern := query
+	if !opts.Regex {
+		pattern = regexp.QuoteMeta(query)
+	}
+	if !opts.CaseSensitive {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		msg := err.Error()
+		var se *syntax.Error
+		if errors.As(err, &se) {
+			msg = se.Code.String() + ": " + strings.TrimPrefix(se.Expr, "(?i)")
+		}
+		return nil, &QueryError{Code: "invalid_regex", Pattern: query, Message: msg}
+	}
+	return &matcher{
+		re:         re,
+		wholeWord:  opts.WholeWord,
+		lookBehind: usesOp(re, syntax.OpBeginLine, syntax.OpBeginText, syntax.OpWordBoundary, syntax.OpNoWordBoundary),
+		anchored:   usesOp(re, syntax.OpBeginLine, syntax.OpBeginText, syntax.OpEndLine, syntax.OpEndText),
+	}, nil
+}
+
+// usesOp reports whether the syntax tree of re holds any of ops
+func usesOp(re *regexp.Regexp, ops ...syntax.Op) bool {
+	tree, err := syntax.Parse(re.String(), syntax.Perl)
+	if err != nil {
+		return true
+	}
+	var walk func(*syntax.Regexp) bool
+	walk = func(r *syntax.Regexp) bool {
+		if slices.Contains(ops, r.Op) {
+			return true
+		}
+		for _, sub := range r.Sub {
+			if walk(sub) {
+				return true
+			}
+		}
+		return false
+	}
+	return walk(tree)
+}
+
+// each calls fn with the submatch byte offsets of every non-empty match in
+// text, stopping early if fn returns false. loc[0] and loc[1] bound the
+// whole match. Matches are found one at a time, so a caller that stops
+// early never pays for the rest of the text.
+func (m *matcher) each(text string, fn func(loc []int) bool) {
+	if m.lookBehind {
+		m.eachBatched(text, fn)
+		return
+	}
+	for pos := 0; pos <= len(text); {
+		loc := m.re.FindStringSubmatchIndex(text[pos:])
+		if loc == nil {
+			return
+		}
+		for i := range loc {
+			if loc[i] >= 0 {
+				loc[i] += pos
+			}
+		}
+		start, end := loc[0], loc[1]
+		if start == end {
+			// step one rune past an empty match so the scan moves on
+			if end == len(text) {
+				return
+			}
+			_, size := utf8.DecodeRuneInString(text[end:])
+			pos = end + size
+			continue
+		}
+		pos = end
+		if !m.accept(text, loc, fn) {
+			return
+		}
+	}
+}
+
+// eachBatched is each for patterns that have to see the whole text. It asks
+// for twice as many matches every round and hands on only the new ones,
+// which keeps early stops cheap at no more than twice the work of one scan.
+func (m *matcher) eachBatched(text string, fn func(loc []int) bool) {
+	seen := 0
+	for n := 64; ; n *= 2 {
+		locs := m.re.FindAllStringSubmatchIndex(text, n)
+		for _, loc := range locs[seen:] {
+			if loc[0] != loc[1] && !m.accept(text, loc, fn) {
+				return
+			}
+		}
+		if len(locs) < n {
+			return
+		}
+		seen = len(locs)
+	}
+}
+
+// accept passes a non-empty match on to fn unless whole word matching
+// rules it out, returning false once fn asks to stop
+func (m *matcher) accept(text string, loc []int, fn func(loc []int) bool) bool {
+	if m.wholeWord && !isWordBoundary(text, loc[0], loc[1]) {
+		return true
+	}
+	return fn(loc)
+}
+
+// isWordBoundary reports whether text[start:end] is not joined to word
+// characters on either side
+func isWordBoundary(text string, start, end int) bool {
+	if start > 0 {
+		r, _ := utf8.DecodeLastRuneInString(text[:start])
+		if isWordRune(r) {
+			return false
+		}
+	}
+	if end < len(text) {
+		r, _ := utf8.DecodeRuneInString(text[end:])
+		if isWordRune(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// find collects up to limit matches of m in text as line/column positions,
+// stopping at the first match past the limit
+func (m *matcher) find(text string, limit int) FindResult {
+	res := FindResult{Matches: []Match{}}
+	line, col, pos := 1, 1, 0
+	m.each(text, func(loc []int) bool {
+		start, end := loc[0], loc[1]
+		if len(res.Matches) >= limit {
+			res.Truncated = true
+			return false
+		}
+		res.Total++
+		// walk forward from the previous match, matches arrive in order
+		for pos < start {
+			r, size := utf8.DecodeRuneInString(text[pos:])
+			if r == '\n' {
+				line++
+				col = 1
+			} else {
+				col++
+			}
+			pos += size
+		}
+		res.Matches = append(res.Matches, Match{
+			Line:   line,
+			Column: col,
+			Length: utf8.RuneCountInString(text[start:end]),
+		})
+		return true
+	})
+	return res
+}
+
+// count returns the number of matches of m in text
+func (m *matcher) count(text string) int {
+	n := 0
+	m.each(text, func([]int) bool {
+		n++
+		return true
+	})
+	return n
+}
+
+// replace substitutes every match of m in text. With expand set, $1 and
+// ${name} in replacement refer to capture groups.
+func (m *matcher) replace(text string, replacement string, expand bool) (string, int) {
+	var sb strings.Builder
+	n, last := 0, 0
+	var buf []byte
+	m.each(text, func(loc []int) bool {
+		if n == 0 {
+			sb.Grow(len(text))
+		}
+		sb.WriteString(text[last:loc[0]])
+		if expand {
+			buf = m.re.ExpandString(buf[:0], replacement, text, loc)
+			sb.Write(buf)
+		} else {
+			sb.WriteString(replacement)
+		}
+		last = loc[1]
+		n++
+		return true
+	})
+	if n == 0 {
+		return text, 0
+	}
+	sb.WriteString(text[last:])
+	return sb.String(), n
+}
+
+// Find searches a document for query
+func (a *App) Find(docID string, query string, opts FindOptions) FindResult {
+	m, err := newMatcher(query, opts)
+	if err != nil {
+		return FindResult{Matches: []Match{}, Error: err.(*QueryError)}
+	}
+	a.mu.Lock()
+	d, err := a.docLocked(docID)
+	if err != nil {
+		a.mu.Unlock()
+		return FindResult{Matches: []Match{}, Error: &QueryError{Code: "no_document", Message: err.Error()}}
+	}
+	content, path, enc, large := d.Content, d.Path, d.Encoding, d.LargeFile
+	a.mu.Unlock()
+	if large {
+		res, err := findLarge(m, path, enc, maxMatches)
+		if err != nil {
+			res.Error = &QueryError{Code: "io", Message: err.Error()}
+		}
+		return res
+	}
+	return m.find(content, maxMatches)
+}
+
+// Count returns how many times query occurs in the active document
+func (a *App) Count(query string, opts FindOptions) (int, error) {
+	m, err := newMatcher(query, opts)
+	if err != nil {
+		return 0, err
+	}
+	a.mu.Lock()
+	d := a.activeLocked()
+	content, path, enc, large := d.Content, d.Path, d.Encoding, d.LargeFile
+	a.mu.Unlock()
+	if large {
+		res, err := findLarge(m, path, enc, 0)
+		return res.Total, err
+	}
+	return m.count(content), nil
+}
+
+// ReplaceResult is returned by ReplaceAll
+type ReplaceResult struct {
+	Content string `json:"content"`
+	Count   int    `json:"count"`
+}
+
+// ReplaceAll replaces every match of query in a document and returns the
+// new content. In regex mode the replacement may use $1 style group
+// references. The document is marked modified when anything changed.
+func (a *App) ReplaceAll(docID string, query string, replacement string, opts FindOptions) (ReplaceResult, error) {
+	m, err := newMatcher(query, opts)
+	if err != nil {
+		return ReplaceResult{}, err
+	}
+	a.mu.Lock()
+	d, err := a.docLocked(docID)
+	if err != nil {
+		a.mu.Unlock()
+		return ReplaceResult{}, err
+	}
+	if d.LargeFile {
+		a.mu.Unlock()
+		return ReplaceResult{}, ErrLargeFile
+	}
+	content, version := d.Content, d.version
+	a.mu.Unlock()
+
+	replaced, n := m.replace(content, replacement, opts.Regex)
+	if n == 0 {
+		return ReplaceResult{Content: content}, nil
+	}
+
+	a.mu.Lock()
+	if d.version != version {
+		a.mu.Unlock()
+		return ReplaceResult{}, errors.New("document changed during replace, try again")
+	}
+	d.Content = replaced
+	d.Dirty = true
+	d.version++
+	a.mu.Unlock()
+
+	// keep both sides as separate steps so undo restores the original
+	a.undo.push(docID, content, false)
+	a.undo.push(docID, replaced, false)
+	return ReplaceResult{Content: replaced, Count: n}, nil
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMatcherEach(t *testing.T) {
+	text := "foo bar\nfoofoo _foo\nfoo\nçaça x* ab ab"
+	long := strings.Repeat("ab ", 500)
+	tests := []struct {
+		name  string
+		query string
+		text  string
+		opts  FindOptions
+	}{
+		{"literal", "foo", text, FindOptions{}},
+		{"ignore case", "FOO", text, FindOptions{}},
+		{"whole word", "foo", text, FindOptions{WholeWord: true}},
+		{"empty matches", "x*", text, FindOptions{Regex: true}},
+		{"multibyte", "ça", text, FindOptions{CaseSensitive: true}},
+		{"begin text", "^foo", text, FindOptions{Regex: true}},
+		{"begin line", "(?m)^foo", text, FindOptions{Regex: true}},
+		{"word boundary", `\bfoo`, text, FindOptions{Regex: true}},
+		{"no word boundary", `\Bfoo`, text, FindOptions{Regex: true}},
+		{"past one batch", `\bab`, long, FindOptions{Regex: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := newMatcher(tt.query, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var want [][]int
+			for _, loc := range m.re.FindAllStringSubmatchIndex(tt.text, -1) {
+				if loc[0] != loc[1] && (!tt.opts.WholeWord || isWordBoundary(tt.text, loc[0], loc[1])) {
+					want = append(want, loc)
+				}
+			}
+			var got [][]int
+			m.each(tt.text, func(loc []int) bool {
+				got = append(got, loc)
+				return true
+			})
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("each = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestMatcherFindLimit(t *testing.T) {
+	for _, query := range []string{"a", `\ba`} {
+		m, err := newMatcher(query, FindOptions{Regex: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		calls := 0
+		m.each(strings.Repeat("a ", 1000), func([]int) bool {
+			calls++
+			return calls < 3
+		})
+		if calls != 3 {
+			t.Errorf("%s: each went on for %d calls after being stopped at 3", query, calls)
+		}
+
+		res := m.find("a\na a\na", 3)
+		want := []Match{{1, 1, 1}, {2, 1, 1}, {2, 3, 1}}
+		if !reflect.DeepEqual(res.Matches, want) || !res.Truncated || res.Total != 3 {
+			t.Errorf("%s: find = %+v, want %v truncated", query, res, want)
+		}
+		if res := m.find("a a", 3); res.Truncated || res.Total != 2 {
+			t.Errorf("%s: find under the limit = %+v", query, res)
+		}
+	}
+}
+
+func TestMatcherReplace(t *testing.T) {
+	tests := []struct {
+		query, replacement, text string
+		want                     string
+		count                    int
+	}{
+		{`(\w+)@(\w+)`, "$2 at $1", "me@home, you@work", "home at me, work at you", 2},
+		{"x*", "-", "abc", "abc", 0},
+		{"^a", "b", "aaa", "baa", 1},
+		{`\ba`, "b", "aa aa", "ba ba", 2},
+	}
+	for _, tt := range tests {
+		m, err := newMatcher(tt.query, FindOptions{Regex: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, n := m.replace(tt.text, tt.replacement, true)
+		if got != tt.want || n != tt.count {
+			t.Errorf("replace(%q, %q) on %q = %q, %d, want %q, %d", tt.query, tt.replacement, tt.text, got, n, tt.want, tt.count)
+		}
+	}
+}
+
+func TestFindTotalCapped(t *testing.T) {
+	a := NewApp()
+	a.mu.Lock()
+	d := a.newDocumentLocked()
+	d.Content = strings.Repeat("x ", maxMatches+5)
+	a.mu.Unlock()
+
+	res := a.Find(d.ID, "x", FindOptions{})
+	if len(res.Matches) != maxMatches || res.Total != maxMatches || !res.Truncated {
+		t.Errorf("Find = %d matches, total %d, truncated %v, want %d, %d, true",
+			len(res.Matches), res.Total, res.Truncated, maxMatches, maxMatches)
+	}
+	a.mu.Lock()
+	a.active = d.ID
+	a.mu.Unlock()
+	if n, err := a.Count("x", FindOptions{}); err != nil || n != maxMatches+5 {
+		t.Errorf("Count = %d, %v, want the full %d", n, err, maxMatches+5)
+	}
+}
@@ -1,6 +1,255 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
+import {main} from '../models';
 
-export function OpenFile():Promise<string>;
+export function AddToUserDictionary(arg1:string):Promise<void>;
 
-export function SaveFile(arg1:string,arg2:string):Promise<string>;
+export function AnalyzeInvisibles(arg1:string):Promise<main.InvisibleReport>;
+
+export function ApplyColumnBlock(arg1:string,arg2:number,arg3:number,arg4:Array<string>,arg5:string):Promise<string>;
+
+export function ApplyUpdateOnExit():Promise<void>;
+
+export function CancelMacro():Promise<void>;
+
+export function CancelSearch(arg1:string):Promise<void>;
+
+export function CancelShare():Promise<void>;
+
+export function CancelUpdateDownload():Promise<void>;
+
+export function CheckForUpdates():Promise<main.UpdateInfo>;
+
+export function CheckRecovery():Promise<main.Recovery>;
+
+export function CheckSpelling(arg1:string,arg2:string):Promise<Array<main.Misspelling>>;
+
+export function ClearBookmarks(arg1:string):Promise<void>;
+
+export function ClearRecentFiles():Promise<void>;
+
+export function CloseDocument(arg1:string):Promise<void>;
+
+export function CloseFolder():Promise<void>;
+
+export function ComputeFileHash(arg1:string,arg2:string):Promise<string>;
+
+export function ComputeHash(arg1:string,arg2:Array<string>):Promise<Record<string, string>>;
+
+export function ConvertIndentation(arg1:string,arg2:main.IndentInfo,arg3:main.IndentInfo):Promise<string>;
+
+export function CopyToClipboard(arg1:string):Promise<void>;
+
+export function Count(arg1:string,arg2:main.FindOptions):Promise<number>;
+
+export function CreateDir(arg1:string,arg2:string):Promise<string>;
+
+export function CreateFile(arg1:string,arg2:string):Promise<string>;
+
+export function DeleteMacro(arg1:string):Promise<void>;
+
+export function DeletePath(arg1:string):Promise<void>;
+
+export function DeleteScratch(arg1:string):Promise<void>;
+
+export function DeleteSnippet(arg1:string):Promise<void>;
+
+export function DetectIndentation(arg1:string):Promise<main.IndentInfo>;
+
+export function DetectLanguage(arg1:string,arg2:string):Promise<main.LanguageInfo>;
+
+export function DiffDocuments(arg1:string,arg2:string,arg3:main.DiffOptions):Promise<main.DiffResult>;
+
+export function DiffFiles(arg1:string,arg2:string,arg3:main.DiffOptions):Promise<main.DiffResult>;
+
+export function DiffSaved(arg1:string,arg2:main.DiffOptions):Promise<main.DiffResult>;
+
+export function DiscardDocument(arg1:string):Promise<void>;
+
+export function DownloadUpdate(arg1:string):Promise<void>;
+
+export function EncodeDecode(arg1:string,arg2:string,arg3:boolean):Promise<string>;
+
+export function ExpandSnippet(arg1:string,arg2:Record<string, string>):Promise<string>;
+
+export function ExportAs(arg1:string,arg2:string,arg3:string):Promise<void>;
+
+export function ExtractColumnBlock(arg1:string,arg2:number,arg3:number,arg4:number,arg5:number):Promise<Array<string>>;
+
+export function Find(arg1:string,arg2:string,arg3:main.FindOptions):Promise<main.FindResult>;
+
+export function FormatStructured(arg1:string,arg2:string,arg3:number,arg4:boolean):Promise<string>;
+
+export function GenerateColumnSequence(arg1:number,arg2:number,arg3:number,arg4:string):Promise<Array<string>>;
+
+export function GetBookmarks(arg1:string):Promise<Array<number>>;
+
+export function GetClipboardHistory():Promise<Array<string>>;
+
+export function GetDocument(arg1:string):Promise<main.Document>;
+
+export function GetEncoding():Promise<string>;
+
+export function GetLineEnding():Promise<string>;
+
+export function GetOutline(arg1:string,arg2:string):Promise<Array<main.OutlineNode>>;
+
+export function GetRecentFiles():Promise<Array<string>>;
+
+export function GetRecentLogs(arg1:number):Promise<Array<string>>;
+
+export function GetSettings():Promise<main.Settings>;
+
+export function GetStartupDocuments():Promise<Array<main.Document>>;
+
+export function GetStats(arg1:string,arg2:string):Promise<main.Stats>;
+
+export function GetZoom():Promise<number>;
+
+export function HandleFileDrop(arg1:Array<string>):Promise<Array<main.DropResult>>;
+
+export function IsDirty():Promise<boolean>;
+
+export function ListBackups(arg1:string):Promise<Array<main.BackupInfo>>;
+
+export function ListDictionaries():Promise<Array<string>>;
+
+export function ListDir(arg1:string):Promise<Array<main.FileEntry>>;
+
+export function ListDocuments():Promise<Array<main.DocumentMeta>>;
+
+export function ListMacros():Promise<Array<main.Macro>>;
+
+export function ListScratches():Promise<Array<main.Scratch>>;
+
+export function ListSnippets():Promise<Array<main.Snippet>>;
+
+export function ListSupportedLanguages():Promise<Array<main.Language>>;
+
+export function ListTemplates():Promise<Array<main.TemplateInfo>>;
+
+export function LoadSession():Promise<main.SessionState>;
+
+export function NewDocument():Promise<main.Document>;
+
+export function NewFromTemplate(arg1:string):Promise<string>;
+
+export function NewScratch():Promise<string>;
+
+export function NextBookmark(arg1:string,arg2:number):Promise<number>;
+
+export function OnSecondInstance(arg1:Array<string>):Promise<void>;
+
+export function OpacitySupported():Promise<boolean>;
+
+export function OpenDocument(arg1:string):Promise<main.Document>;
+
+export function OpenFile():Promise<main.FileResult>;
+
+export function OpenFolder():Promise<string>;
+
+export function OpenInDefaultApp(arg1:string):Promise<void>;
+
+export function OpenTerminalHere(arg1:string):Promise<void>;
+
+export function PasteFromHistory(arg1:number):Promise<string>;
+
+export function PlayMacro(arg1:string,arg2:number):Promise<void>;
+
+export function Print(arg1:string,arg2:main.PrintOptions):Promise<number>;
+
+export function PromoteScratch(arg1:string,arg2:string):Promise<main.Document>;
+
+export function PushSnapshot(arg1:string,arg2:string):Promise<void>;
+
+export function ReadChunk(arg1:string,arg2:number,arg3:number):Promise<Array<string>>;
+
+export function RecordAction(arg1:main.EditorAction):Promise<void>;
+
+export function Redo(arg1:string):Promise<main.UndoResult>;
+
+export function RefreshMenu():Promise<void>;
+
+export function RenamePath(arg1:string,arg2:string):Promise<void>;
+
+export function ReplaceAll(arg1:string,arg2:string,arg3:string,arg4:main.FindOptions):Promise<main.ReplaceResult>;
+
+export function ResetZoom():Promise<number>;
+
+export function RestoreBackup(arg1:string):Promise<string>;
+
+export function RevealInFileManager(arg1:string):Promise<void>;
+
+export function SaveAsAdmin(arg1:string,arg2:string):Promise<void>;
+
+export function SaveAsTemplate(arg1:string,arg2:string,arg3:boolean):Promise<boolean>;
+
+export function SaveDocument(arg1:string,arg2:string):Promise<void>;
+
+export function SaveFile(arg1:string,arg2:string):Promise<main.FileResult>;
+
+export function SaveMacro(arg1:string,arg2:main.Macro):Promise<main.Macro>;
+
+export function SaveSession(arg1:main.SessionState):Promise<void>;
+
+export function SaveSnippet(arg1:main.Snippet):Promise<void>;
+
+export function SearchInFolder(arg1:string,arg2:string,arg3:main.SearchOptions):Promise<string>;
+
+export function SetActiveDocument(arg1:string):Promise<void>;
+
+export function SetAutoReload(arg1:boolean):Promise<void>;
+
+export function SetAutosaveInterval(arg1:number):Promise<void>;
+
+export function SetDirty(arg1:boolean):Promise<void>;
+
+export function SetDocumentLanguage(arg1:string,arg2:string):Promise<void>;
+
+export function SetEncoding(arg1:string):Promise<void>;
+
+export function SetGlobalHotkey(arg1:string):Promise<void>;
+
+export function SetLineEnding(arg1:string):Promise<string>;
+
+export function SetLogLevel(arg1:string):Promise<void>;
+
+export function SetOpacity(arg1:number):Promise<void>;
+
+export function SetUndoMemoryLimit(arg1:number):Promise<void>;
+
+export function SetWatchIgnorePatterns(arg1:Array<string>):Promise<void>;
+
+export function SetZoom(arg1:number):Promise<number>;
+
+export function ShareText(arg1:string,arg2:main.ShareOptions):Promise<string>;
+
+export function StartMacroRecording():Promise<void>;
+
+export function StopMacroRecording():Promise<main.Macro>;
+
+export function StripInvisibles(arg1:string,arg2:Array<string>):Promise<string>;
+
+export function SuggestCorrections(arg1:string,arg2:string):Promise<Array<string>>;
+
+export function ToggleAlwaysOnTop():Promise<boolean>;
+
+export function ToggleBookmark(arg1:string,arg2:number):Promise<Array<number>>;
+
+export function ToggleFullscreen():Promise<boolean>;
+
+export function TransformText(arg1:string,arg2:string):Promise<string>;
+
+export function TrayAvailable():Promise<boolean>;
+
+export function Undo(arg1:string):Promise<main.UndoResult>;
+
+export function UpdateContent(arg1:string):Promise<void>;
+
+export function UpdateDocument(arg1:string,arg2:string):Promise<void>;
+
+export function UpdateScratch(arg1:string,arg2:string):Promise<void>;
+
+export function UpdateSettings(arg1:Record<string, any>):Promise<main.Settings>;
+
+export function ValidateStructured(arg1:string,arg2:string):Promise<Array<main.Diagnostic>>;
@@ -2,10 +2,506 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function AddToUserDictionary(arg1) {
+  return window['go']['main']['App']['AddToUserDictionary'](arg1);
+}
+
+export function AnalyzeInvisibles(arg1) {
+  return window['go']['main']['App']['AnalyzeInvisibles'](arg1);
+}
+
+export function ApplyColumnBlock(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['ApplyColumnBlock'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function ApplyUpdateOnExit() {
+  return window['go']['main']['App']['ApplyUpdateOnExit']();
+}
+
+export function CancelMacro() {
+  return window['go']['main']['App']['CancelMacro']();
+}
+
+export function CancelSearch(arg1) {
+  return window['go']['main']['App']['CancelSearch'](arg1);
+}
+
+export function CancelShare() {
+  return window['go']['main']['App']['CancelShare']();
+}
+
+export function CancelUpdateDownload() {
+  return window['go']['main']['App']['CancelUpdateDownload']();
+}
+
+export function CheckForUpdates() {
+  return window['go']['main']['App']['CheckForUpdates']();
+}
+
+export function CheckRecovery() {
+  return window['go']['main']['App']['CheckRecovery']();
+}
+
+export function CheckSpelling(arg1, arg2) {
+  return window['go']['main']['App']['CheckSpelling'](arg1, arg2);
+}
+
+export function ClearBookmarks(arg1) {
+  return window['go']['main']['App']['ClearBookmarks'](arg1);
+}
+
+export function ClearRecentFiles() {
+  return window['go']['main']['App']['ClearRecentFiles']();
+}
+
+export function CloseDocument(arg1) {
+  return window['go']['main']['App']['CloseDocument'](arg1);
+}
+
+export function CloseFolder() {
+  return window['go']['main']['App']['CloseFolder']();
+}
+
+export function ComputeFileHash(arg1, arg2) {
+  return window['go']['main']['App']['ComputeFileHash'](arg1, arg2);
+}
+
+export function ComputeHash(arg1, arg2) {
+  return window['go']['main']['App']['ComputeHash'](arg1, arg2);
+}
+
+export function ConvertIndentation(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ConvertIndentation'](arg1, arg2, arg3);
+}
+
+export function CopyToClipboard(arg1) {
+  return window['go']['main']['App']['CopyToClipboard'](arg1);
+}
+
+export function Count(arg1, arg2) {
+  return window['go']['main']['App']['Count'](arg1, arg2);
+}
+
+export function CreateDir(arg1, arg2) {
+  return window['go']['main']['App']['CreateDir'](arg1, arg2);
+}
+
+export function CreateFile(arg1, arg2) {
+  return window['go']['main']['App']['CreateFile'](arg1, arg2);
+}
+
+export function DeleteMacro(arg1) {
+  return window['go']['main']['App']['DeleteMacro'](arg1);
+}
+
+export function DeletePath(arg1) {
+  return window['go']['main']['App']['DeletePath'](arg1);
+}
+
+export function DeleteScratch(arg1) {
+  return window['go']['main']['App']['DeleteScratch'](arg1);
+}
+
+export function DeleteSnippet(arg1) {
+  return window['go']['main']['App']['DeleteSnippet'](arg1);
+}
+
+export function DetectIndentation(arg1) {
+  return window['go']['main']['App']['DetectIndentation'](arg1);
+}
+
+export function DetectLanguage(arg1, arg2) {
+  return window['go']['main']['App']['DetectLanguage'](arg1, arg2);
+}
+
+export function DiffDocuments(arg1, arg2, arg3) {
+  return window['go']['main']['App']['DiffDocuments'](arg1, arg2, arg3);
+}
+
+export function DiffFiles(arg1, arg2, arg3) {
+  return window['go']['main']['App']['DiffFiles'](arg1, arg2, arg3);
+}
+
+export function DiffSaved(arg1, arg2) {
+  return window['go']['main']['App']['DiffSaved'](arg1, arg2);
+}
+
+export function DiscardDocument(arg1) {
+  return window['go']['main']['App']['DiscardDocument'](arg1);
+}
+
+export function DownloadUpdate(arg1) {
+  return window['go']['main']['App']['DownloadUpdate'](arg1);
+}
+
+export function EncodeDecode(arg1, arg2, arg3) {
+  return window['go']['main']['App']['EncodeDecode'](arg1, arg2, arg3);
+}
+
+export function ExpandSnippet(arg1, arg2) {
+  return window['go']['main']['App']['ExpandSnippet'](arg1, arg2);
+}
+
+export function ExportAs(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ExportAs'](arg1, arg2, arg3);
+}
+
+export function ExtractColumnBlock(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['ExtractColumnBlock'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function Find(arg1, arg2, arg3) {
+  return window['go']['main']['App']['Find'](arg1, arg2, arg3);
+}
+
+export function FormatStructured(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['FormatStructured'](arg1, arg2, arg3, arg4);
+}
+
+export function GenerateColumnSequence(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['GenerateColumnSequence'](arg1, arg2, arg3, arg4);
+}
+
+export function GetBookmarks(arg1) {
+  return window['go']['main']['App']['GetBookmarks'](arg1);
+}
+
+export function GetClipboardHistory() {
+  return window['go']['main']['App']['GetClipboardHistory']();
+}
+
+export function GetDocument(arg1) {
+  return window['go']['main']['App']['GetDocument'](arg1);
+}
+
+export function GetEncoding() {
+  return window['go']['main']['App']['GetEncoding']();
+}
+
+export function GetLineEnding() {
+  return window['go']['main']['App']['GetLineEnding']();
+}
+
+export function GetOutline(arg1, arg2) {
+  return window['go']['main']['App']['GetOutline'](arg1, arg2);
+}
+
+export function GetRecentFiles() {
+  return window['go']['main']['App']['GetRecentFiles']();
+}
+
+export function GetRecentLogs(arg1) {
+  return window['go']['main']['App']['GetRecentLogs'](arg1);
+}
+
+export function GetSettings() {
+  return window['go']['main']['App']['GetSettings']();
+}
+
+export function GetStartupDocuments() {
+  return window['go']['main']['App']['GetStartupDocuments']();
+}
+
+export function GetStats(arg1, arg2) {
+  return window['go']['main']['App']['GetStats'](arg1, arg2);
+}
+
+export function GetZoom() {
+  return window['go']['main']['App']['GetZoom']();
+}
+
+export function HandleFileDrop(arg1) {
+  return window['go']['main']['App']['HandleFileDrop'](arg1);
+}
+
+export function IsDirty() {
+  return window['go']['main']['App']['IsDirty']();
+}
+
+export function ListBackups(arg1) {
+  return window['go']['main']['App']['ListBackups'](arg1);
+}
+
+export function ListDictionaries() {
+  return window['go']['main']['App']['ListDictionaries']();
+}
+
+export function ListDir(arg1) {
+  return window['go']['main']['App']['ListDir'](arg1);
+}
+
+export function ListDocuments() {
+  return window['go']['main']['App']['ListDocuments']();
+}
+
+export function ListMacros() {
+  return window['go']['main']['App']['ListMacros']();
+}
+
+export function ListScratches() {
+  return window['go']['main']['App']['ListScratches']();
+}
+
+export function ListSnippets() {
+  return window['go']['main']['App']['ListSnippets']();
+}
+
+export function ListSupportedLanguages() {
+  return window['go']['main']['App']['ListSupportedLanguages']();
+}
+
+export function ListTemplates() {
+  return window['go']['main']['App']['ListTemplates']();
+}
+
+export function LoadSession() {
+  return window['go']['main']['App']['LoadSession']();
+}
+
+export function NewDocument() {
+  return window['go']['main']['App']['NewDocument']();
+}
+
+export function NewFromTemplate(arg1) {
+  return window['go']['main']['App']['NewFromTemplate'](arg1);
+}
+
+export function NewScratch() {
+  return window['go']['main']['App']['NewScratch']();
+}
+
+export function NextBookmark(arg1, arg2) {
+  return window['go']['main']['App']['NextBookmark'](arg1, arg2);
+}
+
+export function OnSecondInstance(arg1) {
+  return window['go']['main']['App']['OnSecondInstance'](arg1);
+}
+
+export function OpacitySupported() {
+  return window['go']['main']['App']['OpacitySupported']();
+}
+
+export function OpenDocument(arg1) {
+  return window['go']['main']['App']['OpenDocument'](arg1);
+}
+
 export function OpenFile() {
   return window['go']['main']['App']['OpenFile']();
 }
 
+export function OpenFolder() {
+  return window['go']['main']['App']['OpenFolder']();
+}
+
+export function OpenInDefaultApp(arg1) {
+  return window['go']['main']['App']['OpenInDefaultApp'](arg1);
+}
+
+export function OpenTerminalHere(arg1) {
+  return window['go']['main']['App']['OpenTerminalHere'](arg1);
+}
+
+export function PasteFromHistory(arg1) {
+  return window['go']['main']['App']['PasteFromHistory'](arg1);
+}
+
+export function PlayMacro(arg1, arg2) {
+  return window['go']['main']['App']['PlayMacro'](arg1, arg2);
+}
+
+export function Print(arg1, arg2) {
+  return window['go']['main']['App']['Print'](arg1, arg2);
+}
+
+export function PromoteScratch(arg1, arg2) {
+  return window['go']['main']['App']['PromoteScratch'](arg1, arg2);
+}
+
+export function PushSnapshot(arg1, arg2) {
+  return window['go']['main']['App']['PushSnapshot'](arg1, arg2);
+}
+
+export function ReadChunk(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ReadChunk'](arg1, arg2, arg3);
+}
+
+export function RecordAction(arg1) {
+  return window['go']['main']['App']['RecordAction'](arg1);
+}
+
+export function Redo(arg1) {
+  return window['go']['main']['App']['Redo'](arg1);
+}
+
+export function RefreshMenu() {
+  return window['go']['main']['App']['RefreshMenu']();
+}
+
+export function RenamePath(arg1, arg2) {
+  return window['go']['main']['App']['RenamePath'](arg1, arg2);
+}
+
+export function ReplaceAll(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['ReplaceAll'](arg1, arg2, arg3, arg4);
+}
+
+export function ResetZoom() {
+  return window['go']['main']['App']['ResetZoom']();
+}
+
+export function RestoreBackup(arg1) {
+  return window['go']['main']['App']['RestoreBackup'](arg1);
+}
+
+export function RevealInFileManager(arg1) {
+  return window['go']['main']['App']['RevealInFileManager'](arg1);
+}
+
+export function SaveAsAdmin(arg1, arg2) {
+  return window['go']['main']['App']['SaveAsAdmin'](arg1, arg2);
+}
+
+export function SaveAsTemplate(arg1, arg2, arg3) {
+  return window['go']['main']['App']['SaveAsTemplate'](arg1, arg2, arg3);
+}
+
+export function SaveDocument(arg1, arg2) {
+  return window['go']['main']['App']['SaveDocument'](arg1, arg2);
+}
+
 export function SaveFile(arg1, arg2) {
   return window['go']['main']['App']['SaveFile'](arg1, arg2);
 }
+
+export function SaveMacro(arg1, arg2) {
+  return window['go']['main']['App']['SaveMacro'](arg1, arg2);
+}
+
+export function SaveSession(arg1) {
+  return window['go']['main']['App']['SaveSession'](arg1);
+}
+
+export function SaveSnippet(arg1) {
+  return window['go']['main']['App']['SaveSnippet'](arg1);
+}
+
+export function SearchInFolder(arg1, arg2, arg3) {
+  return window['go']['main']['App']['SearchInFolder'](arg1, arg2, arg3);
+}
+
+export function SetActiveDocument(arg1) {
+  return window['go']['main']['App']['SetActiveDocument'](arg1);
+}
+
+export function SetAutoReload(arg1) {
+  return window['go']['main']['App']['SetAutoReload'](arg1);
+}
+
+export function SetAutosaveInterval(arg1) {
+  return window['go']['main']['App']['SetAutosaveInterval'](arg1);
+}
+
+export function SetDirty(arg1) {
+  return window['go']['main']['App']['SetDirty'](arg1);
+}
+
+export function SetDocumentLanguage(arg1, arg2) {
+  return window['go']['main']['App']['SetDocumentLanguage'](arg1, arg2);
+}
+
+export function SetEncoding(arg1) {
+  return window['go']['main']['App']['SetEncoding'](arg1);
+}
+
+export function SetGlobalHotkey(arg1) {
+  return window['go']['main']['App']['SetGlobalHotkey'](arg1);
+}
+
+export function SetLineEnding(arg1) {
+  return window['go']['main']['App']['SetLineEnding'](arg1);
+}
+
+export function SetLogLevel(arg1) {
+  return window['go']['main']['App']['SetLogLevel'](arg1);
+}
+
+export function SetOpacity(arg1) {
+  return window['go']['main']['App']['SetOpacity'](arg1);
+}
+
+export function SetUndoMemoryLimit(arg1) {
+  return window['go']['main']['App']['SetUndoMemoryLimit'](arg1);
+}
+
+export function SetWatchIgnorePatterns(arg1) {
+  return window['go']['main']['App']['SetWatchIgnorePatterns'](arg1);
+}
+
+export function SetZoom(arg1) {
+  return window['go']['main']['App']['SetZoom'](arg1);
+}
+
+export function ShareText(arg1, arg2) {
+  return window['go']['main']['App']['ShareText'](arg1, arg2);
+}
+
+export function StartMacroRecording() {
+  return window['go']['main']['App']['StartMacroRecording']();
+}
+
+export function StopMacroRecording() {
+  return window['go']['main']['App']['StopMacroRecording']();
+}
+
+export function StripInvisibles(arg1, arg2) {
+  return window['go']['main']['App']['StripInvisibles'](arg1, arg2);
+}
+
+export function SuggestCorrections(arg1, arg2) {
+  return window['go']['main']['App']['SuggestCorrections'](arg1, arg2);
+}
+
+export function ToggleAlwaysOnTop() {
+  return window['go']['main']['App']['ToggleAlwaysOnTop']();
+}
+
+export function ToggleBookmark(arg1, arg2) {
+  return window['go']['main']['App']['ToggleBookmark'](arg1, arg2);
+}
+
+export function ToggleFullscreen() {
+  return window['go']['main']['App']['ToggleFullscreen']();
+}
+
+export function TransformText(arg1, arg2) {
+  return window['go']['main']['App']['TransformText'](arg1, arg2);
+}
+
+export function TrayAvailable() {
+  return window['go']['main']['App']['TrayAvailable']();
+}
+
+export function Undo(arg1) {
+  return window['go']['main']['App']['Undo'](arg1);
+}
+
+export function UpdateContent(arg1) {
+  return window['go']['main']['App']['UpdateContent'](arg1);
+}
+
+export function UpdateDocument(arg1, arg2) {
+  return window['go']['main']['App']['UpdateDocument'](arg1, arg2);
+}
+
+export function UpdateScratch(arg1, arg2) {
+  return window['go']['main']['App']['UpdateScratch'](arg1, arg2);
+}
+
+export function UpdateSettings(arg1) {
+  return window['go']['main']['App']['UpdateSettings'](arg1);
+}
+
+export function ValidateStructured(arg1, arg2) {
+  return window['go']['main']['App']['ValidateStructured'](arg1, arg2);
+}
@@ -0,0 +1,1084 @@
+export namespace main {
+	
+	export class BackupInfo {
+	    path: string;
+	    kind: string;
+	    size: number;
+	    // Go type: time
+	    created: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new BackupInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.kind = source["kind"];
+	        this.size = source["size"];
+	        this.created = this.convertValues(source["created"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Counts {
+	    lines: number;
+	    words: number;
+	    chars: number;
+	    bytes: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Counts(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.lines = source["lines"];
+	        this.words = source["words"];
+	        this.chars = source["chars"];
+	        this.bytes = source["bytes"];
+	    }
+	}
+	export class Diagnostic {
+	    line: number;
+	    column: number;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Diagnostic(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.line = source["line"];
+	        this.column = source["column"];
+	        this.message = source["message"];
+	    }
+	}
+	export class DiffHunk {
+	    type: string;
+	    leftStart: number;
+	    leftCount: number;
+	    rightStart: number;
+	    rightCount: number;
+	    left: string[];
+	    right: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new DiffHunk(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.type = source["type"];
+	        this.leftStart = source["leftStart"];
+	        this.leftCount = source["leftCount"];
+	        this.rightStart = source["rightStart"];
+	        this.rightCount = source["rightCount"];
+	        this.left = source["left"];
+	        this.right = source["right"];
+	    }
+	}
+	export class DiffOptions {
+	    ignoreWhitespace: boolean;
+	    ignoreCase: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new DiffOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.ignoreWhitespace = source["ignoreWhitespace"];
+	        this.ignoreCase = source["ignoreCase"];
+	    }
+	}
+	export class DiffResult {
+	    hunks: DiffHunk[];
+	    added: number;
+	    removed: number;
+	    identical: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new DiffResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.hunks = this.convertValues(source["hunks"], DiffHunk);
+	        this.added = source["added"];
+	        this.removed = source["removed"];
+	        this.identical = source["identical"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Document {
+	    id: string;
+	    path: string;
+	    content: string;
+	    dirty: boolean;
+	    encoding: string;
+	    lineEnding: string;
+	    language: string;
+	    lossy?: boolean;
+	    title?: string;
+	    readOnly?: boolean;
+	    largeFile?: boolean;
+	    size?: number;
+	    lines?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Document(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.path = source["path"];
+	        this.content = source["content"];
+	        this.dirty = source["dirty"];
+	        this.encoding = source["encoding"];
+	        this.lineEnding = source["lineEnding"];
+	        this.language = source["language"];
+	        this.lossy = source["lossy"];
+	        this.title = source["title"];
+	        this.readOnly = source["readOnly"];
+	        this.largeFile = source["largeFile"];
+	        this.size = source["size"];
+	        this.lines = source["lines"];
+	    }
+	}
+	export class DocumentMeta {
+	    id: string;
+	    path: string;
+	    name: string;
+	    dirty: boolean;
+	    encoding: string;
+	    lineEnding: string;
+	    language: string;
+	    largeFile?: boolean;
+	    active: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new DocumentMeta(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.path = source["path"];
+	        this.name = source["name"];
+	        this.dirty = source["dirty"];
+	        this.encoding = source["encoding"];
+	        this.lineEnding = source["lineEnding"];
+	        this.language = source["language"];
+	        this.largeFile = source["largeFile"];
+	        this.active = source["active"];
+	    }
+	}
+	export class FileError {
+	    code: string;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FileError(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.code = source["code"];
+	        this.message = source["message"];
+	    }
+	}
+	export class DropResult {
+	    path: string;
+	    document?: Document;
+	    error?: FileError;
+	
+	    static createFrom(source: any = {}) {
+	        return new DropResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.document = this.convertValues(source["document"], Document);
+	        this.error = this.convertValues(source["error"], FileError);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class EditorAction {
+	    type: string;
+	    text?: string;
+	    data?: Record<string, any>;
+	
+	    static createFrom(source: any = {}) {
+	        return new EditorAction(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.type = source["type"];
+	        this.text = source["text"];
+	        this.data = source["data"];
+	    }
+	}
+	export class FileEntry {
+	    name: string;
+	    path: string;
+	    isDir: boolean;
+	    size: number;
+	    // Go type: time
+	    modTime: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new FileEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.path = source["path"];
+	        this.isDir = source["isDir"];
+	        this.size = source["size"];
+	        this.modTime = this.convertValues(source["modTime"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class FileResult {
+	    id?: string;
+	    path: string;
+	    content: string;
+	    encoding?: string;
+	    lineEnding?: string;
+	    lossy?: boolean;
+	    readOnly?: boolean;
+	    largeFile?: boolean;
+	    size?: number;
+	    lines?: number;
+	    error?: FileError;
+	
+	    static createFrom(source: any = {}) {
+	        return new FileResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.path = source["path"];
+	        this.content = source["content"];
+	        this.encoding = source["encoding"];
+	        this.lineEnding = source["lineEnding"];
+	        this.lossy = source["lossy"];
+	        this.readOnly = source["readOnly"];
+	        this.largeFile = source["largeFile"];
+	        this.size = source["size"];
+	        this.lines = source["lines"];
+	        this.error = this.convertValues(source["error"], FileError);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FindOptions {
+	    caseSensitive: boolean;
+	    wholeWord: boolean;
+	    regex: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new FindOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.caseSensitive = source["caseSensitive"];
+	        this.wholeWord = source["wholeWord"];
+	        this.regex = source["regex"];
+	    }
+	}
+	export class QueryError {
+	    code: string;
+	    pattern: string;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new QueryError(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.code = source["code"];
+	        this.pattern = source["pattern"];
+	        this.message = source["message"];
+	    }
+	}
+	export class Match {
+	    line: number;
+	    column: number;
+	    length: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Match(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.line = source["line"];
+	        this.column = source["column"];
+	        this.length = source["length"];
+	    }
+	}
+	export class FindResult {
+	    matches: Match[];
+	    truncated: boolean;
+	    total: number;
+	    error?: QueryError;
+	
+	    static createFrom(source: any = {}) {
+	        return new FindResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.matches = this.convertValues(source["matches"], Match);
+	        this.truncated = source["truncated"];
+	        this.total = source["total"];
+	        this.error = this.convertValues(source["error"], QueryError);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class IndentInfo {
+	    useTabs: boolean;
+	    width: number;
+	    confidence: number;
+	    mixed: boolean;
+	    tabLines: number;
+	    spaceLines: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new IndentInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.useTabs = source["useTabs"];
+	        this.width = source["width"];
+	        this.confidence = source["confidence"];
+	        this.mixed = source["mixed"];
+	        this.tabLines = source["tabLines"];
+	        this.spaceLines = source["spaceLines"];
+	    }
+	}
+	export class Invisible {
+	    class: string;
+	    name: string;
+	    codepoint?: string;
+	    line: number;
+	    column: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Invisible(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.class = source["class"];
+	        this.name = source["name"];
+	        this.codepoint = source["codepoint"];
+	        this.line = source["line"];
+	        this.column = source["column"];
+	    }
+	}
+	export class InvisibleReport {
+	    items: Invisible[];
+	    counts: Record<string, number>;
+	    truncated: boolean;
+	    mixedIndent: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new InvisibleReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.items = this.convertValues(source["items"], Invisible);
+	        this.counts = source["counts"];
+	        this.truncated = source["truncated"];
+	        this.mixedIndent = source["mixedIndent"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Language {
+	    id: string;
+	    name: string;
+	    extensions: string[];
+	    filenames?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Language(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.extensions = source["extensions"];
+	        this.filenames = source["filenames"];
+	    }
+	}
+	export class LanguageInfo {
+	    id: string;
+	    name: string;
+	    confidence: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new LanguageInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.confidence = source["confidence"];
+	    }
+	}
+	export class Macro {
+	    id: string;
+	    name?: string;
+	    actions: EditorAction[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Macro(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.actions = this.convertValues(source["actions"], EditorAction);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Margins {
+	    top: number;
+	    right: number;
+	    bottom: number;
+	    left: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Margins(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.top = source["top"];
+	        this.right = source["right"];
+	        this.bottom = source["bottom"];
+	        this.left = source["left"];
+	    }
+	}
+	
+	export class Misspelling {
+	    word: string;
+	    offset: number;
+	    length: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Misspelling(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.word = source["word"];
+	        this.offset = source["offset"];
+	        this.length = source["length"];
+	    }
+	}
+	export class OutlineNode {
+	    label: string;
+	    kind: string;
+	    line: number;
+	    children?: OutlineNode[];
+	
+	    static createFrom(source: any = {}) {
+	        return new OutlineNode(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.label = source["label"];
+	        this.kind = source["kind"];
+	        this.line = source["line"];
+	        this.children = this.convertValues(source["children"], OutlineNode);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PrintOptions {
+	    pageSize: string;
+	    margins: Margins;
+	    header: string;
+	    footer: string;
+	    fontSize: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new PrintOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.pageSize = source["pageSize"];
+	        this.margins = this.convertValues(source["margins"], Margins);
+	        this.header = source["header"];
+	        this.footer = source["footer"];
+	        this.fontSize = source["fontSize"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class Recovery {
+	    found: boolean;
+	    path: string;
+	    content: string;
+	    // Go type: time
+	    savedAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Recovery(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.found = source["found"];
+	        this.path = source["path"];
+	        this.content = source["content"];
+	        this.savedAt = this.convertValues(source["savedAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ReplaceResult {
+	    content: string;
+	    count: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ReplaceResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.content = source["content"];
+	        this.count = source["count"];
+	    }
+	}
+	export class Scratch {
+	    id: string;
+	    title: string;
+	    content: string;
+	    // Go type: time
+	    modified: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Scratch(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.title = source["title"];
+	        this.content = source["content"];
+	        this.modified = this.convertValues(source["modified"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SearchOptions {
+	    caseSensitive: boolean;
+	    wholeWord: boolean;
+	    regex: boolean;
+	    include: string[];
+	    exclude: string[];
+	    maxFileSize: number;
+	    includeBinary: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new SearchOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.caseSensitive = source["caseSensitive"];
+	        this.wholeWord = source["wholeWord"];
+	        this.regex = source["regex"];
+	        this.include = source["include"];
+	        this.exclude = source["exclude"];
+	        this.maxFileSize = source["maxFileSize"];
+	        this.includeBinary = source["includeBinary"];
+	    }
+	}
+	export class SessionFile {
+	    path: string;
+	    line: number;
+	    column: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SessionFile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.line = source["line"];
+	        this.column = source["column"];
+	    }
+	}
+	export class WindowGeometry {
+	    x: number;
+	    y: number;
+	    width: number;
+	    height: number;
+	    maximised: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new WindowGeometry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.x = source["x"];
+	        this.y = source["y"];
+	        this.width = source["width"];
+	        this.height = source["height"];
+	        this.maximised = source["maximised"];
+	    }
+	}
+	export class SessionState {
+	    files: SessionFile[];
+	    active: number;
+	    window: WindowGeometry;
+	    zoom?: number;
+	    skipped?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SessionState(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.files = this.convertValues(source["files"], SessionFile);
+	        this.active = source["active"];
+	        this.window = this.convertValues(source["window"], WindowGeometry);
+	        this.zoom = source["zoom"];
+	        this.skipped = source["skipped"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Settings {
+	    fontFamily: string;
+	    fontSize: number;
+	    theme: string;
+	    tabWidth: number;
+	    insertSpaces: boolean;
+	    wordWrap: boolean;
+	    showLineNumbers: boolean;
+	    persistClipboard: boolean;
+	    largeFileMB: number;
+	    backupMode: string;
+	    backupKeep: number;
+	    minimizeToTray: boolean;
+	    globalHotkey: string;
+	    zoom: number;
+	    spellSkipCode: boolean;
+	    watchIgnore: string[];
+	    utf8Bom: string;
+	    author: string;
+	    alwaysOnTop: boolean;
+	    opacity: number;
+	    terminal: string;
+	    shareUrl: string;
+	    shareFormat: string;
+	    shareApiKey: string;
+	    updateCheck: boolean;
+	    updateRepo: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Settings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.fontFamily = source["fontFamily"];
+	        this.fontSize = source["fontSize"];
+	        this.theme = source["theme"];
+	        this.tabWidth = source["tabWidth"];
+	        this.insertSpaces = source["insertSpaces"];
+	        this.wordWrap = source["wordWrap"];
+	        this.showLineNumbers = source["showLineNumbers"];
+	        this.persistClipboard = source["persistClipboard"];
+	        this.largeFileMB = source["largeFileMB"];
+	        this.backupMode = source["backupMode"];
+	        this.backupKeep = source["backupKeep"];
+	        this.minimizeToTray = source["minimizeToTray"];
+	        this.globalHotkey = source["globalHotkey"];
+	        this.zoom = source["zoom"];
+	        this.spellSkipCode = source["spellSkipCode"];
+	        this.watchIgnore = source["watchIgnore"];
+	        this.utf8Bom = source["utf8Bom"];
+	        this.author = source["author"];
+	        this.alwaysOnTop = source["alwaysOnTop"];
+	        this.opacity = source["opacity"];
+	        this.terminal = source["terminal"];
+	        this.shareUrl = source["shareUrl"];
+	        this.shareFormat = source["shareFormat"];
+	        this.shareApiKey = source["shareApiKey"];
+	        this.updateCheck = source["updateCheck"];
+	        this.updateRepo = source["updateRepo"];
+	    }
+	}
+	export class ShareOptions {
+	    expiry?: string;
+	    visibility?: string;
+	    filename?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ShareOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.expiry = source["expiry"];
+	        this.visibility = source["visibility"];
+	        this.filename = source["filename"];
+	    }
+	}
+	export class Snippet {
+	    name: string;
+	    trigger?: string;
+	    language?: string;
+	    description?: string;
+	    body: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Snippet(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.trigger = source["trigger"];
+	        this.language = source["language"];
+	        this.description = source["description"];
+	        this.body = source["body"];
+	    }
+	}
+	export class Stats {
+	    document: Counts;
+	    selection?: Counts;
+	
+	    static createFrom(source: any = {}) {
+	        return new Stats(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.document = this.convertValues(source["document"], Counts);
+	        this.selection = this.convertValues(source["selection"], Counts);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TemplateInfo {
+	    name: string;
+	    language: string;
+	    size: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TemplateInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.language = source["language"];
+	        this.size = source["size"];
+	    }
+	}
+	export class UndoResult {
+	    content: string;
+	    ok: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new UndoResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.content = source["content"];
+	        this.ok = source["ok"];
+	    }
+	}
+	export class UpdateInfo {
+	    checked: boolean;
+	    available: boolean;
+	    current: string;
+	    latest?: string;
+	    notes?: string;
+	    pageUrl?: string;
+	    assetUrl?: string;
+	    assetName?: string;
+	    assetSize?: number;
+	    message?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new UpdateInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.checked = source["checked"];
+	        this.available = source["available"];
+	        this.current = source["current"];
+	        this.latest = source["latest"];
+	        this.notes = source["notes"];
+	        this.pageUrl = source["pageUrl"];
+	        this.assetUrl = source["assetUrl"];
+	        this.assetName = source["assetName"];
+	        this.assetSize = source["assetSize"];
+	        this.message = source["message"];
+	    }
+	}
+
+}
+
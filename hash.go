@@ -0,0 +1,84 @@
+package main
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// hashes maps algorithm names to constructors
+var hashes = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+	"crc32":  func() hash.Hash { return crc32.NewIEEE() },
+}
+
+// newHash returns a hash for algo, or an error naming the supported ones
+func newHash(algo string) (hash.Hash, error) {
+	fn, ok := hashes[strings.ToLower(algo)]
+	if !ok {
+		names := make([]string, 0, len(hashes))
+		for n := range hashes {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown hash algorithm %q, expected one of %s", algo, strings.Join(names, ", "))
+	}
+	return fn(), nil
+}
+
+// digest returns h's sum as lowercase hex. CRC-32 sums come out big-endian,
+// matching how checksum tools print them.
+func digest(h hash.Hash) string {
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// ComputeHash returns the lowercase hex digest of text for each algorithm,
+// keyed by algorithm name
+func (a *App) ComputeHash(text string, algos []string) (map[string]string, error) {
+	hs := make([]hash.Hash, len(algos))
+	writers := make([]io.Writer, len(algos))
+	for i, algo := range algos {
+		h, err := newHash(algo)
+		if err != nil {
+			return nil, err
+		}
+		hs[i], writers[i] = h, h
+	}
+	io.WriteString(io.MultiWriter(writers...), text)
+
+	sums := make(map[string]string, len(algos))
+	for i, algo := range algos {
+		sums[strings.ToLower(algo)] = digest(hs[i])
+	}
+	return sums, nil
+}
+
+// ComputeFileHash streams the file at path through algo and returns its
+// lowercase hex digest, so large files are never loaded whole
+func (a *App) ComputeFileHash(path string, algo string) (string, error) {
+	h, err := newHash(algo)
+	if err != nil {
+		return "", err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "", newFileError(path, err)
+	}
+	defer f.Close()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", newFileError(path, err)
+	}
+	return digest(h), nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// hashVectors are the published digests of "" and "abc"
+var hashVectors = map[string]map[string]string{
+	"": {
+		"md5":    "d41d8cd98f00b204e9800998ecf8427e",
+		"sha1":   "da39a3ee5e6b4b0d3255bfef95601890afd80709",
+		"sha256": "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		"sha512": "cf83e1357eefb8bdf1542850d66d8007d620e4050b5715dc83f4a921d36ce9ce47d0d13c5d85f2b0ff8318d2877eec2f63b931bd47417a81a538327af927da3e",
+		"crc32":  "00000000",
+	},
+	"abc": {
+		"md5":    "900150983cd24fb0d6963f7d28e17f72",
+		"sha1":   "a9993e364706816aba3e25717850c26c9cd0d89d",
+		"sha256": "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+		"sha512": "ddaf35a193617abacc417349ae20413112e6fa4e89a97ea20a9eeee64b55d39a2192992a274fc1a836ba3c23a3feebbd454d4423643ce80e2a9ac94fa54ca49f",
+		"crc32":  "352441c2",
+	},
+}
+
+func TestComputeHash(t *testing.T) {
+	a := NewApp()
+	algos := []string{"md5", "SHA1", "sha256", "sha512", "crc32"}
+	for text, want := range hashVectors {
+		got, err := a.ComputeHash(text, algos)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for algo, sum := range want {
+			if got[algo] != sum {
+				t.Errorf("%s(%q) = %s, want %s", algo, text, got[algo], sum)
+			}
+		}
+	}
+}
+
+func TestComputeHashUnknown(t *testing.T) {
+	a := NewApp()
+	_, err := a.ComputeHash("abc", []string{"sha256", "sha3"})
+	if err == nil || !strings.Contains(err.Error(), `"sha3"`) {
+		t.Fatalf("err = %v, want one naming sha3", err)
+	}
+	if _, err := a.ComputeFileHash("whatever", "blake2"); err == nil {
+		t.Fatal("ComputeFileHash accepted an unknown algorithm")
+	}
+}
+
+func TestComputeFileHash(t *testing.T) {
+	a := NewApp()
+	path := filepath.Join(t.TempDir(), "abc.txt")
+	// big enough to take several reads of io.Copy
+	content := strings.Repeat("abc", 100000)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for algo := range hashVectors["abc"] {
+		want, err := a.ComputeHash(content, []string{algo})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := a.ComputeFileHash(path, algo)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want[algo] {
+			t.Errorf("%s of the file = %s, want %s", algo, got, want[algo])
+		}
+	}
+
+	small := filepath.Join(t.TempDir(), "small.txt")
+	os.WriteFile(small, []byte("abc"), 0o644)
+	if got, _ := a.ComputeFileHash(small, "sha256"); got != hashVectors["abc"]["sha256"] {
+		t.Errorf("sha256 of abc file = %s", got)
+	}
+	if _, err := a.ComputeFileHash(filepath.Join(t.TempDir(), "missing"), "md5"); err == nil {
+		t.Error("ComputeFileHash of a missing file returned no error")
+	}
+}
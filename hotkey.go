@@ -0,0 +1,181 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// errHotkeyUnsupported is returned where global hotkeys cannot be registered
+var errHotkeyUnsupported = errors.New("global hotkeys are not supported on this platform")
+
+// hotkey is a parsed accelerator such as Ctrl+Alt+N
+type hotkey struct {
+	Ctrl, Alt, Shift, Super bool
+	Key                     string // canonical key name, see hotkeyKeys
+}
+
+// hotkeyKeys are the key names an accelerator may end in, besides letters,
+// digits and F1 to F24
+var hotkeyKeys = []string{
+	"Space", "Enter", "Tab", "Escape", "Backspace", "Insert", "Delete",
+	"Home", "End", "PageUp", "PageDown", "Up", "Down", "Left", "Right",
+}
+
+// HotkeyError is sent as a hotkey:error event when the hotkey could not be
+// registered at startup or after a settings change
+type HotkeyError struct {
+	Hotkey  string `json:"hotkey"`
+	Message string `json:"message"`
+}
+
+// parseHotkey reads an accelerator like "Ctrl+Shift+F5". Modifier and key
+// names are case-insensitive, and at least one of Ctrl, Alt and Super is
+// required so the hotkey cannot swallow ordinary typing.
+func parseHotkey(spec string) (hotkey, error) {
+	var h hotkey
+	parts := strings.Split(spec, "+")
+	for i, p := range parts {
+		p = strings.TrimSpace(p)
+		if i < len(parts)-1 {
+			switch strings.ToLower(p) {
+			case "ctrl", "control":
+				h.Ctrl = true
+			case "alt", "option":
+				h.Alt = true
+			case "shift":
+				h.Shift = true
+			case "super", "win", "cmd", "meta":
+				h.Super = true
+			default:
+				return hotkey{}, fmt.Errorf("unknown modifier %q in hotkey %q", p, spec)
+			}
+			continue
+		}
+		key, ok := hotkeyKey(p)
+		if !ok {
+			return hotkey{}, fmt.Errorf("unknown key %q in hotkey %q", p, spec)
+		}
+		h.Key = key
+	}
+	if !h.Ctrl && !h.Alt && !h.Super {
+		return hotkey{}, fmt.Errorf("hotkey %q needs Ctrl, Alt or Super", spec)
+	}
+	return h, nil
+}
+
+// hotkeyKey returns the canonical name of a key
+func hotkeyKey(name string) (string, bool) {
+	if len(name) == 1 {
+		c := strings.ToUpper(name)[0]
+		if c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' {
+			return string(c), true
+		}
+		return "", false
+	}
+	var n int
+	if _, err := fmt.Sscanf(strings.ToUpper(name), "F%d", &n); err == nil && n >= 1 && n <= 24 && strings.EqualFold(name, fmt.Sprintf("F%d", n)) {
+		return fmt.Sprintf("F%d", n), true
+	}
+	for _, k := range hotkeyKeys {
+		if strings.EqualFold(k, name) {
+			return k, true
+		}
+	}
+	return "", false
+}
+
+func (h hotkey) String() string {
+	var parts []string
+	if h.Ctrl {
+		parts = append(parts, "Ctrl")
+	}
+	if h.Alt {
+		parts = append(parts, "Alt")
+	}
+	if h.Shift {
+		parts = append(parts, "Shift")
+	}
+	if h.Super {
+		parts = append(parts, "Super")
+	}
+	return strings.Join(append(parts, h.Key), "+")
+}
+
+// SetGlobalHotkey registers spec as the system-wide hotkey that brings up
+// the window with a new quick note, replacing the previous one, and saves
+// it in the settings. An empty spec turns the hotkey off.
+func (a *App) SetGlobalHotkey(spec string) error {
+	canonical := ""
+	if strings.TrimSpace(spec) != "" {
+		h, err := parseHotkey(spec)
+		if err != nil {
+			return err
+		}
+		canonical = h.String()
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.registerHotkeyLocked(canonical); err != nil {
+		return err
+	}
+	next := a.settings
+	next.GlobalHotkey = canonical
+	if err := saveSettings(next); err != nil {
+		return err
+	}
+	a.settings = next
+	return nil
+}
+
+// registerHotkeyLocked swaps the registered hotkey for spec. On failure
+// the previous hotkey is put back. a.mu must be held.
+func (a *App) registerHotkeyLocked(spec string) error {
+	// the old registration goes first in case spec is the same keys
+	if a.hotkeyStop != nil {
+		a.hotkeyStop()
+		a.hotkeyStop = nil
+	}
+	old := a.hotkey
+	a.hotkey = ""
+	if spec == "" {
+		return nil
+	}
+	h, err := parseHotkey(spec)
+	if err != nil {
+		return err
+	}
+	stop, err := registerHotkey(h, a.quickNote)
+	if err != nil {
+		if h, perr := parseHotkey(old); perr == nil {
+			if stop, rerr := registerHotkey(h, a.quickNote); rerr == nil {
+				a.hotkey, a.hotkeyStop = old, stop
+			}
+		}
+		return err
+	}
+	a.hotkey, a.hotkeyStop = spec, stop
+	return nil
+}
+
+// hotkeyError describes a failed registration of spec for the frontend
+func hotkeyError(spec string, err error) HotkeyError {
+	return HotkeyError{Hotkey: spec, Message: err.Error()}
+}
+
+// quickNote brings the window forward with a new document named after the
+// current time
+func (a *App) quickNote() {
+	a.mu.Lock()
+	d := a.newDocumentLocked()
+	d.Title = "Quick Note " + time.Now().Format("2006-01-02 15.04.05")
+	doc := *d
+	a.mu.Unlock()
+
+	a.showWindow()
+	runtime.EventsEmit(a.ctx, "file:opened", doc)
+}
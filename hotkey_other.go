@@ -0,0 +1,13 @@
+//go:build !windows
+
+package main
+
+// defaultHotkey is empty since registerHotkey always fails here
+const defaultHotkey = ""
+
+// registerHotkey reports that global hotkeys are unavailable. Wayland has no
+// way for an app to grab keys system-wide, and on macOS it takes the
+// accessibility permission and a Carbon event handler on the main thread.
+func registerHotkey(h hotkey, fn func()) (stop func(), err error) {
+	return nil, errHotkeyUnsupported
+}
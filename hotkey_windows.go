@@ -0,0 +1,124 @@
+//go:build windows
+
+package main
+
+import (
+	"fmt"
+	goruntime "runtime"
+	"syscall"
+	"unsafe"
+)
+
+// defaultHotkey summons the window with a new quick note
+const defaultHotkey = "Ctrl+Alt+N"
+
+var (
+	user32                 = syscall.NewLazyDLL("user32.dll")
+	procRegisterHotKey     = user32.NewProc("RegisterHotKey")
+	procUnregisterHotKey   = user32.NewProc("UnregisterHotKey")
+	procGetMessageW        = user32.NewProc("GetMessageW")
+	procPostThreadMessageW = user32.NewProc("PostThreadMessageW")
+	procGetCurrentThreadId = kernel32.NewProc("GetCurrentThreadId")
+)
+
+// hotkeyVK maps key names other than letters and digits to virtual key codes
+var hotkeyVK = map[string]uintptr{
+	"Space": 0x20, "Enter": 0x0D, "Tab": 0x09, "Escape": 0x1B, "Backspace": 0x08,
+	"Insert": 0x2D, "Delete": 0x2E, "Home": 0x24, "End": 0x23,
+	"PageUp": 0x21, "PageDown": 0x22,
+	"Up": 0x26, "Down": 0x28, "Left": 0x25, "Right": 0x27,
+}
+
+func virtualKey(key string) uintptr {
+	if len(key) == 1 {
+		return uintptr(key[0]) // VK codes of letters and digits are their ASCII
+	}
+	var n int
+	if _, err := fmt.Sscanf(key, "F%d", &n); err == nil {
+		return 0x70 + uintptr(n-1)
+	}
+	return hotkeyVK[key]
+}
+
+// registerHotkey registers h for the whole desktop and calls fn each time
+// it is pressed. RegisterHotKey ties the hotkey to the calling thread, so
+// each registration gets a locked thread running its own message loop.
+func registerHotkey(h hotkey, fn func()) (stop func(), err error) {
+	const (
+		modAlt      = 0x1
+		modControl  = 0x2
+		modShift    = 0x4
+		modWin      = 0x8
+		modNoRepeat = 0x4000
+		wmHotkey    = 0x0312
+		wmQuit      = 0x0012
+
+		errorHotkeyAlreadyRegistered = 1409
+	)
+	mods := uintptr(modNoRepeat)
+	if h.Alt {
+		mods |= modAlt
+	}
+	if h.Ctrl {
+		mods |= modControl
+	}
+	if h.Shift {
+		mods |= modShift
+	}
+	if h.Super {
+		mods |= modWin
+	}
+
+	type started struct {
+		thread uintptr
+		err    error
+	}
+	ready := make(chan started)
+	done := make(chan struct{})
+	go func() {
+		goruntime.LockOSThread()
+		defer goruntime.UnlockOSThread()
+		defer close(done)
+
+		thread, _, _ := procGetCurrentThreadId.Call()
+		if ret, _, callErr := procRegisterHotKey.Call(0, 1, mods, virtualKey(h.Key)); ret == 0 {
+			err := fmt.Errorf("could not register %s: %v", h, callErr)
+			if errno, ok := callErr.(syscall.Errno); ok && errno == errorHotkeyAlreadyRegistered {
+				err = fmt.Errorf("%s is already in use by another application", h)
+			}
+			ready <- started{err: err}
+			return
+		}
+		defer procUnregisterHotKey.Call(0, 1)
+		ready <- started{thread: thread}
+
+		var msg struct {
+			hwnd    uintptr
+			message uint32
+			wParam  uintptr
+			lParam  uintptr
+			time    uint32
+			pt      struct{ x, y int32 }
+		}
+		for {
+			ret, _, _ := procGetMessageW.Call(uintptr(unsafe.Pointer(&msg)), 0, 0, 0)
+			if ret == 0 || int32(ret) == -1 {
+				return
+			}
+			if msg.message == wmHotkey {
+				// fn may need locks held by whoever is stopping us
+				go fn()
+			}
+		}
+	}()
+
+	s := <-ready
+	if s.err != nil {
+		<-done
+		return nil, s.err
+	}
+	return func() {
+		procPostThreadMessageW.Call(s.thread, wmQuit, 0, 0)
+		<-done
+	}, nil
+}
@@ -0,0 +1,225 @@
+package main
+
+import "strings"
+
+// indentSample is the number of lines DetectIndentation looks at
+const indentSample = 10000
+
+// indentWidths are the space indentation widths DetectIndentation reports
+var indentWidths = []int{2, 4, 8}
+
+// IndentInfo describes how a document is indented
+type IndentInfo struct {
+	UseTabs bool `json:"useTabs"`
+	// Width is the number of spaces per level, or the width of a tab
+	Width int `json:"width"`
+	// Confidence runs from 0, nothing indented to go by, to 1 when every
+	// indented line agrees
+	Confidence float64 `json:"confidence"`
+	// Mixed is set when some lines are indented with tabs and others with
+	// spaces
+	Mixed      bool `json:"mixed"`
+	TabLines   int  `json:"tabLines"`
+	SpaceLines int  `json:"spaceLines"`
+}
+
+// DetectIndentation works out from the start of content whether it is
+// indented with tabs or spaces, and with how many spaces a level. Without
+// any indentation to go by the settings are reported.
+func (a *App) DetectIndentation(content string) IndentInfo {
+	a.mu.Lock()
+	settings := a.settings
+	a.mu.Unlock()
+	return detectIndentation(content, IndentInfo{UseTabs: !settings.InsertSpaces, Width: settings.TabWidth})
+}
+
+// detectIndentation does the work of DetectIndentation, reporting info as
+// it is when there is nothing to go by
+func detectIndentation(content string, info IndentInfo) IndentInfo {
+	deltas := map[int]int{}
+	totalDeltas := 0
+	prev := 0 // spaces before the last line, -1 after a tab indented one
+	var lit literalTracker
+	for n, line := range strings.SplitN(content, "\n", indentSample+1) {
+		if n == indentSample {
+			break
+		}
+		line = strings.TrimSuffix(line, "\r")
+		inside := lit.inside()
+		lit.scan(line)
+		indent := leadingWhitespace(line)
+		if inside || len(indent) == len(line) {
+			// string contents and blank lines say nothing
+			continue
+		}
+		switch {
+		case indent == "":
+			prev = 0
+		case indent[0] == '\t':
+			info.TabLines++
+			prev = -1
+		case len(indent) == 1:
+			// most likely the * of a block comment, not indentation
+		case strings.Trim(indent, " ") == "":
+			info.SpaceLines++
+			// odd steps and big jumps are alignment, not indentation
+			if d := len(indent) - prev; prev >= 0 && d > 0 && d%2 == 0 && d <= 8 {
+				deltas[d]++
+				totalDeltas++
+			}
+			prev = len(indent)
+		}
+	}
+
+	indented := info.TabLines + info.SpaceLines
+	if indented == 0 {
+		return info
+	}
+	info.Mixed = info.TabLines > 0 && info.SpaceLines > 0
+	info.UseTabs = info.TabLines > info.SpaceLines
+	agree := max(info.TabLines, info.SpaceLines)
+	info.Confidence = float64(agree) / float64(indented)
+	if info.UseTabs {
+		return info
+	}
+	best := 0
+	for _, w := range indentWidths {
+		// a tie goes to the wider, 2 and 4 both fit a file stepping by 4
+		if deltas[w] > 0 && deltas[w] >= deltas[best] {
+			best = w
+		}
+	}
+	if best == 0 {
+		// every indented line at the same depth, going by the step alone
+		return info
+	}
+	info.Width = best
+	info.Confidence *= float64(deltas[best]) / float64(totalDeltas)
+	return info
+}
+
+// ConvertIndentation reindents content from one style to another. Only
+// leading whitespace changes: whole levels are converted and spaces left
+// over are kept. A line indented more than a level past the one before is
+// taken for a continuation aligned with it, and keeps its alignment. Lines
+// inside multi-line string literals are left as they are. Text already in
+// the to style comes out unchanged, so converting twice gives the same
+// result: from tabs only lines indented with a tab are converted, and from
+// one space width to another only a text that steps by the from width is,
+// which it no longer does once converted.
+func (a *App) ConvertIndentation(content string, from IndentInfo, to IndentInfo) string {
+	a.mu.Lock()
+	width := a.settings.TabWidth
+	a.mu.Unlock()
+	fromWidth, toWidth := validIndentWidth(from.Width, width), validIndentWidth(to.Width, width)
+	if !from.UseTabs && !to.UseTabs && fromWidth != toWidth {
+		if d := detectIndentation(content, IndentInfo{}); !d.UseTabs && d.Width != 0 && d.Width != fromWidth {
+			return content
+		}
+	}
+
+	convert := func(cols int) string {
+		levels, rest := cols/fromWidth, cols%fromWidth
+		if to.UseTabs {
+			return strings.Repeat("\t", levels) + strings.Repeat(" ", rest)
+		}
+		return strings.Repeat(" ", levels*toWidth+rest)
+	}
+	var lit literalTracker
+	// the line a continuation line is aligned against, before and after
+	baseCols, base := 0, ""
+	return mapLines(content, func(lines []string) []string {
+		for i, line := range lines {
+			inside := lit.inside()
+			lit.scan(line)
+			indent := leadingWhitespace(line)
+			if inside || len(indent) == len(line) {
+				continue
+			}
+			if from.UseTabs && !to.UseTabs && !strings.Contains(indent, "\t") {
+				// already converted, or aligned with spaces to begin with
+				continue
+			}
+			cols := len(expandTabs(indent, fromWidth))
+			if cols > baseCols+fromWidth {
+				// deeper than one more level is a continuation, aligned
+				// with something on the line it continues
+				lines[i] = base + strings.Repeat(" ", cols-baseCols) + line[len(indent):]
+				continue
+			}
+			baseCols, base = cols, convert(cols)
+			lines[i] = base + line[len(indent):]
+		}
+		return lines
+	})
+}
+
+func validIndentWidth(width, fallback int) int {
+	if width < 1 || width > 16 {
+		return fallback
+	}
+	return width
+}
+
+// leadingWhitespace returns the tabs and spaces line starts with
+func leadingWhitespace(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
+
+// literalTracker follows multi-line string literals through a document
+// line by line: triple quoted strings and backtick strings, which cover
+// Python, Go raw strings, JavaScript templates and Markdown code fences
+type literalTracker struct {
+	open string // the delimiter of the literal the line ends inside, or ""
+	// comment starts a line comment, whose quotes are not looked at
+	comment string
+}
+
+func (t *literalTracker) inside() bool {
+	return t.open != ""
+}
+
+// scan moves the tracker past one line. Ordinary quoted strings end with
+// the line, so only their contents have to be skipped.
+func (t *literalTracker) scan(line string) {
+	for i := 0; i < len(line); {
+		if t.open != "" {
+			j := strings.Index(line[i:], t.open)
+			if j < 0 {
+				return
+			}
+			i += j + len(t.open)
+			t.open = ""
+			continue
+		}
+		rest := line[i:]
+		switch {
+		case t.comment != "" && strings.HasPrefix(rest, t.comment):
+			return
+		case strings.HasPrefix(rest, `"""`), strings.HasPrefix(rest, "'''"):
+			t.open = rest[:3]
+			i += 3
+		case rest[0] == '`':
+			t.open = "`"
+			i++
+		case rest[0] == '"' || rest[0] == '\'':
+			i += quotedLen(rest)
+		default:
+			i++
+		}
+	}
+}
+
+// quotedLen returns the length of the quoted string s starts with, or all
+// of s when it is not closed on the line
+func quotedLen(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case s[0]:
+			return i + 1
+		}
+	}
+	return len(s)
+}
@@ -0,0 +1,105 @@
+package main
+
+import "testing"
+
+// indentSamples are the same code indented with tabs, with 2 spaces and
+// with 4, continuation lines aligned with spaces
+var indentSamples = map[string]string{
+	"tabs":     "func f() {\n\tif x {\n\t\tcall(a,\n\t\t     b)\n\t}\n\ts := `\n    raw`\n}\n",
+	"spaces2":  "func f() {\n  if x {\n    call(a,\n         b)\n  }\n  s := `\n    raw`\n}\n",
+	"spaces4":  "func f() {\n    if x {\n        call(a,\n             b)\n    }\n    s := `\n    raw`\n}\n",
+	"python2":  "class A:\n  def m(self):\n    return [1,\n            2]\n",
+	"python4":  "class A:\n    def m(self):\n        return [1,\n                2]\n",
+	"markdown": "- a\n  - b\n    - c\n",
+}
+
+var (
+	tabs4   = IndentInfo{UseTabs: true, Width: 4}
+	spaces2 = IndentInfo{Width: 2}
+	spaces4 = IndentInfo{Width: 4}
+)
+
+func TestDetectIndentation(t *testing.T) {
+	tests := []struct {
+		sample  string
+		useTabs bool
+		width   int
+	}{
+		{"tabs", true, 4},
+		{"spaces2", false, 2},
+		{"spaces4", false, 4},
+		{"python2", false, 2},
+		{"python4", false, 4},
+	}
+	a := NewApp()
+	for _, tt := range tests {
+		got := a.DetectIndentation(indentSamples[tt.sample])
+		if got.UseTabs != tt.useTabs || got.Width != tt.width {
+			t.Errorf("%s: detected %+v, want tabs %v width %d", tt.sample, got, tt.useTabs, tt.width)
+		}
+	}
+	if got := a.DetectIndentation("no\nindentation\n"); got.Confidence != 0 || got.Width != a.settings.TabWidth {
+		t.Errorf("unindented text detected as %+v, want the settings", got)
+	}
+}
+
+func TestConvertIndentation(t *testing.T) {
+	tests := []struct {
+		from, to string
+		fromInfo IndentInfo
+		toInfo   IndentInfo
+	}{
+		{"tabs", "spaces4", tabs4, spaces4},
+		{"spaces4", "tabs", spaces4, tabs4},
+		{"spaces2", "spaces4", spaces2, spaces4},
+		{"spaces4", "spaces2", spaces4, spaces2},
+		{"python2", "python4", spaces2, spaces4},
+		{"python4", "python2", spaces4, spaces2},
+	}
+	a := NewApp()
+	for _, tt := range tests {
+		t.Run(tt.from+" to "+tt.to, func(t *testing.T) {
+			once := a.ConvertIndentation(indentSamples[tt.from], tt.fromInfo, tt.toInfo)
+			if once != indentSamples[tt.to] {
+				t.Errorf("converted to %q, want %q", once, indentSamples[tt.to])
+			}
+			if twice := a.ConvertIndentation(once, tt.fromInfo, tt.toInfo); twice != once {
+				t.Errorf("converting again gave %q, want %q", twice, once)
+			}
+		})
+	}
+}
+
+func TestConvertIndentationIdempotent(t *testing.T) {
+	styles := map[string]IndentInfo{
+		"tabs": tabs4, "tabs2": {UseTabs: true, Width: 2}, "spaces2": spaces2, "spaces4": spaces4, "spaces8": {Width: 8},
+	}
+	a := NewApp()
+	for sample, content := range indentSamples {
+		for fromName, from := range styles {
+			for toName, to := range styles {
+				once := a.ConvertIndentation(content, from, to)
+				if twice := a.ConvertIndentation(once, from, to); twice != once {
+					t.Errorf("%s, %s to %s: second run changed %q to %q", sample, fromName, toName, once, twice)
+				}
+			}
+		}
+	}
+}
+
+func TestConvertIndentationAlreadyConverted(t *testing.T) {
+	a := NewApp()
+	for _, tt := range []struct {
+		sample   string
+		from, to IndentInfo
+	}{
+		{"spaces4", spaces2, spaces4},
+		{"spaces2", spaces4, spaces2},
+		{"spaces4", tabs4, spaces4},
+		{"tabs", spaces4, tabs4},
+	} {
+		if got := a.ConvertIndentation(indentSamples[tt.sample], tt.from, tt.to); got != indentSamples[tt.sample] {
+			t.Errorf("%s from %+v to %+v changed to %q", tt.sample, tt.from, tt.to, got)
+		}
+	}
+}
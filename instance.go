@@ -0,0 +1,44 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/options"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// instanceID names the lock that keeps wailspad to a single process. Wails
+// holds it with a D-Bus name on Linux and a named mutex on Windows, both of
+// which the OS releases when a process dies, so a crash leaves nothing stale.
+const instanceID = "io.github.sean5446.wailspad"
+
+// onSecondInstanceLaunch receives the command line of a later launch
+func (a *App) onSecondInstanceLaunch(data options.SecondInstanceData) {
+	args := make([]string, 0, len(data.Args))
+	for _, arg := range data.Args {
+		if !strings.HasPrefix(arg, "-") && !filepath.IsAbs(arg) && data.WorkingDirectory != "" {
+			arg = filepath.Join(data.WorkingDirectory, arg)
+		}
+		args = append(args, arg)
+	}
+	a.OnSecondInstance(args)
+}
+
+// OnSecondInstance opens the files another launch of wailspad was given and
+// brings this window to the front
+func (a *App) OnSecondInstance(args []string) {
+	// a second instance cannot hand over its stdin, only file names
+	files := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg != "-" && arg != "" {
+			files = append(files, arg)
+		}
+	}
+
+	runtime.WindowUnminimise(a.ctx)
+	runtime.WindowShow(a.ctx)
+	for _, d := range a.openArgs(files, "") {
+		runtime.EventsEmit(a.ctx, "file:opened", d)
+	}
+}
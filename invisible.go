@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxInvisibles caps how many occurrences AnalyzeInvisibles lists
+const maxInvisibles = 10000
+
+// invisibleClasses are the classes reported by AnalyzeInvisibles and
+// accepted by StripInvisibles
+var invisibleClasses = []string{"bom", "zero-width", "nbsp", "bidi", "mixed-indent"}
+
+// invisibleRune describes a character AnalyzeInvisibles looks for
+type invisibleRune struct {
+	class string
+	name  string
+}
+
+var invisibleRunes = map[rune]invisibleRune{
+	'\uFEFF': {"bom", "byte order mark"},
+	'\u200B': {"zero-width", "zero width space"},
+	'\u200C': {"zero-width", "zero width non-joiner"},
+	'\u200D': {"zero-width", "zero width joiner"},
+	'\u2060': {"zero-width", "word joiner"},
+	'\u180E': {"zero-width", "Mongolian vowel separator"},
+	'\u00AD': {"zero-width", "soft hyphen"},
+	'\u00A0': {"nbsp", "no-break space"},
+	'\u202F': {"nbsp", "narrow no-break space"},
+	'\u2007': {"nbsp", "figure space"},
+	'\u200E': {"bidi", "left-to-right mark"},
+	'\u200F': {"bidi", "right-to-left mark"},
+	'\u061C': {"bidi", "Arabic letter mark"},
+	'\u202A': {"bidi", "left-to-right embedding"},
+	'\u202B': {"bidi", "right-to-left embedding"},
+	'\u202C': {"bidi", "pop directional formatting"},
+	'\u202D': {"bidi", "left-to-right override"},
+	'\u202E': {"bidi", "right-to-left override"},
+	'\u2066': {"bidi", "left-to-right isolate"},
+	'\u2067': {"bidi", "right-to-left isolate"},
+	'\u2068': {"bidi", "first strong isolate"},
+	'\u2069': {"bidi", "pop directional isolate"},
+}
+
+// Invisible is one hidden character, or a line indented with both tabs and
+// spaces. Line and Column are 1-based and count runes.
+type Invisible struct {
+	Class     string `json:"class"`
+	Name      string `json:"name"`
+	Codepoint string `json:"codepoint,omitempty"` // like U+200B
+	Line      int    `json:"line"`
+	Column    int    `json:"column"`
+}
+
+// InvisibleReport is returned by AnalyzeInvisibles
+type InvisibleReport struct {
+	Items []Invisible `json:"items"`
+	// Counts has the total per class, including items left out past the
+	// cap
+	Counts    map[string]int `json:"counts"`
+	Truncated bool           `json:"truncated"`
+	// MixedIndent is set when some lines are indented with tabs and others
+	// with spaces
+	MixedIndent bool `json:"mixedIndent"`
+}
+
+// AnalyzeInvisibles lists the byte order marks, zero width, no-break and
+// bidirectional control characters in text, and the lines whose
+// indentation mixes tabs and spaces
+func (a *App) AnalyzeInvisibles(text string) InvisibleReport {
+	report := InvisibleReport{Items: []Invisible{}, Counts: map[string]int{}}
+	for _, c := range invisibleClasses {
+		report.Counts[c] = 0
+	}
+	add := func(item Invisible) {
+		report.Counts[item.Class]++
+		if len(report.Items) < maxInvisibles {
+			report.Items = append(report.Items, item)
+		} else {
+			report.Truncated = true
+		}
+	}
+
+	line, col := 1, 1
+	atStart := true // still in the line's indentation
+	var tabs, spaces bool
+	var tabLines, spaceLines bool
+	endIndent := func() {
+		if tabs && spaces {
+			add(Invisible{Class: "mixed-indent", Name: "tabs and spaces", Line: line, Column: 1})
+		}
+		tabLines = tabLines || tabs
+		spaceLines = spaceLines || spaces
+		atStart, tabs, spaces = false, false, false
+	}
+	for _, r := range text {
+		if atStart {
+			switch r {
+			case '\t':
+				tabs = true
+			case ' ':
+				spaces = true
+			default:
+				if r != '\n' && r != '\r' {
+					endIndent()
+				} else {
+					// blank lines say nothing about indentation
+					atStart, tabs, spaces = false, false, false
+				}
+			}
+		}
+		if inv, ok := invisibleRunes[r]; ok {
+			add(Invisible{Class: inv.class, Name: inv.name, Codepoint: fmt.Sprintf("U+%04X", r), Line: line, Column: col})
+		}
+		if r == '\n' {
+			line, col, atStart = line+1, 1, true
+			continue
+		}
+		col++
+	}
+	report.MixedIndent = tabLines && spaceLines
+	return report
+}
+
+// StripInvisibles removes the characters of the given classes from text.
+// No-break spaces become plain spaces rather than vanishing, and
+// mixed-indent reindents lines that mix tabs and spaces in the style of the
+// insertSpaces setting.
+func (a *App) StripInvisibles(text string, classes []string) string {
+	a.mu.Lock()
+	settings := a.settings
+	a.mu.Unlock()
+
+	strip := map[string]bool{}
+	for _, c := range classes {
+		strip[c] = true
+	}
+	if strip["bom"] || strip["zero-width"] || strip["nbsp"] || strip["bidi"] {
+		text = strings.Map(func(r rune) rune {
+			inv, ok := invisibleRunes[r]
+			switch {
+			case !ok || !strip[inv.class]:
+				return r
+			case inv.class == "nbsp":
+				return ' '
+			default:
+				return -1
+			}
+		}, text)
+	}
+	if strip["mixed-indent"] {
+		text = mapLines(text, func(lines []string) []string {
+			for i, l := range lines {
+				lines[i] = reindent(l, settings.TabWidth, settings.InsertSpaces)
+			}
+			return lines
+		})
+	}
+	return text
+}
+
+// reindent rewrites the indentation of a line that mixes tabs and spaces
+// as all spaces or as tabs, keeping its width
+func reindent(line string, width int, spaces bool) string {
+	n := 0
+	for n < len(line) && (line[n] == ' ' || line[n] == '\t') {
+		n++
+	}
+	indent := line[:n]
+	if !strings.Contains(indent, " ") || !strings.Contains(indent, "\t") {
+		return line
+	}
+	expanded := string(expandTabs(indent, width))
+	if spaces {
+		return expanded + line[n:]
+	}
+	return tabifyIndent(expanded, width) + line[n:]
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Language is a syntax the editor can highlight. IDs match the Monaco
+// editor's language identifiers.
+type Language struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Extensions []string `json:"extensions"`
+	Filenames  []string `json:"filenames,omitempty"`
+}
+
+// LanguageInfo is the result of language detection. Confidence runs from 0
+// (a guess) to 1 (certain).
+type LanguageInfo struct {
+	ID         string  `json:"id"`
+	Name       string  `json:"name"`
+	Confidence float64 `json:"confidence"`
+}
+
+const plaintext = "plaintext"
+
+var languages = []Language{
+	{ID: plaintext, Name: "Plain Text", Extensions: []string{".txt", ".text", ".log"}},
+	{ID: "bat", Name: "Batch", Extensions: []string{".bat", ".cmd"}},
+	{ID: "c", Name: "C", Extensions: []string{".c", ".h"}},
+	{ID: "cpp", Name: "C++", Extensions: []string{".cpp", ".cc", ".cxx", ".hpp", ".hh", ".hxx"}},
+	{ID: "csharp", Name: "C#", Extensions: []string{".cs"}},
+	{ID: "css", Name: "CSS", Extensions: []string{".css"}},
+	{ID: "dockerfile", Name: "Dockerfile", Extensions: []string{".dockerfile"}, Filenames: []string{"Dockerfile", "Containerfile"}},
+	{ID: "go", Name: "Go", Extensions: []string{".go"}},
+	{ID: "html", Name: "HTML", Extensions: []string{".html", ".htm", ".xhtml"}},
+	{ID: "ini", Name: "INI", Extensions: []string{".ini", ".cfg", ".conf", ".properties"}, Filenames: []string{".editorconfig", ".gitconfig"}},
+	{ID: "java", Name: "Java", Extensions: []string{".java"}},
+	{ID: "javascript", Name: "JavaScript", Extensions: []string{".js", ".mjs", ".cjs", ".jsx"}},
+	{ID: "json", Name: "JSON", Extensions: []string{".json", ".jsonc", ".webmanifest"}},
+	{ID: "kotlin", Name: "Kotlin", Extensions: []string{".kt", ".kts"}},
+	{ID: "lua", Name: "Lua", Extensions: []string{".lua"}},
+	{ID: "markdown", Name: "Markdown", Extensions: []string{".md", ".markdown", ".mdown", ".mkd"}},
+	{ID: "perl", Name: "Perl", Extensions: []string{".pl", ".pm"}},
+	{ID: "php", Name: "PHP", Extensions: []string{".php"}},
+	{ID: "powershell", Name: "PowerShell", Extensions: []string{".ps1", ".psm1", ".psd1"}},
+	{ID: "python", Name: "Python", Extensions: []string{".py", ".pyw", ".pyi"}},
+	{ID: "ruby", Name: "Ruby", Extensions: []string{".rb"}, Filenames: []string{"Gemfile", "Rakefile"}},
+	{ID: "rust", Name: "Rust", Extensions: []string{".rs"}},
+	{ID: "shell", Name: "Shell", Extensions: []string{".sh", ".bash", ".zsh"}, Filenames: []string{".bashrc", ".zshrc", ".profile"}},
+	{ID: "sql", Name: "SQL", Extensions: []string{".sql"}},
+	{ID: "swift", Name: "Swift", Extensions: []string{".swift"}},
+	{ID: "typescript", Name: "TypeScript", Extensions: []string{".ts", ".tsx", ".mts", ".cts"}},
+	{ID: "xml", Name: "XML", Extensions: []string{".xml", ".xsd", ".xsl", ".svg", ".plist", ".csproj"}},
+	{ID: "yaml", Name: "YAML", Extensions: []string{".yaml", ".yml"}},
+}
+
+// shebangs maps interpreters named on a #! line to languages
+var shebangs = map[string]string{
+	"sh": "shell", "bash": "shell", "zsh": "shell", "dash": "shell", "ksh": "shell",
+	"python": "python", "python2": "python", "python3": "python",
+	"node": "javascript", "deno": "typescript",
+	"perl": "perl", "ruby": "ruby", "lua": "lua", "php": "php",
+	"pwsh": "powershell",
+}
+
+// languageByID returns the language with the given ID
+func languageByID(id string) (Language, bool) {
+	for _, l := range languages {
+		if l.ID == id {
+			return l, true
+		}
+	}
+	return Language{}, false
+}
+
+func languageInfo(id string, confidence float64) LanguageInfo {
+	l, _ := languageByID(id)
+	return LanguageInfo{ID: l.ID, Name: l.Name, Confidence: confidence}
+}
+
+// languageSample is the start of content used for sniffing
+func languageSample(content string) string {
+	const n = 4096
+	if len(content) > n {
+		return content[:n]
+	}
+	return content
+}
+
+// detectLanguage picks a language from the file name, falling back to
+// sniffing the start of the content
+func detectLanguage(path string, sample string) LanguageInfo {
+	base := filepath.Base(path)
+	ext := strings.ToLower(filepath.Ext(base))
+	for _, l := range languages {
+		for _, name := range l.Filenames {
+			if base == name {
+				return languageInfo(l.ID, 0.95)
+			}
+		}
+	}
+	if ext != "" {
+		for _, l := range languages {
+			for _, e := range l.Extensions {
+				if ext == e {
+					return languageInfo(l.ID, 0.9)
+				}
+			}
+		}
+	}
+	if info, ok := sniffLanguage(sample); ok {
+		return info
+	}
+	return languageInfo(plaintext, 0)
+}
+
+// sniffLanguage recognises content by its first line or characters
+func sniffLanguage(sample string) (LanguageInfo, bool) {
+	s := strings.TrimPrefix(sample, "\uFEFF")
+	if strings.HasPrefix(s, "#!") {
+		line := s[2:]
+		if i := strings.IndexAny(line, "\r\n"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) > 0 {
+			interp := filepath.Base(fields[0])
+			if interp == "env" && len(fields) > 1 {
+				interp = fields[1]
+				if interp == "-S" && len(fields) > 2 {
+					interp = fields[2]
+				}
+			}
+			// python3.12 and the like
+			interp = strings.TrimRight(interp, "0123456789.")
+			if id, ok := shebangs[interp]; ok {
+				return languageInfo(id, 0.9), true
+			}
+			if id, ok := shebangs[interp+"3"]; ok {
+				return languageInfo(id, 0.9), true
+			}
+		}
+		return languageInfo("shell", 0.5), true
+	}
+
+	t := strings.TrimSpace(s)
+	lower := strings.ToLower(t)
+	switch {
+	case strings.HasPrefix(t, "<?xml"):
+		return languageInfo("xml", 0.9), true
+	case strings.HasPrefix(lower, "<!doctype html"), strings.HasPrefix(lower, "<html"):
+		return languageInfo("html", 0.9), true
+	case strings.HasPrefix(t, "<?php"):
+		return languageInfo("php", 0.9), true
+	case strings.HasPrefix(t, "{") || strings.HasPrefix(t, "["):
+		if json.Valid([]byte(t)) {
+			return languageInfo("json", 0.8), true
+		}
+		// the sample may be cut off mid-document
+		return languageInfo("json", 0.4), true
+	case strings.HasPrefix(t, "---\n") || strings.HasPrefix(t, "---\r\n") || strings.HasPrefix(t, "%YAML"):
+		return languageInfo("yaml", 0.5), true
+	case strings.HasPrefix(t, "package ") && strings.Contains(t, "\n"):
+		return languageInfo("go", 0.4), true
+	}
+	return LanguageInfo{}, false
+}
+
+// DetectLanguage guesses the language of a file from its path and a sample
+// of its content. Files that cannot be placed are plaintext.
+func (a *App) DetectLanguage(path string, contentSample string) LanguageInfo {
+	return detectLanguage(path, contentSample)
+}
+
+// ListSupportedLanguages returns every language the editor can be switched to
+func (a *App) ListSupportedLanguages() []Language {
+	return append([]Language{}, languages...)
+}
+
+// SetDocumentLanguage overrides the detected language of a document
+func (a *App) SetDocumentLanguage(docID string, lang string) error {
+	if _, ok := languageByID(lang); !ok {
+		return fmt.Errorf("unsupported language %q", lang)
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	d, err := a.docLocked(docID)
+	if err != nil {
+		return err
+	}
+	d.Language = lang
+	return nil
+}
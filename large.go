@@ -0,0 +1,439 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// ErrLargeFile is returned when editing, or otherwise needing the whole
+// content of, a document opened in large-file mode
+var ErrLargeFile = errors.New("large files are opened read-only")
+
+const (
+	// defaultLargeFileMB is the size above which files open in large-file mode
+	defaultLargeFileMB = 20
+	// maxChunkLines caps how many lines one ReadChunk call returns
+	maxChunkLines = 10000
+	// largeSampleLen is how much of a large file is read up front to guess
+	// its encoding, language and line count
+	largeSampleLen = 64 << 10
+	// indexFlushLines is how many lines are indexed between flushes of the
+	// index file, which is when waiting readers are woken
+	indexFlushLines = 1 << 16
+)
+
+// IndexProgress is sent as a largefile:progress event while the line index
+// of a large document is built
+type IndexProgress struct {
+	ID    string `json:"id"`
+	Bytes int64  `json:"bytes"`
+	Total int64  `json:"total"`
+	Lines int    `json:"lines"`
+	Done  bool   `json:"done"`
+	Error string `json:"error,omitempty"`
+}
+
+// lineIndex records where each line of a large file starts. The offsets
+// are kept on disk as little-endian uint64s so huge files cost no memory.
+type lineIndex struct {
+	path   string
+	file   *os.File
+	cancel context.CancelFunc
+
+	mu    sync.Mutex
+	cond  *sync.Cond
+	lines int   // line offsets written and flushed so far
+	end   int64 // size of the file once indexing is done
+	done  bool
+	err   error
+}
+
+// openLargeDocument opens path in large-file mode. Only a sample is read
+// now; the line index is built in the background.
+func (a *App) openLargeDocument(path string, size int64) (Document, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Document{}, newFileError(path, err)
+	}
+	sample := make([]byte, largeSampleLen)
+	n, err := io.ReadFull(f, sample)
+	f.Close()
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return Document{}, newFileError(path, err)
+	}
+	sample = sample[:n]
+	if bytes.HasPrefix(sample, bomUTF16LE) || bytes.HasPrefix(sample, bomUTF16BE) {
+		return Document{}, &FileError{Code: "too_large", Message: fmt.Sprintf("%s is too large to open as UTF-16", path)}
+	}
+	if looksBinary(sample) {
+		return Document{}, &FileError{Code: "binary", Message: fmt.Sprintf("%s looks like a binary file and cannot be opened as text", path)}
+	}
+	// don't let a rune cut in half at the end pass for Latin-1
+	if i := bytes.LastIndexByte(sample, '\n'); i >= 0 {
+		sample = sample[:i+1]
+	}
+	text, enc, _ := decodeText(sample)
+
+	a.mu.Lock()
+	d := a.newDocumentLocked()
+	d.Path = path
+	d.Encoding = enc
+	d.LineEnding = detectLineEnding(text)
+	d.Language = detectLanguage(path, languageSample(text)).ID
+	d.LargeFile = true
+	d.Size = size
+	d.Lines = estimateLines(sample, size)
+	d.ReadOnly = !writable(path)
+	a.indexLocked(d)
+	a.watchLocked(d)
+	doc := *d
+	a.mu.Unlock()
+	a.log.Info("opened as a large file", "path", path, "bytes", size, "encoding", enc)
+
+	a.addRecent(path)
+	return doc, nil
+}
+
+// estimateLines extrapolates the line count of a file from a sample of it
+func estimateLines(sample []byte, size int64) int {
+	if len(sample) == 0 {
+		return 1
+	}
+	newlines := int64(bytes.Count(sample, []byte{'\n'}))
+	return int(newlines*size/int64(len(sample))) + 1
+}
+
+// indexLocked starts building the line index of a large document,
+// replacing any previous one. a.mu must be held.
+func (a *App) indexLocked(d *Document) {
+	d.index.close()
+
+	idx := &lineIndex{path: d.Path}
+	idx.cond = sync.NewCond(&idx.mu)
+	d.index = idx
+
+	parent := a.bg
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithCancel(parent)
+	idx.cancel = cancel
+
+	id, total := d.ID, d.Size
+	skip := int64(0)
+	if d.Encoding == EncodingUTF8BOM {
+		skip = int64(len(bomUTF8))
+	}
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		last := time.Now()
+		err := idx.build(ctx, skip, func(read int64, lines int) {
+			if time.Since(last) < 250*time.Millisecond {
+				return
+			}
+			last = time.Now()
+			runtime.EventsEmit(a.ctx, "largefile:progress", IndexProgress{ID: id, Bytes: read, Total: total, Lines: lines})
+		})
+		if errors.Is(err, context.Canceled) {
+			return
+		}
+
+		idx.mu.Lock()
+		p := IndexProgress{ID: id, Bytes: idx.end, Total: idx.end, Lines: idx.lines, Done: true}
+		idx.mu.Unlock()
+		if err != nil {
+			p.Error = err.Error()
+		}
+		a.mu.Lock()
+		if d, ok := a.docs[id]; ok && d.index == idx && err == nil {
+			d.Lines = p.Lines
+			d.Size = p.Total
+		}
+		a.mu.Unlock()
+		runtime.EventsEmit(a.ctx, "largefile:progress", p)
+	}()
+}
+
+// build scans the file for line starts, the first of which is at skip
+func (idx *lineIndex) build(ctx context.Context, skip int64, progress func(read int64, lines int)) (err error) {
+	defer func() {
+		idx.mu.Lock()
+		idx.done, idx.err = true, err
+		idx.cond.Broadcast()
+		idx.mu.Unlock()
+	}()
+
+	src, err := os.Open(idx.path)
+	if err != nil {
+		return newFileError(idx.path, err)
+	}
+	defer src.Close()
+	idx.file, err = os.CreateTemp("", "wailspad-index-*")
+	if err != nil {
+		return err
+	}
+	if _, err := src.Seek(skip, io.SeekStart); err != nil {
+		return err
+	}
+
+	w := bufio.NewWriterSize(idx.file, 64<<10)
+	var rec [8]byte
+	put := func(off int64) error {
+		binary.LittleEndian.PutUint64(rec[:], uint64(off))
+		_, err := w.Write(rec[:])
+		return err
+	}
+	flush := func(lines int) error {
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		idx.mu.Lock()
+		idx.lines = lines
+		idx.cond.Broadcast()
+		idx.mu.Unlock()
+		return nil
+	}
+
+	if err := put(skip); err != nil {
+		return err
+	}
+	lines, pos := 1, skip
+	buf := make([]byte, 1<<20)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		n, rerr := src.Read(buf)
+		chunk := buf[:n]
+		for base := 0; ; {
+			i := bytes.IndexByte(chunk[base:], '\n')
+			if i < 0 {
+				break
+			}
+			base += i + 1
+			if err := put(pos + int64(base)); err != nil {
+				return err
+			}
+			lines++
+			if lines%indexFlushLines == 0 {
+				if err := flush(lines); err != nil {
+					return err
+				}
+			}
+		}
+		pos += int64(n)
+		progress(pos, lines)
+		if rerr == io.EOF {
+			break
+		}
+		if rerr != nil {
+			return newFileError(idx.path, rerr)
+		}
+	}
+	if err := flush(lines); err != nil {
+		return err
+	}
+	idx.mu.Lock()
+	idx.end = pos
+	idx.mu.Unlock()
+	return nil
+}
+
+// offsets waits until the lines from first up to and including last (both
+// 0-based) are indexed and returns their start offsets. last may be one past
+// the final line, in which case the end of the file stands in for it.
+func (idx *lineIndex) offsets(first, last int) ([]int64, error) {
+	idx.mu.Lock()
+	for !idx.done && idx.lines <= last {
+		idx.cond.Wait()
+	}
+	lines, end, err := idx.lines, idx.end, idx.err
+	idx.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if first >= lines {
+		return nil, nil
+	}
+
+	have := min(last, lines-1)
+	raw := make([]byte, (have-first+1)*8)
+	if _, err := idx.file.ReadAt(raw, int64(first)*8); err != nil {
+		return nil, err
+	}
+	offs := make([]int64, 0, last-first+1)
+	for i := 0; i < len(raw); i += 8 {
+		offs = append(offs, int64(binary.LittleEndian.Uint64(raw[i:])))
+	}
+	if have < last {
+		offs = append(offs, end)
+	}
+	return offs, nil
+}
+
+// close stops the index build and deletes the index file
+func (idx *lineIndex) close() {
+	if idx == nil {
+		return
+	}
+	idx.cancel()
+	// wait for build to let go of the file
+	idx.mu.Lock()
+	for !idx.done {
+		idx.cond.Wait()
+	}
+	idx.mu.Unlock()
+	if idx.file != nil {
+		idx.file.Close()
+		os.Remove(idx.file.Name())
+	}
+}
+
+// ReadChunk returns lineCount lines of a large document starting at the
+// 1-based startLine, without line terminators. It waits for the line index
+// to reach them when they have not been indexed yet.
+func (a *App) ReadChunk(docID string, startLine, lineCount int) ([]string, error) {
+	a.mu.Lock()
+	d, err := a.docLocked(docID)
+	if err != nil {
+		a.mu.Unlock()
+		return nil, err
+	}
+	idx, path, enc, large := d.index, d.Path, d.Encoding, d.LargeFile
+	a.mu.Unlock()
+
+	if !large {
+		return nil, fmt.Errorf("%s is not open in large-file mode", d.Name())
+	}
+	if startLine < 1 || lineCount < 0 {
+		return nil, fmt.Errorf("invalid line range %d+%d", startLine, lineCount)
+	}
+	lineCount = min(lineCount, maxChunkLines)
+	if lineCount == 0 {
+		return []string{}, nil
+	}
+
+	// one extra offset marks where the last line ends
+	offs, err := idx.offsets(startLine-1, startLine-1+lineCount)
+	if err != nil {
+		return nil, err
+	}
+	if len(offs) < 2 {
+		return []string{}, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, newFileError(path, err)
+	}
+	defer f.Close()
+	data := make([]byte, offs[len(offs)-1]-offs[0])
+	if _, err := f.ReadAt(data, offs[0]); err != nil && err != io.EOF {
+		return nil, newFileError(path, err)
+	}
+
+	lines := make([]string, 0, len(offs)-1)
+	for i := 1; i < len(offs); i++ {
+		line := data[offs[i-1]-offs[0] : offs[i]-offs[0]]
+		lines = append(lines, decodeLine(line, enc))
+	}
+	return lines, nil
+}
+
+// decodeLine turns one raw line of a large file into text, dropping its
+// terminator
+func decodeLine(line []byte, enc string) string {
+	line = bytes.TrimSuffix(line, []byte{'\n'})
+	line = bytes.TrimSuffix(line, []byte{'\r'})
+	if enc == EncodingLatin1 {
+		return decodeLatin1(line)
+	}
+	return strings.ToValidUTF8(string(line), "\uFFFD")
+}
+
+// findLarge searches a large document a block of whole lines at a time.
+// Blocks with no match are skipped without looking at their lines, and
+// matches cannot span lines. A limit of 0 only counts the matches.
+func findLarge(m *matcher, path string, enc string, limit int) (FindResult, error) {
+	res := FindResult{Matches: []Match{}}
+	f, err := os.Open(path)
+	if err != nil {
+		return res, newFileError(path, err)
+	}
+	defer f.Close()
+	r := bufio.NewReaderSize(f, 1<<20)
+	if enc == EncodingUTF8BOM {
+		r.Discard(len(bomUTF8))
+	}
+
+	// Latin-1 bytes only mean the same as UTF-8 for ASCII, so those files
+	// are decoded before matching. An anchored pattern sees a line start or
+	// end in the middle of a block, or the line break, differently from the
+	// decoded line, so it is not prefiltered either.
+	quick := func(raw []byte) bool {
+		return enc == EncodingLatin1 || m.anchored || m.re.Match(raw)
+	}
+	n := 1
+	var pending []byte
+	block := make([]byte, 1<<20)
+	for {
+		read, rerr := io.ReadFull(r, block)
+		eof := rerr == io.EOF || rerr == io.ErrUnexpectedEOF
+		if rerr != nil && !eof {
+			return res, newFileError(path, rerr)
+		}
+		data := append(pending, block[:read]...)
+		cut := len(data)
+		if !eof {
+			// a line longer than the block keeps accumulating
+			cut = bytes.LastIndexByte(data, '\n') + 1
+		}
+		whole := data[:cut]
+		pending = append([]byte(nil), data[cut:]...)
+
+		if len(whole) > 0 && quick(whole) {
+			for len(whole) > 0 {
+				raw := whole
+				if i := bytes.IndexByte(whole, '\n'); i >= 0 {
+					raw = whole[:i+1]
+				}
+				whole = whole[len(raw):]
+				switch {
+				case !quick(raw):
+				case limit == 0:
+					res.Total += m.count(decodeLine(raw, enc))
+				default:
+					found := m.find(decodeLine(raw, enc), limit-len(res.Matches))
+					res.Total += found.Total
+					for _, match := range found.Matches {
+						match.Line = n
+						res.Matches = append(res.Matches, match)
+					}
+					if found.Truncated {
+						res.Truncated = true
+						return res, nil
+					}
+				}
+				if raw[len(raw)-1] == '\n' {
+					n++
+				}
+			}
+		} else {
+			n += bytes.Count(whole, []byte{'\n'})
+		}
+		if eof {
+			return res, nil
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFindLargeAnchored(t *testing.T) {
+	// about 2.5MB, so the match sits in the middle of the second 1MB block
+	const lines, at = 40000, 25000
+	var sb strings.Builder
+	for i := 1; i <= lines; i++ {
+		if i == at {
+			sb.WriteString("needle in the middle\r\n")
+			continue
+		}
+		fmt.Fprintf(&sb, "line %05d with some filler text around it\r\n", i)
+	}
+	path := filepath.Join(t.TempDir(), "big.txt")
+	if err := os.WriteFile(path, []byte(sb.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, query := range []string{"needle", "^needle", `\Aneedle`, "(?m)^needle", "middle$", `middle\z`, "^needle in the middle$"} {
+		m, err := newMatcher(query, FindOptions{Regex: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := findLarge(m, path, EncodingUTF8, maxMatches)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Matches) != 1 || res.Matches[0].Line != at || res.Total != 1 {
+			t.Errorf("%s: findLarge = %+v, want one match on line %d", query, res, at)
+		}
+		if res, _ := findLarge(m, path, EncodingUTF8, 0); res.Total != 1 {
+			t.Errorf("%s: counting found %d matches, want 1", query, res.Total)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// Line ending styles
+const (
+	LineEndingLF    = "LF"
+	LineEndingCRLF  = "CRLF"
+	LineEndingCR    = "CR"
+	LineEndingMixed = "Mixed"
+)
+
+// defaultLineEnding is used for new documents and files without line breaks
+func defaultLineEnding() string {
+	if runtime.GOOS == "windows" {
+		return LineEndingCRLF
+	}
+	return LineEndingLF
+}
+
+// detectLineEnding reports which line ending text uses, or Mixed when it
+// has more than one kind
+func detectLineEnding(text string) string {
+	var lf, crlf, cr int
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '\r':
+			if i+1 < len(text) && text[i+1] == '\n' {
+				crlf++
+				i++
+			} else {
+				cr++
+			}
+		case '\n':
+			lf++
+		}
+	}
+	switch {
+	case lf == 0 && crlf == 0 && cr == 0:
+		return defaultLineEnding()
+	case crlf == 0 && cr == 0:
+		return LineEndingLF
+	case lf == 0 && cr == 0:
+		return LineEndingCRLF
+	case lf == 0 && crlf == 0:
+		return LineEndingCR
+	default:
+		return LineEndingMixed
+	}
+}
+
+// convertLineEndings rewrites every line break in text as le. Breaks are
+// normalised first so CRLF is never doubled up into CRCRLF.
+func convertLineEndings(text string, le string) string {
+	var sep string
+	switch le {
+	case LineEndingLF:
+		sep = "\n"
+	case LineEndingCRLF:
+		sep = "\r\n"
+	case LineEndingCR:
+		sep = "\r"
+	default:
+		return text
+	}
+	if !strings.ContainsRune(text, '\r') && sep == "\n" {
+		return text
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(text) + len(text)/32)
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '\r':
+			if i+1 < len(text) && text[i+1] == '\n' {
+				i++
+			}
+			sb.WriteString(sep)
+		case '\n':
+			sb.WriteString(sep)
+		default:
+			sb.WriteByte(text[i])
+		}
+	}
+	return sb.String()
+}
+
+// validLineEnding reports whether le can be applied with convertLineEndings
+func validLineEnding(le string) error {
+	switch le {
+	case LineEndingLF, LineEndingCRLF, LineEndingCR:
+		return nil
+	}
+	return fmt.Errorf("unsupported line ending %q, expected LF, CRLF or CR", le)
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/logger"
+)
+
+const (
+	// logMaxSize is the size at which the log file is rotated
+	logMaxSize = 1 << 20
+	// logKeep is the number of log files kept, the current one included
+	logKeep = 3
+	// logRecent is the number of lines GetRecentLogs can return
+	logRecent = 1000
+	// logQueue is the number of lines waiting to be written before more
+	// are dropped rather than holding up the caller
+	logQueue = 4096
+)
+
+// logLevels are the values SetLogLevel accepts
+var logLevels = map[string]slog.Level{
+	"debug": slog.LevelDebug,
+	"info":  slog.LevelInfo,
+	"warn":  slog.LevelWarn,
+	"error": slog.LevelError,
+}
+
+// logWriter keeps the latest log lines in memory and writes them to the
+// rotated log file from a goroutine of its own, so logging never waits on
+// the disk
+type logWriter struct {
+	mu      sync.Mutex
+	recent  []string // ring of the latest lines
+	next    int      // where the next line goes in recent
+	queue   chan []byte
+	closed  bool
+	dropped int // lines lost to a full queue, reported once there is room
+	done    chan struct{}
+}
+
+// newLogger starts the log writer and returns a logger writing through it,
+// along with the level it logs from
+func newLogger() (*slog.Logger, *slog.LevelVar, *logWriter) {
+	w := &logWriter{
+		recent: make([]string, 0, logRecent),
+		queue:  make(chan []byte, logQueue),
+		done:   make(chan struct{}),
+	}
+	go w.run()
+	level := new(slog.LevelVar)
+	return slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: level})), level, w
+}
+
+// GetRecentLogs returns up to the last lines log lines, oldest first
+func (a *App) GetRecentLogs(lines int) []string {
+	return a.logs.tail(lines)
+}
+
+// SetLogLevel changes the level logged from: debug, info, warn or error
+func (a *App) SetLogLevel(level string) error {
+	l, ok := logLevels[strings.ToLower(level)]
+	if !ok {
+		return fmt.Errorf("unknown log level %q, expected debug, info, warn or error", level)
+	}
+	a.logLevel.Set(l)
+	a.log.Info("log level changed", "level", l.String())
+	return nil
+}
+
+// Write queues one log line. It never blocks: when the file cannot keep up
+// lines are dropped from it, though they still reach GetRecentLogs.
+func (w *logWriter) Write(p []byte) (int, error) {
+	line := append([]byte{}, p...)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.recent) < logRecent {
+		w.recent = append(w.recent, strings.TrimSuffix(string(line), "\n"))
+	} else {
+		w.recent[w.next] = strings.TrimSuffix(string(line), "\n")
+	}
+	w.next = (w.next + 1) % logRecent
+	if w.closed {
+		return len(p), nil
+	}
+	if w.dropped > 0 {
+		select {
+		case w.queue <- []byte(fmt.Sprintf("time=%s level=WARN msg=\"%d log lines dropped\"\n", time.Now().Format(time.RFC3339Nano), w.dropped)):
+			w.dropped = 0
+		default:
+		}
+	}
+	select {
+	case w.queue <- line:
+	default:
+		w.dropped++
+	}
+	return len(p), nil
+}
+
+// tail returns up to n of the latest lines, oldest first
+func (w *logWriter) tail(n int) []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if n <= 0 || n > len(w.recent) {
+		n = len(w.recent)
+	}
+	lines := make([]string, 0, n)
+	start := (w.next - n + len(w.recent)) % max(len(w.recent), 1)
+	for i := range n {
+		lines = append(lines, w.recent[(start+i)%len(w.recent)])
+	}
+	return lines
+}
+
+// close writes out the queued lines and stops the writer
+func (w *logWriter) close() {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return
+	}
+	w.closed = true
+	close(w.queue)
+	w.mu.Unlock()
+	<-w.done
+}
+
+// run writes queued lines to the log file, flushing whenever the queue
+// runs dry. The file is opened on the first line, so a run that logs
+// nothing leaves no file.
+func (w *logWriter) run() {
+	defer close(w.done)
+	var f *os.File
+	var buf *bufio.Writer
+	var size int64
+	defer func() {
+		if f != nil {
+			buf.Flush()
+			f.Close()
+		}
+	}()
+	for line := range w.queue {
+		if f == nil || size+int64(len(line)) > logMaxSize {
+			if f != nil {
+				buf.Flush()
+				f.Close()
+				f = nil
+				rotateLogs()
+			}
+			var err error
+			if f, size, err = openLog(); err != nil {
+				// nowhere to write, the lines stay in memory only
+				continue
+			}
+			buf = bufio.NewWriter(f)
+		}
+		n, _ := buf.Write(line)
+		size += int64(n)
+		if len(w.queue) == 0 {
+			buf.Flush()
+		}
+	}
+}
+
+// logPath returns the path of the nth log file, 0 being the current one
+func logPath(n int) (string, error) {
+	name := "wailspad.log"
+	if n > 0 {
+		name = fmt.Sprintf("wailspad.log.%d", n)
+	}
+	return configPath("logs", name)
+}
+
+// openLog opens the current log file for appending and returns its size
+func openLog() (*os.File, int64, error) {
+	path, err := logPath(0)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, 0, err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, 0, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, 0, err
+	}
+	return f, info.Size(), nil
+}
+
+// rotateLogs shifts wailspad.log to wailspad.log.1 and so on, dropping the
+// oldest
+func rotateLogs() {
+	for n := logKeep - 1; n > 0; n-- {
+		from, err := logPath(n - 1)
+		if err != nil {
+			return
+		}
+		to, _ := logPath(n)
+		os.Rename(from, to)
+	}
+}
+
+// wailsLogger passes the messages of the Wails runtime to our log
+type wailsLogger struct {
+	log  *slog.Logger
+	logs *logWriter
+}
+
+var _ logger.Logger = wailsLogger{}
+
+func (l wailsLogger) Print(message string)   { l.log.Info(message, "source", "wails") }
+func (l wailsLogger) Trace(message string)   { l.log.Debug(message, "source", "wails") }
+func (l wailsLogger) Debug(message string)   { l.log.Debug(message, "source", "wails") }
+func (l wailsLogger) Info(message string)    { l.log.Info(message, "source", "wails") }
+func (l wailsLogger) Warning(message string) { l.log.Warn(message, "source", "wails") }
+func (l wailsLogger) Error(message string)   { l.log.Error(message, "source", "wails") }
+
+// Fatal logs message and exits, as the Wails logger does
+func (l wailsLogger) Fatal(message string) {
+	l.log.Error(message, "source", "wails", "fatal", true)
+	l.logs.close()
+	os.Exit(1)
+}
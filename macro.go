@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// maxMacroRuns caps the repeat count of PlayMacro, so a typo in the count
+// cannot flood the editor for minutes
+const maxMacroRuns = 10000
+
+// maxMacroActions caps how many actions one recording keeps
+const maxMacroActions = 100000
+
+// EditorAction is one editing step reported by the frontend while a macro
+// is recorded, such as typing text or running a command. Its meaning is up
+// to the editor, which gets it back unchanged on playback.
+type EditorAction struct {
+	Type string         `json:"type"`
+	Text string         `json:"text,omitempty"`
+	Data map[string]any `json:"data,omitempty"`
+}
+
+// Macro is a recorded list of actions. Recordings get an ID of the form
+// "macro-N" and saved macros use their name as ID.
+type Macro struct {
+	ID      string         `json:"id"`
+	Name    string         `json:"name,omitempty"`
+	Actions []EditorAction `json:"actions"`
+}
+
+// MacroPlayback is sent as a macro:done event when playback stops
+type MacroPlayback struct {
+	MacroID   string `json:"macroId"`
+	Runs      int    `json:"runs"` // complete runs played
+	Cancelled bool   `json:"cancelled"`
+}
+
+// StartMacroRecording begins collecting the actions passed to RecordAction
+func (a *App) StartMacroRecording() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.recording != nil {
+		return errors.New("a macro is already being recorded")
+	}
+	a.recording = []EditorAction{}
+	return nil
+}
+
+// RecordAction adds an action to the macro being recorded. Actions
+// reported while nothing is recorded are ignored.
+func (a *App) RecordAction(action EditorAction) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.recording == nil || action.Type == "" || len(a.recording) >= maxMacroActions {
+		return
+	}
+	a.recording = append(a.recording, action)
+}
+
+// StopMacroRecording ends the recording and returns it. It can be played by
+// ID straight away and kept with SaveMacro.
+func (a *App) StopMacroRecording() Macro {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.recording == nil {
+		return Macro{Actions: []EditorAction{}}
+	}
+	if a.recorded == nil {
+		a.recorded = make(map[string]Macro)
+	}
+	a.macroSeq++
+	m := Macro{ID: "macro-" + strconv.Itoa(a.macroSeq), Actions: a.recording}
+	a.recorded[m.ID] = m
+	a.recording = nil
+	return m
+}
+
+// PlayMacro replays a macro times times in the background, sending each
+// action as a macro:action event and a macro:done event at the end. Only
+// one macro plays at a time.
+func (a *App) PlayMacro(macroID string, times int) error {
+	if times < 1 || times > maxMacroRuns {
+		return fmt.Errorf("a macro can be played 1 to %d times", maxMacroRuns)
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.macroCancel != nil {
+		return errors.New("a macro is already playing")
+	}
+	m, ok := a.recorded[macroID]
+	if !ok {
+		macros, err := loadMacros()
+		if err != nil {
+			return err
+		}
+		i := findMacro(macros, macroID)
+		if i < 0 {
+			return fmt.Errorf("no macro %q", macroID)
+		}
+		m = macros[i]
+	}
+
+	parent := a.bg
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithCancel(parent)
+	a.macroCancel = cancel
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		done := MacroPlayback{MacroID: macroID}
+	play:
+		for ; done.Runs < times; done.Runs++ {
+			for _, action := range m.Actions {
+				if ctx.Err() != nil {
+					break play
+				}
+				runtime.EventsEmit(a.ctx, "macro:action", action)
+			}
+		}
+		done.Cancelled = ctx.Err() != nil
+
+		a.mu.Lock()
+		a.macroCancel = nil
+		a.mu.Unlock()
+		cancel()
+		runtime.EventsEmit(a.ctx, "macro:done", done)
+	}()
+	return nil
+}
+
+// CancelMacro stops the macro that is playing. Its macro:done event still
+// fires.
+func (a *App) CancelMacro() {
+	a.mu.Lock()
+	cancel := a.macroCancel
+	a.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+}
+
+// SaveMacro stores macro under name, replacing any macro of that name
+func (a *App) SaveMacro(name string, macro Macro) (Macro, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return Macro{}, errors.New("a macro needs a name")
+	}
+	if len(macro.Actions) == 0 {
+		return Macro{}, errors.New("the macro has no actions")
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	macros, err := loadMacros()
+	if err != nil {
+		return Macro{}, err
+	}
+	m := Macro{ID: name, Name: name, Actions: macro.Actions}
+	if i := findMacro(macros, name); i >= 0 {
+		macros[i] = m
+	} else {
+		macros = append(macros, m)
+	}
+	if err := saveMacros(macros); err != nil {
+		return Macro{}, err
+	}
+	return m, nil
+}
+
+// ListMacros returns the saved macros by name
+func (a *App) ListMacros() []Macro {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	macros, err := loadMacros()
+	if err != nil {
+		return []Macro{}
+	}
+	sort.Slice(macros, func(i, j int) bool {
+		return strings.ToLower(macros[i].Name) < strings.ToLower(macros[j].Name)
+	})
+	return macros
+}
+
+// DeleteMacro removes the saved macro called name
+func (a *App) DeleteMacro(name string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	macros, err := loadMacros()
+	if err != nil {
+		return err
+	}
+	i := findMacro(macros, name)
+	if i < 0 {
+		return fmt.Errorf("no macro %q", name)
+	}
+	return saveMacros(append(macros[:i], macros[i+1:]...))
+}
+
+// findMacro returns the index of the macro called name, or -1
+func findMacro(macros []Macro, name string) int {
+	for i, m := range macros {
+		if m.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// loadMacros reads the saved macros. A missing file is no macros.
+func loadMacros() ([]Macro, error) {
+	path, err := configPath("macros.json")
+	if err != nil {
+		return nil, err
+	}
+	macros := []Macro{}
+	if err := readJSON(path, &macros); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	return macros, nil
+}
+
+// saveMacros persists macros
+func saveMacros(macros []Macro) error {
+	path, err := configPath("macros.json")
+	if err != nil {
+		return err
+	}
+	return writeJSON(path, macros, 0o644)
+}
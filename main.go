@@ -2,8 +2,10 @@ package main
 
 import (
 	"embed"
+	"os"
 
 	"github.com/wailsapp/wails/v2"
+	"github.com/wailsapp/wails/v2/pkg/logger"
 	"github.com/wailsapp/wails/v2/pkg/options"
 	"github.com/wailsapp/wails/v2/pkg/options/assetserver"
 )
@@ -12,6 +14,10 @@ import (
 var assets embed.FS
 
 func main() {
+	if len(os.Args) == 4 && os.Args[1] == elevatedWriteFlag {
+		os.Exit(elevatedWrite(os.Args[2], os.Args[3]))
+	}
+
 	// Create an instance of the app structure
 	app := NewApp()
 
@@ -27,7 +33,22 @@ func main() {
 			Assets: assets,
 		},
 		BackgroundColour: &options.RGBA{R: 27, G: 38, B: 54, A: 1},
-		OnStartup:        app.startup,
+		Menu:             app.buildMenu(),
+		DragAndDrop: &options.DragAndDrop{
+			EnableFileDrop:     true,
+			DisableWebViewDrop: true,
+		},
+		SingleInstanceLock: &options.SingleInstanceLock{
+			UniqueId:               instanceID,
+			OnSecondInstanceLaunch: app.onSecondInstanceLaunch,
+		},
+		// everything is handed to our logger, which applies its own level
+		Logger:             wailsLogger{log: app.log, logs: app.logs},
+		LogLevel:           logger.TRACE,
+		LogLevelProduction: logger.TRACE,
+		OnStartup:          app.startup,
+		OnBeforeClose:      app.beforeClose,
+		OnShutdown:         app.shutdown,
 		Bind: []interface{}{
 			app,
 		},
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"fmt"
+	goruntime "runtime"
+
+	"github.com/wailsapp/wails/v2/pkg/menu"
+	"github.com/wailsapp/wails/v2/pkg/menu/keys"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// buildMenu creates the application menu from the current settings and
+// recent files. Commands that need the editor's content are passed on to
+// the frontend as menu:* events.
+func (a *App) buildMenu() *menu.Menu {
+	a.mu.Lock()
+	wrap := a.settings.WordWrap
+	onTop, opacity := a.settings.AlwaysOnTop, a.settings.Opacity
+	recent := append([]string{}, a.recent...)
+	a.mu.Unlock()
+
+	mac := goruntime.GOOS == "darwin"
+	emit := func(event string, data ...any) menu.Callback {
+		return func(*menu.CallbackData) {
+			runtime.EventsEmit(a.ctx, event, data...)
+		}
+	}
+
+	m := menu.NewMenu()
+	if mac {
+		m.Append(menu.AppMenu())
+	}
+
+	file := m.AddSubmenu("File")
+	file.AddText("New", keys.CmdOrCtrl("n"), func(*menu.CallbackData) {
+		runtime.EventsEmit(a.ctx, "file:opened", a.NewDocument())
+	})
+	file.AddText("Open...", keys.CmdOrCtrl("o"), func(*menu.CallbackData) {
+		res := a.OpenFile()
+		if res.Error != nil {
+			a.showError("Open Failed", res.Error.Message)
+		} else if res.ID != "" {
+			a.announceDocument(res.ID)
+		}
+	})
+	openRecent := file.AddSubmenu("Open Recent")
+	if len(recent) == 0 {
+		openRecent.AddText("No recent files", nil, nil).Disable()
+	}
+	for _, path := range recent {
+		openRecent.AddText(path, nil, func(*menu.CallbackData) {
+			a.openAndShow(path)
+		})
+	}
+	if len(recent) > 0 {
+		openRecent.AddSeparator()
+		openRecent.AddText("Clear Recent", nil, func(*menu.CallbackData) {
+			a.ClearRecentFiles()
+		})
+	}
+	file.AddSeparator()
+	file.AddText("Save", keys.CmdOrCtrl("s"), emit("menu:save"))
+	file.AddText("Save As...", keys.Combo("s", keys.CmdOrCtrlKey, keys.ShiftKey), emit("menu:save-as"))
+	if !mac {
+		// the app menu already has Quit on macOS
+		file.AddSeparator()
+		file.AddText("Exit", nil, func(*menu.CallbackData) {
+			a.quit()
+		})
+	}
+
+	redo := keys.CmdOrCtrl("y")
+	replace := keys.CmdOrCtrl("h")
+	if mac {
+		redo = keys.Combo("z", keys.CmdOrCtrlKey, keys.ShiftKey)
+		replace = keys.Combo("f", keys.CmdOrCtrlKey, keys.OptionOrAltKey)
+	}
+	find := m.AddSubmenu("Edit")
+	if mac {
+		// the standard Edit menu carries the clipboard commands the web
+		// view relies on, so search gets a menu of its own
+		m.Append(menu.EditMenu())
+		find = m.AddSubmenu("Find")
+	} else {
+		find.AddText("Undo", keys.CmdOrCtrl("z"), emit("menu:undo"))
+		find.AddText("Redo", redo, emit("menu:redo"))
+		find.AddSeparator()
+	}
+	find.AddText("Find", keys.CmdOrCtrl("f"), emit("menu:find"))
+	find.AddText("Replace", replace, emit("menu:replace"))
+
+	view := m.AddSubmenu("View")
+	view.AddCheckbox("Word Wrap", wrap, keys.OptionOrAlt("z"), func(cd *menu.CallbackData) {
+		a.menuSetting("wordWrap", cd.MenuItem.Checked)
+	})
+	view.AddSeparator()
+	fullscreen := keys.Key("F11")
+	if mac {
+		fullscreen = keys.Combo("f", keys.CmdOrCtrlKey, keys.ControlKey)
+	}
+	view.AddCheckbox("Always on Top", onTop, nil, func(cd *menu.CallbackData) {
+		a.menuSetting("alwaysOnTop", cd.MenuItem.Checked)
+	})
+	view.AddCheckbox("Full Screen", a.ctx != nil && runtime.WindowIsFullscreen(a.ctx), fullscreen, func(cd *menu.CallbackData) {
+		cd.MenuItem.Checked = a.ToggleFullscreen()
+		runtime.MenuUpdateApplicationMenu(a.ctx)
+	})
+	if opacitySupported() {
+		transparency := view.AddSubmenu("Opacity")
+		for p := maxOpacity; p >= minOpacity; p -= 10 {
+			transparency.AddRadio(fmt.Sprintf("%d%%", p), p == opacity, nil, func(*menu.CallbackData) {
+				a.menuSetting("opacity", p)
+			})
+		}
+	}
+	view.AddSeparator()
+	zoom := func(level func() float64) menu.Callback {
+		return func(*menu.CallbackData) {
+			runtime.EventsEmit(a.ctx, "zoom:changed", level())
+		}
+	}
+	view.AddText("Zoom In", keys.CmdOrCtrl("="), zoom(func() float64 { return a.SetZoom(a.GetZoom() + zoomStep) }))
+	view.AddText("Zoom Out", keys.CmdOrCtrl("-"), zoom(func() float64 { return a.SetZoom(a.GetZoom() - zoomStep) }))
+	view.AddText("Reset Zoom", keys.CmdOrCtrl("0"), zoom(a.ResetZoom))
+
+	help := m.AddSubmenu("Help")
+	help.AddText("About wailspad", nil, func(*menu.CallbackData) {
+		runtime.MessageDialog(a.ctx, runtime.MessageDialogOptions{
+			Type:    runtime.InfoDialog,
+			Title:   "About wailspad",
+			Message: "wailspad is a plain text editor built with Wails.\nhttps://github.com/sean5446/wailspad",
+		})
+	})
+	return m
+}
+
+// RefreshMenu rebuilds the application menu so its checkboxes and recent
+// files match the current state
+func (a *App) RefreshMenu() {
+	if a.ctx == nil {
+		return
+	}
+	runtime.MenuSetApplicationMenu(a.ctx, a.buildMenu())
+	runtime.MenuUpdateApplicationMenu(a.ctx)
+}
+
+// menuSetting changes one setting from the menu and tells the frontend
+func (a *App) menuSetting(key string, value any) {
+	s, err := a.UpdateSettings(map[string]any{key: value})
+	if err != nil {
+		a.showError("Settings", err.Error())
+		return
+	}
+	runtime.EventsEmit(a.ctx, "settings:changed", s)
+}
+
+// openAndShow opens a file and brings the window up to show it
+func (a *App) openAndShow(path string) {
+	d, err := a.OpenDocument(path)
+	a.showWindow()
+	if err != nil {
+		a.showError("Open Failed", err.Error())
+		return
+	}
+	runtime.EventsEmit(a.ctx, "file:opened", d)
+}
+
+// announceDocument sends an open document to the frontend as file:opened
+func (a *App) announceDocument(id string) {
+	if d, err := a.GetDocument(id); err == nil {
+		runtime.EventsEmit(a.ctx, "file:opened", d)
+	}
+}
+
+func (a *App) showError(title, message string) {
+	a.log.Error(title, "err", message)
+	runtime.MessageDialog(a.ctx, runtime.MessageDialogOptions{
+		Type:    runtime.ErrorDialog,
+		Title:   title,
+		Message: message,
+	})
+}
@@ -0,0 +1,637 @@
+package main
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// OutlineNode is an entry of a document outline: a heading, a declaration
+// or a key, with what it contains as children
+type OutlineNode struct {
+	Label    string        `json:"label"`
+	Kind     string        `json:"kind"` // heading, function, method, class, struct, interface, type, enum or key
+	Line     int           `json:"line"` // 1-based
+	Children []OutlineNode `json:"children,omitempty"`
+}
+
+var (
+	// jsBinding is a const, let or var declaration, with what it is set to
+	jsBinding = regexp.MustCompile(`^(?:const|let|var)\s+([\p{L}_$][\p{L}\p{N}_$]*)\s*(?::[^=]*)?=\s*(.*)$`)
+	// jsField is a class field, with what it is set to
+	jsField = regexp.MustCompile(`^(#?[\p{L}_$][\p{L}\p{N}_$]*)\s*(?::[^=]*)?=\s*(.*)$`)
+	// jsArrow is the start of an arrow function
+	jsArrow = regexp.MustCompile(`^(?:async\s*)?(?:<[^>]*>\s*)?(?:\([^)]*\)|[\p{L}_$][\p{L}\p{N}_$]*)\s*(?::[^=]*)?=>`)
+)
+
+// jsModifiers come before the name of a declaration or class member
+var jsModifiers = []string{"export", "default", "declare", "async", "abstract", "static", "public", "private", "protected", "readonly", "override", "get", "set"}
+
+// jsKeywords look like calls at the start of a line but are not methods
+var jsKeywords = []string{"if", "for", "while", "switch", "catch", "function", "return", "with", "super"}
+
+// GetOutline lists the structure of content for the outline panel:
+// headings for Markdown, top-level functions, types and classes for Go,
+// JavaScript, TypeScript and Python, and top-level keys for JSON and
+// YAML. It reads the text line by line rather than parsing it, so content
+// that is broken, as it is halfway through typing, still gets an outline
+// of what can be made out. Other languages have no outline.
+func (a *App) GetOutline(content string, language string) []OutlineNode {
+	var outline []OutlineNode
+	switch strings.ToLower(language) {
+	case "markdown", "md":
+		outline = markdownOutline(outlineLines(content))
+	case "go":
+		outline = goOutline(outlineLines(content))
+	case "javascript", "js", "typescript", "ts":
+		outline = jsOutline(outlineLines(content))
+	case "python", "py":
+		outline = pythonOutline(outlineLines(content))
+	case "json":
+		outline = jsonOutline(content)
+	case "yaml", "yml":
+		outline = yamlOutline(outlineLines(content))
+	}
+	if outline == nil {
+		return []OutlineNode{}
+	}
+	return outline
+}
+
+// outlineLines splits content into lines without their line endings
+func outlineLines(content string) []string {
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines
+}
+
+// outlineHeading is a Markdown heading before headings are nested
+type outlineHeading struct {
+	level int
+	node  OutlineNode
+}
+
+// markdownOutline nests the ATX (# Title) and setext (Title over ===)
+// headings of a Markdown document by level, skipping code blocks and front
+// matter
+func markdownOutline(lines []string) []OutlineNode {
+	var headings []outlineHeading
+	start := 0
+	if len(lines) > 0 && lines[0] == "---" {
+		// YAML front matter, up to the line that closes it
+		for i := 1; i < len(lines); i++ {
+			if lines[i] == "---" || lines[i] == "..." {
+				start = i + 1
+				break
+			}
+		}
+	}
+	fence := "" // the ``` or ~~~ of the code block we are in
+	para := -1  // where the paragraph a setext underline would apply to starts
+	for i := start; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimLeft(line, " ")
+		indent := len(line) - len(trimmed)
+		if fence != "" {
+			if indent < 4 && strings.HasPrefix(trimmed, fence) && strings.TrimSpace(strings.TrimLeft(trimmed, fence[:1])) == "" {
+				fence = ""
+			}
+			continue
+		}
+		switch {
+		case strings.TrimSpace(line) == "":
+			para = -1
+		case indent >= 4 && para < 0:
+			// an indented code block
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			fence = trimmed[:len(trimmed)-len(strings.TrimLeft(trimmed, trimmed[:1]))]
+			para = -1
+		case trimmed[0] == '#':
+			level := len(trimmed) - len(strings.TrimLeft(trimmed, "#"))
+			rest := trimmed[level:]
+			if level > 6 || (rest != "" && rest[0] != ' ' && rest[0] != '\t') {
+				if para < 0 {
+					para = i
+				}
+				continue
+			}
+			if label := atxLabel(rest); label != "" {
+				headings = append(headings, outlineHeading{level, OutlineNode{Label: label, Kind: "heading", Line: i + 1}})
+			}
+			para = -1
+		case para >= 0 && indent < 4 && (strings.Trim(trimmed, "= ") == "" || strings.Trim(trimmed, "- ") == "") && !strings.Contains(strings.TrimSpace(trimmed), " "):
+			level := 1
+			if trimmed[0] == '-' {
+				level = 2
+			}
+			var label []string
+			for _, l := range lines[para:i] {
+				label = append(label, strings.TrimSpace(l))
+			}
+			headings = append(headings, outlineHeading{level, OutlineNode{Label: strings.Join(label, " "), Kind: "heading", Line: para + 1}})
+			para = -1
+		case isMarkdownBlock(trimmed):
+			// list items, quotes and rules are not headings even when
+			// underlined
+			para = -1
+		case para < 0:
+			para = i
+		}
+	}
+	return nestHeadings(headings)
+}
+
+// atxLabel returns the text of an ATX heading without its closing #s
+func atxLabel(rest string) string {
+	label := strings.TrimSpace(rest)
+	if trimmed := strings.TrimRight(label, "#"); trimmed == "" || strings.HasSuffix(trimmed, " ") || strings.HasSuffix(trimmed, "\t") {
+		label = strings.TrimSpace(trimmed)
+	}
+	return label
+}
+
+// isMarkdownBlock reports whether a line starts a list item, quote, table
+// row or rule rather than a paragraph
+func isMarkdownBlock(trimmed string) bool {
+	switch trimmed[0] {
+	case '-', '*', '+':
+		return len(trimmed) == 1 || trimmed[1] == ' ' || trimmed[1] == '\t' || strings.Trim(trimmed, trimmed[:1]+" ") == ""
+	case '>', '|', '<':
+		return true
+	}
+	digits := strings.TrimLeft(trimmed, "0123456789")
+	return len(digits) < len(trimmed) && (strings.HasPrefix(digits, ". ") || strings.HasPrefix(digits, ") "))
+}
+
+// nestHeadings makes each heading the parent of the deeper ones that follow
+// it
+func nestHeadings(headings []outlineHeading) []OutlineNode {
+	var nodes []OutlineNode
+	for i := 0; i < len(headings); {
+		j := i + 1
+		for j < len(headings) && headings[j].level > headings[i].level {
+			j++
+		}
+		node := headings[i].node
+		node.Children = nestHeadings(headings[i+1 : j])
+		nodes = append(nodes, node)
+		i = j
+	}
+	return nodes
+}
+
+// goOutline lists the functions, methods and types declared at the top
+// level of a Go file
+func goOutline(lines []string) []OutlineNode {
+	var nodes []OutlineNode
+	lit := literalTracker{comment: "//"}
+	group := false // inside a type ( ... ) block
+	groupIndent := ""
+	for i, line := range lines {
+		inside := lit.inside()
+		lit.scan(line)
+		if inside || line == "" {
+			continue
+		}
+		if group {
+			indent := leadingWhitespace(line)
+			switch {
+			case strings.HasPrefix(line, ")"):
+				group = false
+			case indent == "" || strings.HasPrefix(line[len(indent):], "//"):
+			case groupIndent == "" || indent == groupIndent:
+				groupIndent = indent
+				if node, ok := goType(line[len(indent):], i+1); ok {
+					nodes = append(nodes, node)
+				}
+			}
+			continue
+		}
+		if rest, ok := strings.CutPrefix(line, "func"); ok && (rest == "" || rest[0] == ' ' || rest[0] == '(') {
+			if node, ok := goFunc(strings.TrimSpace(rest), i+1); ok {
+				nodes = append(nodes, node)
+			}
+		} else if rest, ok := strings.CutPrefix(line, "type "); ok {
+			rest = strings.TrimSpace(rest)
+			if strings.HasPrefix(rest, "(") {
+				group, groupIndent = true, ""
+			} else if node, ok := goType(rest, i+1); ok {
+				nodes = append(nodes, node)
+			}
+		}
+	}
+	return nodes
+}
+
+// goFunc reads the declaration after func. A method is labelled with its
+// receiver the way a method expression names it, (*T).Name.
+func goFunc(rest string, line int) (OutlineNode, bool) {
+	receiver := ""
+	if strings.HasPrefix(rest, "(") {
+		end := strings.IndexByte(rest, ')')
+		if end < 0 {
+			return OutlineNode{}, false
+		}
+		fields := strings.Fields(rest[1:end])
+		if len(fields) == 0 {
+			return OutlineNode{}, false
+		}
+		receiver = fields[len(fields)-1]
+		if i := strings.IndexByte(receiver, '['); i >= 0 {
+			receiver = receiver[:i]
+		}
+		rest = strings.TrimSpace(rest[end+1:])
+	}
+	name := identPrefix(rest, "")
+	if name == "" {
+		return OutlineNode{}, false
+	}
+	switch {
+	case receiver == "":
+		return OutlineNode{Label: name, Kind: "function", Line: line}, true
+	case strings.HasPrefix(receiver, "*"):
+		return OutlineNode{Label: "(" + receiver + ")." + name, Kind: "method", Line: line}, true
+	default:
+		return OutlineNode{Label: receiver + "." + name, Kind: "method", Line: line}, true
+	}
+}
+
+// goType reads a type spec, Name followed by its type
+func goType(spec string, line int) (OutlineNode, bool) {
+	name := identPrefix(spec, "")
+	if name == "" {
+		return OutlineNode{}, false
+	}
+	rest := strings.TrimSpace(spec[len(name):])
+	if strings.HasPrefix(rest, "[") {
+		// type parameters, which unlike an array length name a constraint
+		if end := strings.IndexByte(rest, ']'); end > 0 && strings.ContainsAny(rest[:end], " ,") {
+			rest = strings.TrimSpace(rest[end+1:])
+		}
+	}
+	kind := "type"
+	switch {
+	case strings.HasPrefix(rest, "struct"):
+		kind = "struct"
+	case strings.HasPrefix(rest, "interface"):
+		kind = "interface"
+	}
+	return OutlineNode{Label: name, Kind: kind, Line: line}, true
+}
+
+// jsOutline lists the functions, classes and, for TypeScript, interfaces,
+// types and enums declared at the top level of a JavaScript or TypeScript
+// file, with the methods of each class. Only declarations starting a line
+// count, which is how nearly all code is formatted.
+func jsOutline(lines []string) []OutlineNode {
+	var nodes []OutlineNode
+	lit := literalTracker{comment: "//"}
+	class := false // inside the body of the last class
+	memberIndent := ""
+	for i, line := range lines {
+		inside := lit.inside()
+		lit.scan(line)
+		if inside || strings.TrimSpace(line) == "" {
+			continue
+		}
+		indent := leadingWhitespace(line)
+		code := line[len(indent):]
+		if strings.HasPrefix(code, "//") || strings.HasPrefix(code, "*") || strings.HasPrefix(code, "/*") {
+			continue
+		}
+		if class && indent != "" {
+			if memberIndent == "" {
+				memberIndent = indent
+			}
+			if indent == memberIndent {
+				if name, ok := jsMember(code); ok {
+					last := &nodes[len(nodes)-1]
+					last.Children = append(last.Children, OutlineNode{Label: name, Kind: "method", Line: i + 1})
+				}
+			}
+			continue
+		}
+		if indent != "" {
+			continue
+		}
+		class = false
+		node, ok := jsDeclaration(code, i+1)
+		if !ok {
+			continue
+		}
+		nodes = append(nodes, node)
+		if node.Kind == "class" {
+			// a body that closes on the same line has no members to list
+			open := strings.IndexByte(code, '{')
+			class, memberIndent = open < 0 || !strings.Contains(code[open:], "}"), ""
+		}
+	}
+	return nodes
+}
+
+// jsDeclaration reads a top-level declaration
+func jsDeclaration(code string, line int) (OutlineNode, bool) {
+	code = trimModifiers(code)
+	keyword := identPrefix(code, "$")
+	rest := strings.TrimSpace(code[len(keyword):])
+	if keyword == "const" && strings.HasPrefix(rest, "enum ") {
+		keyword, rest = "enum", strings.TrimSpace(rest[len("enum"):])
+	}
+	switch keyword {
+	case "function":
+		rest = strings.TrimSpace(strings.TrimPrefix(rest, "*"))
+		if name := identPrefix(rest, "$"); name != "" {
+			return OutlineNode{Label: name, Kind: "function", Line: line}, true
+		}
+	case "class", "interface", "enum":
+		if name := identPrefix(rest, "$"); name != "" {
+			return OutlineNode{Label: name, Kind: keyword, Line: line}, true
+		}
+	case "type":
+		if name := identPrefix(rest, "$"); name != "" && strings.ContainsAny(rest[len(name):], "=<") {
+			return OutlineNode{Label: name, Kind: "type", Line: line}, true
+		}
+	case "const", "let", "var":
+		m := jsBinding.FindStringSubmatch(code)
+		if m == nil {
+			break
+		}
+		if value := strings.TrimSpace(m[2]); isJSFunction(value) {
+			return OutlineNode{Label: m[1], Kind: "function", Line: line}, true
+		} else if strings.HasPrefix(value, "class") {
+			return OutlineNode{Label: m[1], Kind: "class", Line: line}, true
+		}
+	}
+	return OutlineNode{}, false
+}
+
+// jsMember returns the name of the method a line of a class body starts,
+// whether written as a method or as a field set to a function
+func jsMember(code string) (string, bool) {
+	code = strings.TrimSpace(strings.TrimPrefix(trimModifiers(code), "*"))
+	name := identPrefix(strings.TrimPrefix(code, "#"), "$")
+	if name == "" || contains(jsKeywords, name) {
+		return "", false
+	}
+	if strings.HasPrefix(code, "#") {
+		name = "#" + name
+	}
+	rest := strings.TrimSpace(strings.TrimPrefix(code[len(name):], "?"))
+	if strings.HasPrefix(rest, "<") {
+		if end := strings.IndexByte(rest, '>'); end > 0 {
+			rest = strings.TrimSpace(rest[end+1:])
+		}
+	}
+	if strings.HasPrefix(rest, "(") {
+		return name, true
+	}
+	if m := jsField.FindStringSubmatch(code); m != nil && isJSFunction(strings.TrimSpace(m[2])) {
+		return name, true
+	}
+	return "", false
+}
+
+// isJSFunction reports whether an expression starts a function
+func isJSFunction(value string) bool {
+	return strings.HasPrefix(value, "function") || strings.HasPrefix(value, "async function") || jsArrow.MatchString(value)
+}
+
+// trimModifiers removes the keywords that can come before the name of a
+// declaration or member, export default async and the like
+func trimModifiers(code string) string {
+	for {
+		word := identPrefix(code, "$")
+		rest := code[len(word):]
+		if word == "" || !contains(jsModifiers, word) || rest == "" || (rest[0] != ' ' && rest[0] != '\t') {
+			return code
+		}
+		next := strings.TrimSpace(rest)
+		if next == "" || next[0] == '(' || next[0] == '=' || next[0] == ':' || next[0] == '<' {
+			// a method or field called get, static and so on
+			return code
+		}
+		code = next
+	}
+}
+
+// pythonOutline lists the functions and classes defined at the top level
+// of a Python file, with the methods and nested classes of each class
+func pythonOutline(lines []string) []OutlineNode {
+	var nodes []OutlineNode
+	lit := literalTracker{comment: "#"}
+	class := false // inside the body of the last class
+	memberIndent := ""
+	for i, line := range lines {
+		inside := lit.inside()
+		lit.scan(line)
+		if inside {
+			continue
+		}
+		indent := leadingWhitespace(line)
+		code := line[len(indent):]
+		if code == "" || code[0] == '#' {
+			continue
+		}
+		if indent != "" {
+			if !class {
+				continue
+			}
+			if memberIndent == "" {
+				memberIndent = indent
+			}
+			if indent == memberIndent {
+				if node, ok := pythonDef(code, i+1); ok {
+					if node.Kind == "function" {
+						node.Kind = "method"
+					}
+					last := &nodes[len(nodes)-1]
+					last.Children = append(last.Children, node)
+				}
+			}
+			continue
+		}
+		if code[0] == '@' || code[0] == ')' || code[0] == ']' || code[0] == '}' {
+			// decorators of what follows and the end of a bracket that
+			// began indented
+			continue
+		}
+		class = false
+		if node, ok := pythonDef(code, i+1); ok {
+			nodes = append(nodes, node)
+			class, memberIndent = node.Kind == "class", ""
+		}
+	}
+	return nodes
+}
+
+// pythonDef reads a def or class statement
+func pythonDef(code string, line int) (OutlineNode, bool) {
+	kind := "function"
+	if rest, ok := strings.CutPrefix(code, "async "); ok {
+		code = strings.TrimSpace(rest)
+	}
+	rest, ok := strings.CutPrefix(code, "def ")
+	if !ok {
+		if rest, ok = strings.CutPrefix(code, "class "); !ok {
+			return OutlineNode{}, false
+		}
+		kind = "class"
+	}
+	name := identPrefix(strings.TrimSpace(rest), "")
+	if name == "" {
+		return OutlineNode{}, false
+	}
+	return OutlineNode{Label: name, Kind: kind, Line: line}, true
+}
+
+// jsonOutline lists the keys of the top-level object of a JSON document.
+// It scans the text rather than decoding it, so a document that is broken
+// partway through still lists the keys before that, and comments, as in
+// JSON with comments, are skipped.
+func jsonOutline(content string) []OutlineNode {
+	var nodes []OutlineNode
+	line, depth := 1, 0
+	started := false // the top-level value has begun
+	object := false  // the top-level value is an object
+	wantKey := false // the next string in the top-level object is a key
+	for i := 0; i < len(content); i++ {
+		switch c := content[i]; c {
+		case '\n':
+			line++
+		case '"':
+			end := jsonStringEnd(content, i)
+			if depth == 1 && object && wantKey {
+				nodes = append(nodes, OutlineNode{Label: jsonKey(content[i:end]), Kind: "key", Line: line})
+				wantKey = false
+			}
+			line += strings.Count(content[i:end], "\n")
+			i = end - 1
+		case '{', '[':
+			if depth == 0 {
+				if started {
+					// a second document after the first, as in JSON Lines
+					return nodes
+				}
+				started, object, wantKey = true, c == '{', c == '{'
+
+			}
+			depth++
+		case '}', ']':
+			depth = max(depth-1, 0)
+		case ',':
+			wantKey = depth == 1 && object
+		case '/':
+			if strings.HasPrefix(content[i:], "//") {
+				end := strings.IndexByte(content[i:], '\n')
+				if end < 0 {
+					return nodes
+				}
+				i += end - 1
+			} else if strings.HasPrefix(content[i:], "/*") {
+				end := strings.Index(content[i+2:], "*/")
+				if end < 0 {
+					return nodes
+				}
+				line += strings.Count(content[i:i+2+end], "\n")
+				i += end + 3
+			}
+		}
+	}
+	return nodes
+}
+
+// jsonStringEnd returns the index just past the string starting at start,
+// or the end of the line when it is not closed on it
+func jsonStringEnd(content string, start int) int {
+	for i := start + 1; i < len(content); i++ {
+		switch content[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		case '\n':
+			return i
+		}
+	}
+	return len(content)
+}
+
+// jsonKey returns the text of a quoted key, as it is written when its
+// escapes do not decode
+func jsonKey(quoted string) string {
+	var key string
+	if err := json.Unmarshal([]byte(quoted), &key); err != nil {
+		return strings.Trim(quoted, `"`)
+	}
+	return key
+}
+
+// yamlOutline lists the top-level keys of a YAML file, across all its
+// documents
+func yamlOutline(lines []string) []OutlineNode {
+	var nodes []OutlineNode
+	for i, line := range lines {
+		if line == "" || line[0] == ' ' || line[0] == '\t' || line[0] == '#' || line[0] == '%' {
+			continue
+		}
+		if line[0] == '-' && (len(line) == 1 || line[1] == ' ' || line[1] == '\t' || strings.HasPrefix(line, "---")) {
+			// list items and document markers
+			continue
+		}
+		if key, ok := yamlKey(line); ok {
+			nodes = append(nodes, OutlineNode{Label: key, Kind: "key", Line: i + 1})
+		}
+	}
+	return nodes
+}
+
+// yamlKey returns the key of a key: value line
+func yamlKey(line string) (string, bool) {
+	if line[0] == '"' || line[0] == '\'' {
+		end := strings.IndexByte(line[1:], line[0])
+		if end < 0 {
+			return "", false
+		}
+		rest := strings.TrimSpace(line[end+2:])
+		if !strings.HasPrefix(rest, ":") || (len(rest) > 1 && rest[1] != ' ' && rest[1] != '\t') {
+			return "", false
+		}
+		return line[1 : end+1], true
+	}
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case ':':
+			if i+1 == len(line) || line[i+1] == ' ' || line[i+1] == '\t' {
+				key := strings.TrimSpace(line[:i])
+				return key, key != ""
+			}
+		case '#':
+			if i > 0 && (line[i-1] == ' ' || line[i-1] == '\t') {
+				return "", false
+			}
+		case '[', '{':
+			if i == 0 {
+				return "", false
+			}
+		}
+	}
+	return "", false
+}
+
+// identPrefix returns the identifier s starts with. extra holds characters
+// allowed in it beyond letters, digits and underscores.
+func identPrefix(s string, extra string) string {
+	for i, r := range s {
+		switch {
+		case r == '_' || unicode.IsLetter(r) || (r < utf8.RuneSelf && strings.ContainsRune(extra, r)):
+		case i > 0 && unicode.IsDigit(r):
+		default:
+			return s[:i]
+		}
+	}
+	return s
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// outlineLabels flattens an outline into label@line entries, children
+// indented under their parents
+func outlineLabels(nodes []OutlineNode, depth int) []string {
+	var out []string
+	for _, n := range nodes {
+		out = append(out, strings.Repeat("  ", depth)+n.Label+":"+n.Kind)
+		out = append(out, outlineLabels(n.Children, depth+1)...)
+	}
+	return out
+}
+
+func TestGetOutline(t *testing.T) {
+	tests := []struct {
+		name, language, content string
+		want                    []string
+	}{
+		{"markdown", "markdown", "---\ntitle: x\n---\n# Top ##\ntext\n## Sub\n```\n# not a heading\n```\n### Deep\nSetext\n===\n- item\n---\n", []string{
+			"Top:heading", "  Sub:heading", "    Deep:heading", "Setext:heading",
+		}},
+		{"go", "go", "package x\n\nfunc A() {}\nfunc (a *App) B() {}\nfunc (s Set[T]) C() {}\nvar raw = `\nfunc Fake() {}\n`\ntype (\n\tD struct {\n\t\tX int\n\t}\n\tE = int\n)\ntype F[T any] interface{}\n", []string{
+			"A:function", "(*App).B:method", "Set.C:method", "D:struct", "E:type", "F:interface",
+		}},
+		{"typescript", "typescript", "export default class Foo {\n  constructor(a) {\n    if (a) {\n    }\n  }\n  handle = async (e) => {}\n}\nexport async function load() {}\nconst f = (a, b) => a\nconst N = 5\ninterface I {}\ntype T<X> = X[]\nenum E { A }\n", []string{
+			"Foo:class", "  constructor:method", "  handle:method", "load:function", "f:function", "I:interface", "T:type", "E:enum",
+		}},
+		{"python", "python", "class A(B):\n    '''doc\nclass Fake:\n'''\n    def m(self):\n        def inner(): pass\n    class Inner: pass\n\n# don't\nasync def top():\n    pass\n", []string{
+			"A:class", "  m:method", "  Inner:class", "top:function",
+		}},
+		{"json", "json", "// settings\n{\"a\": {\"x\": 1}, /* \"no\": 1 */ \"b\": \"v,w\", \"c\": [{\"z\": 1}]}", []string{
+			"a:key", "b:key", "c:key",
+		}},
+		{"yaml", "yaml", "name: x\nlist:\n  - a\n\"quoted key\": 1\n# comment\nblock: |\n  not: key\n---\nsecond: 2\n", []string{
+			"name:key", "list:key", "quoted key:key", "block:key", "second:key",
+		}},
+		{"unsupported", "rust", "fn main() {}", nil},
+	}
+	a := NewApp()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := outlineLabels(a.GetOutline(tt.content, tt.language), 0)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("outline = %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+	if got := a.GetOutline("", "rust"); got == nil {
+		t.Error("GetOutline returned nil rather than an empty outline")
+	}
+}
+
+func TestGetOutlinePartial(t *testing.T) {
+	tests := []struct {
+		name, language, content string
+		want                    []OutlineNode
+	}{
+		{"json cut in a value", "json", "{\n  \"a\": 1,\n  \"b\": {\"x\": [1, 2", []OutlineNode{
+			{Label: "a", Kind: "key", Line: 2}, {Label: "b", Kind: "key", Line: 3},
+		}},
+		{"json cut in a key", "json", "{\"a\": 1, \"b", []OutlineNode{
+			{Label: "a", Kind: "key", Line: 1}, {Label: "b", Kind: "key", Line: 1},
+		}},
+		{"json cut in a comment", "json", "{\"a\": 1, /* \"b\": 2", []OutlineNode{
+			{Label: "a", Kind: "key", Line: 1},
+		}},
+		{"json broken syntax", "json", "{\"a\": tru,, \"b\" 2,\n\"c\": }", []OutlineNode{
+			{Label: "a", Kind: "key", Line: 1}, {Label: "b", Kind: "key", Line: 1}, {Label: "c", Kind: "key", Line: 2},
+		}},
+		{"yaml cut in a block", "yaml", "a: 1\nb:\n  - x\n  - ", []OutlineNode{
+			{Label: "a", Kind: "key", Line: 1}, {Label: "b", Kind: "key", Line: 2},
+		}},
+		{"yaml cut in a quoted key", "yaml", "a: 1\n\"b: 2", []OutlineNode{
+			{Label: "a", Kind: "key", Line: 1},
+		}},
+		{"yaml cut in a flow mapping", "yaml", "a: {x: 1,\nb: 2", []OutlineNode{
+			{Label: "a", Kind: "key", Line: 1}, {Label: "b", Kind: "key", Line: 2},
+		}},
+	}
+	a := NewApp()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := a.GetOutline(tt.content, tt.language); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("outline = %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// repeatTo repeats unit until it makes up 1MB
+func repeatTo(unit string) string {
+	return strings.Repeat(unit, 1<<20/len(unit)+1)
+}
+
+func benchmarkOutline(b *testing.B, language, content string) {
+	a := NewApp()
+	b.SetBytes(int64(len(content)))
+	b.ResetTimer()
+	for range b.N {
+		a.GetOutline(content, language)
+	}
+}
+
+func BenchmarkGetOutline(b *testing.B) {
+	b.Run("markdown", func(b *testing.B) {
+		benchmarkOutline(b, "markdown", repeatTo("# Heading\n\nSome text with `code` in it\n## Sub\n- item\n- item two\n```go\nx := 1\n```\n"))
+	})
+	b.Run("go", func(b *testing.B) {
+		benchmarkOutline(b, "go", repeatTo("// Method does things\nfunc (a *App) Method(x int) error {\n\tif x > 0 {\n\t\treturn nil\n\t}\n\treturn `raw`\n}\ntype T struct {\n\tX int\n}\n"))
+	})
+	b.Run("typescript", func(b *testing.B) {
+		benchmarkOutline(b, "typescript", repeatTo("export class A {\n  method(a: number): void {\n    const x = `t ${a}`\n  }\n  f = () => 1\n}\nexport const g = (x: number) => x * 2\n"))
+	})
+	b.Run("python", func(b *testing.B) {
+		benchmarkOutline(b, "python", repeatTo("class A:\n    \"\"\"Doc.\"\"\"\n    def m(self, x):\n        return x  # comment\n\ndef f():\n    pass\n"))
+	})
+	b.Run("json", func(b *testing.B) {
+		benchmarkOutline(b, "json", "{"+strings.TrimSuffix(repeatTo("\"key\": {\"a\": [1, 2, \"three\"], \"b\": null},\n"), ",\n")+"}")
+	})
+	b.Run("yaml", func(b *testing.B) {
+		benchmarkOutline(b, "yaml", repeatTo("key: value\nnested:\n  a: 1\n  b: [1, 2]\nlist:\n  - x\n"))
+	})
+}
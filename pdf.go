@@ -0,0 +1,261 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Page sizes in PDF points
+var pageSizes = map[string][2]float64{
+	"A4":     {595.28, 841.89},
+	"A5":     {419.53, 595.28},
+	"Letter": {612, 792},
+	"Legal":  {612, 1008},
+}
+
+// pdfTabWidth is how many columns a tab expands to when laying out pages
+const pdfTabWidth = 4
+
+// pageLayout describes how text is placed on a page. Text is set in
+// Courier, whose glyphs are all 0.6 em wide, so wrapping is by column.
+type pageLayout struct {
+	Width, Height float64
+	// margins in points
+	Top, Right, Bottom, Left float64
+	FontSize                 float64
+}
+
+// pdfPage is the text of one page
+type pdfPage struct {
+	Header string
+	Lines  []string
+	Footer string
+}
+
+func (l pageLayout) columns() int {
+	n := int((l.Width - l.Left - l.Right) / (0.6 * l.FontSize))
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
+
+func (l pageLayout) leading() float64 {
+	return 1.2 * l.FontSize
+}
+
+func (l pageLayout) rows() int {
+	n := int((l.Height - l.Top - l.Bottom) / l.leading())
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
+
+// wrapLines splits text into lines no wider than the layout allows,
+// expanding tabs and breaking long lines at the last space that fits
+func (l pageLayout) wrapLines(text string) []string {
+	cols := l.columns()
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	var out []string
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r", "\n"), "\n") {
+		runes := expandTabs(line, pdfTabWidth)
+		for len(runes) > cols {
+			cut := cols
+			for i := cols; i > cols/2; i-- {
+				if runes[i-1] == ' ' {
+					cut = i
+					break
+				}
+			}
+			out = append(out, string(runes[:cut]))
+			runes = runes[cut:]
+		}
+		out = append(out, string(runes))
+	}
+	return out
+}
+
+// expandTabs replaces tabs in line with spaces up to the next tab stop
+func expandTabs(line string, width int) []rune {
+	out := make([]rune, 0, utf8.RuneCountInString(line))
+	for _, r := range line {
+		if r == '\t' {
+			n := width - len(out)%width
+			for i := 0; i < n; i++ {
+				out = append(out, ' ')
+			}
+			continue
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// paginate lays text out over as many pages as it needs. An empty text
+// still produces a single blank page.
+func (l pageLayout) paginate(text string) []pdfPage {
+	lines := l.wrapLines(text)
+	rows := l.rows()
+	var pages []pdfPage
+	for len(lines) > 0 {
+		n := rows
+		if n > len(lines) {
+			n = len(lines)
+		}
+		pages = append(pages, pdfPage{Lines: lines[:n]})
+		lines = lines[n:]
+	}
+	if len(pages) == 0 {
+		pages = append(pages, pdfPage{})
+	}
+	return pages
+}
+
+// maxUnprintableListed caps how many characters the unprintable error names
+const maxUnprintableListed = 20
+
+// writePDF renders pages as a PDF document. The standard Courier font only
+// covers WinAnsi, so text with any other character is refused with an error
+// naming them rather than printed with holes in it.
+func writePDF(w io.Writer, l pageLayout, pages []pdfPage) error {
+	if bad := unprintable(pages); len(bad) > 0 {
+		list := make([]string, 0, min(len(bad), maxUnprintableListed))
+		for _, r := range bad[:cap(list)] {
+			list = append(list, fmt.Sprintf("%q (U+%04X)", r, r))
+		}
+		if len(bad) > len(list) {
+			list = append(list, fmt.Sprintf("and %d more", len(bad)-len(list)))
+		}
+		return fmt.Errorf("the PDF font has no glyphs for %s", strings.Join(list, ", "))
+	}
+	bw := bufio.NewWriter(w)
+	var offsets []int
+	written := 0
+	emit := func(format string, args ...any) {
+		n, _ := fmt.Fprintf(bw, format, args...)
+		written += n
+	}
+	object := func(body string) {
+		offsets = append(offsets, written)
+		emit("%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	emit("%%PDF-1.4\n")
+	// objects 1-3 are the catalog, page tree and font; each page then takes
+	// a page object followed by its content stream
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+
+	for i, p := range pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			l.Width, l.Height, 5+2*i))
+		stream := l.pageStream(p)
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(stream), stream))
+	}
+
+	xref := written
+	emit("xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		emit("%010d 00000 n \n", off)
+	}
+	emit("trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return bw.Flush()
+}
+
+// pageStream returns the content stream drawing one page
+func (l pageLayout) pageStream(p pdfPage) string {
+	var sb strings.Builder
+	text := func(size, x, y float64, s string) {
+		fmt.Fprintf(&sb, "BT /F1 %.2f Tf %.2f %.2f Td (%s) Tj ET\n", size, x, y, pdfString(s))
+	}
+	small := l.FontSize * 0.8
+	if p.Header != "" {
+		text(small, l.Left, l.Height-l.Top/2, p.Header)
+	}
+	y := l.Height - l.Top - l.FontSize
+	for _, line := range p.Lines {
+		if line != "" {
+			text(l.FontSize, l.Left, y, line)
+		}
+		y -= l.leading()
+	}
+	if p.Footer != "" {
+		text(small, l.Left, l.Bottom/2, p.Footer)
+	}
+	return sb.String()
+}
+
+// winAnsiExtra maps the characters WinAnsi places in 0x80-0x9F, where
+// Latin-1 has control codes
+var winAnsiExtra = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87,
+	'ˆ': 0x88, '‰': 0x89, 'Š': 0x8A, '‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E,
+	'‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+	'˜': 0x98, '™': 0x99, 'š': 0x9A, '›': 0x9B, 'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
+
+// winAnsi returns the WinAnsi code of r, if it has one
+func winAnsi(r rune) (byte, bool) {
+	if r < 0x80 || (r >= 0xA0 && r <= 0xFF) {
+		return byte(r), true
+	}
+	b, ok := winAnsiExtra[r]
+	return b, ok
+}
+
+// unprintable returns the characters on pages, in the order they first
+// appear, that WinAnsi cannot encode. Control characters are left out since
+// they are printed as spaces.
+func unprintable(pages []pdfPage) []rune {
+	seen := make(map[rune]bool)
+	var bad []rune
+	check := func(s string) {
+		for _, r := range s {
+			if _, ok := winAnsi(r); !ok && !unicode.IsControl(r) && !seen[r] {
+				seen[r] = true
+				bad = append(bad, r)
+			}
+		}
+	}
+	for _, p := range pages {
+		check(p.Header)
+		for _, line := range p.Lines {
+			check(line)
+		}
+		check(p.Footer)
+	}
+	return bad
+}
+
+// pdfString escapes s for a PDF literal string in WinAnsi encoding.
+// Characters it has no code for are printed as '?'.
+func pdfString(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		b, ok := winAnsi(r)
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case unicode.IsControl(r):
+			sb.WriteByte(' ')
+		case !ok:
+			sb.WriteByte('?')
+		case b < 0x80:
+			sb.WriteByte(b)
+		default:
+			fmt.Fprintf(&sb, "\\%03o", b)
+		}
+	}
+	return sb.String()
+}
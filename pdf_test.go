@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPDFString(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"plain (text) \\", `plain \(text\) \\`},
+		{"tab\tbell\a", "tab bell "},
+		{"café", `caf\351`},
+		{"“quoted” – €5", `\223quoted\224 \226 \2005`},
+		{"日本", "??"},
+	}
+	for _, tt := range tests {
+		if got := pdfString(tt.in); got != tt.want {
+			t.Errorf("pdfString(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWritePDFUnprintable(t *testing.T) {
+	l := pageLayout{Width: 595.28, Height: 841.89, Top: 72, Right: 72, Bottom: 72, Left: 72, FontSize: 10}
+
+	var out bytes.Buffer
+	if err := writePDF(&out, l, l.paginate("naïve “café” — €5\n")); err != nil {
+		t.Fatalf("WinAnsi text refused: %v", err)
+	}
+	if !bytes.HasPrefix(out.Bytes(), []byte("%PDF-1.4")) {
+		t.Errorf("output does not start with a PDF header")
+	}
+
+	out.Reset()
+	pages := l.paginate("Привет, мир\nПока")
+	pages[0].Footer = "日"
+	err := writePDF(&out, l, pages)
+	if err == nil {
+		t.Fatal("Cyrillic text was printed")
+	}
+	for _, want := range []string{"'П' (U+041F)", "'р' (U+0440)", "'日' (U+65E5)"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not name %s", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), "',' (") {
+		t.Errorf("error %q names a printable character", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("%d bytes written before the error", out.Len())
+	}
+
+	var many []rune
+	for r := rune(0x4E00); len(many) < maxUnprintableListed+2; r++ {
+		many = append(many, r)
+	}
+	if err := writePDF(&out, l, l.paginate(string(many))); err == nil || !strings.HasSuffix(err.Error(), "and 2 more") {
+		t.Errorf("writePDF with %d unprintable characters = %v", len(many), err)
+	}
+}
+
+func TestExportLargeFile(t *testing.T) {
+	a := NewApp()
+	a.mu.Lock()
+	d := a.newDocumentLocked()
+	d.LargeFile = true
+	a.mu.Unlock()
+
+	dest := filepath.Join(t.TempDir(), "out.pdf")
+	if err := a.ExportAs(d.ID, "pdf", dest); !errors.Is(err, ErrLargeFile) {
+		t.Errorf("ExportAs of a large file = %v, want ErrLargeFile", err)
+	}
+	if _, err := a.Print(d.ID, PrintOptions{}); !errors.Is(err, ErrLargeFile) {
+		t.Errorf("Print of a large file = %v, want ErrLargeFile", err)
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PrintOptions controls how a document is laid out for printing. Zero
+// values fall back to A4 paper, 15mm margins and 10pt text; empty header
+// and footer templates are left off the page.
+type PrintOptions struct {
+	PageSize string  `json:"pageSize"` // A4, A5, Letter or Legal
+	Margins  Margins `json:"margins"`
+	// Header and Footer may use %f for the file name, %p for the page
+	// number and %P for the page count
+	Header   string  `json:"header"`
+	Footer   string  `json:"footer"`
+	FontSize float64 `json:"fontSize"` // points
+}
+
+// Margins are page margins in millimetres
+type Margins struct {
+	Top    float64 `json:"top"`
+	Right  float64 `json:"right"`
+	Bottom float64 `json:"bottom"`
+	Left   float64 `json:"left"`
+}
+
+const pointsPerMM = 72 / 25.4
+
+// layout converts the options into a page layout, applying defaults
+func (o PrintOptions) layout() (pageLayout, error) {
+	name := o.PageSize
+	if name == "" {
+		name = "A4"
+	}
+	size, ok := pageSizes[name]
+	if !ok {
+		names := make([]string, 0, len(pageSizes))
+		for n := range pageSizes {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return pageLayout{}, fmt.Errorf("unknown page size %q, expected one of %s", name, strings.Join(names, ", "))
+	}
+	m := o.Margins
+	if m == (Margins{}) {
+		m = Margins{Top: 15, Right: 15, Bottom: 15, Left: 15}
+	}
+	if m.Top < 0 || m.Right < 0 || m.Bottom < 0 || m.Left < 0 {
+		return pageLayout{}, fmt.Errorf("margins must not be negative")
+	}
+	fontSize := o.FontSize
+	if fontSize == 0 {
+		fontSize = 10
+	}
+	if fontSize < 4 || fontSize > 72 {
+		return pageLayout{}, fmt.Errorf("font size must be between 4 and 72 points")
+	}
+
+	l := pageLayout{
+		Width:    size[0],
+		Height:   size[1],
+		Top:      m.Top * pointsPerMM,
+		Right:    m.Right * pointsPerMM,
+		Bottom:   m.Bottom * pointsPerMM,
+		Left:     m.Left * pointsPerMM,
+		FontSize: fontSize,
+	}
+	if l.Left+l.Right+0.6*fontSize > l.Width || l.Top+l.Bottom+l.leading() > l.Height {
+		return pageLayout{}, fmt.Errorf("margins leave no room for text on %s paper", name)
+	}
+	return l, nil
+}
+
+// expandPageTemplate substitutes %f, %p and %P in a header or footer
+func expandPageTemplate(tmpl, file string, page, pages int) string {
+	if tmpl == "" {
+		return ""
+	}
+	return strings.NewReplacer(
+		"%f", file,
+		"%p", strconv.Itoa(page),
+		"%P", strconv.Itoa(pages),
+		"%%", "%",
+	).Replace(tmpl)
+}
+
+// Print lays a document out in pages and sends it to the printer, returning
+// the number of pages produced
+func (a *App) Print(docID string, opts PrintOptions) (int, error) {
+	a.mu.Lock()
+	d, err := a.docLocked(docID)
+	if err != nil {
+		a.mu.Unlock()
+		return 0, err
+	}
+	if d.LargeFile {
+		a.mu.Unlock()
+		return 0, ErrLargeFile
+	}
+	name, content := d.Name(), d.Content
+	a.mu.Unlock()
+
+	l, err := opts.layout()
+	if err != nil {
+		return 0, err
+	}
+	pages := l.paginate(content)
+	for i := range pages {
+		pages[i].Header = expandPageTemplate(opts.Header, name, i+1, len(pages))
+		pages[i].Footer = expandPageTemplate(opts.Footer, name, i+1, len(pages))
+	}
+
+	f, err := os.CreateTemp("", "wailspad-print-*.pdf")
+	if err != nil {
+		return 0, err
+	}
+	if err := writePDF(f, l, pages); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return 0, err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return 0, err
+	}
+	if err := printPDF(filepath.Clean(f.Name())); err != nil {
+		return 0, fmt.Errorf("printing %s: %w", name, err)
+	}
+	return len(pages), nil
+}
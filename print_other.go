@@ -0,0 +1,26 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// printPDF sends the file to the default CUPS printer with lp, falling back
+// to opening it in the desktop's PDF viewer to print from there
+func printPDF(path string) error {
+	if lp, err := exec.LookPath("lp"); err == nil {
+		if err := exec.Command(lp, path).Run(); err == nil {
+			os.Remove(path)
+			return nil
+		}
+	}
+	opener := "xdg-open"
+	if runtime.GOOS == "darwin" {
+		opener = "open"
+	}
+	// the viewer reads the file after we return, so it stays in the temp dir
+	return exec.Command(opener, path).Start()
+}
@@ -0,0 +1,53 @@
+//go:build windows
+
+package main
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	shell32           = syscall.NewLazyDLL("shell32.dll")
+	procShellExecuteW = shell32.NewProc("ShellExecuteW")
+)
+
+// shellExecute runs a shell verb on file, such as "open" or "print"
+func shellExecute(verb, file, params, dir string) error {
+	v, err := syscall.UTF16PtrFromString(verb)
+	if err != nil {
+		return err
+	}
+	f, err := syscall.UTF16PtrFromString(file)
+	if err != nil {
+		return err
+	}
+	var p, d *uint16
+	if params != "" {
+		if p, err = syscall.UTF16PtrFromString(params); err != nil {
+			return err
+		}
+	}
+	if dir != "" {
+		if d, err = syscall.UTF16PtrFromString(dir); err != nil {
+			return err
+		}
+	}
+	const swShowNormal = 1
+	ret, _, _ := procShellExecuteW.Call(0,
+		uintptr(unsafe.Pointer(v)), uintptr(unsafe.Pointer(f)),
+		uintptr(unsafe.Pointer(p)), uintptr(unsafe.Pointer(d)), swShowNormal)
+	// values of 32 or less are errors
+	if ret <= 32 {
+		return fmt.Errorf("ShellExecute %s failed with code %d", verb, ret)
+	}
+	return nil
+}
+
+// printPDF hands the file to the print verb of the registered PDF handler,
+// which normally shows the printer dialog. The file is left in the temp
+// directory since the handler reads it after we return.
+func printPDF(path string) error {
+	return shellExecute("print", path, "", "")
+}
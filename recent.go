@@ -0,0 +1,81 @@
+package main
+
+import "os"
+
+// maxRecentFiles caps the most-recently-used list
+const maxRecentFiles = 10
+
+// loadRecentFiles reads the recent files list, dropping entries whose
+// files no longer exist
+func loadRecentFiles() []string {
+	path, err := configPath("recent.json")
+	if err != nil {
+		return nil
+	}
+	var files []string
+	if err := readJSON(path, &files); err != nil {
+		return nil
+	}
+	kept := files[:0]
+	for _, f := range files {
+		if _, err := os.Stat(f); err == nil {
+			kept = append(kept, f)
+		}
+	}
+	if len(kept) > maxRecentFiles {
+		kept = kept[:maxRecentFiles]
+	}
+	return kept
+}
+
+// saveRecentFiles persists the recent files list
+func saveRecentFiles(files []string) error {
+	path, err := configPath("recent.json")
+	if err != nil {
+		return err
+	}
+	if files == nil {
+		files = []string{}
+	}
+	return writeJSON(path, files, 0o644)
+}
+
+// pushRecent moves path to the front of files, removing any earlier entry
+func pushRecent(files []string, path string) []string {
+	out := make([]string, 0, maxRecentFiles)
+	out = append(out, path)
+	for _, f := range files {
+		if f != path && len(out) < maxRecentFiles {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// GetRecentFiles returns recently opened or saved files, most recent first
+func (a *App) GetRecentFiles() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]string{}, a.recent...)
+}
+
+// ClearRecentFiles empties the recent files list
+func (a *App) ClearRecentFiles() error {
+	a.mu.Lock()
+	a.recent = nil
+	err := saveRecentFiles(nil)
+	a.mu.Unlock()
+	a.refreshTray()
+	a.RefreshMenu()
+	return err
+}
+
+// addRecent records path as the most recently used file
+func (a *App) addRecent(path string) {
+	a.mu.Lock()
+	a.recent = pushRecent(a.recent, path)
+	saveRecentFiles(a.recent)
+	a.mu.Unlock()
+	a.refreshTray()
+	a.RefreshMenu()
+}
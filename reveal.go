@@ -0,0 +1,87 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// RevealInFileManager opens the folder holding path in the system file
+// manager with path selected
+func (a *App) RevealInFileManager(path string) error {
+	abs, _, err := existingPath(path)
+	if err != nil {
+		return err
+	}
+	if err := revealFile(abs); err != nil {
+		a.log.Warn("reveal failed", "path", abs, "err", err)
+		return newFileError(abs, err)
+	}
+	return nil
+}
+
+// OpenInDefaultApp opens path with the application the system associates
+// with it
+func (a *App) OpenInDefaultApp(path string) error {
+	abs, _, err := existingPath(path)
+	if err != nil {
+		return err
+	}
+	if err := openDefault(abs); err != nil {
+		a.log.Warn("opening in the default app failed", "path", abs, "err", err)
+		return newFileError(abs, err)
+	}
+	return nil
+}
+
+// OpenTerminalHere opens a terminal in path, or in the folder of path when
+// it is a file. The terminal setting picks the program, otherwise the
+// platform's usual terminal is used.
+func (a *App) OpenTerminalHere(path string) error {
+	abs, info, err := existingPath(path)
+	if err != nil {
+		return err
+	}
+	dir := abs
+	if !info.IsDir() {
+		dir = filepath.Dir(abs)
+	}
+	a.mu.Lock()
+	terminal := a.settings.Terminal
+	a.mu.Unlock()
+	if err := openTerminal(terminal, dir); err != nil {
+		a.log.Warn("opening a terminal failed", "dir", dir, "terminal", terminal, "err", err)
+		return newFileError(dir, err)
+	}
+	return nil
+}
+
+// existingPath makes path absolute and checks that it exists. Commands are
+// only ever given absolute paths, which cannot be mistaken for options.
+func existingPath(path string) (string, os.FileInfo, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", nil, newFileError(path, err)
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return "", nil, newFileError(abs, err)
+	}
+	return abs, info, nil
+}
+
+// hasCommand reports whether name is a program on the PATH
+func hasCommand(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
+
+// startDetached starts cmd without waiting for it to finish. The process is
+// still reaped once it exits.
+func startDetached(cmd *exec.Cmd) error {
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
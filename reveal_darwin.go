@@ -0,0 +1,22 @@
+//go:build darwin
+
+package main
+
+import "os/exec"
+
+func revealFile(path string) error {
+	return exec.Command("open", "-R", path).Run()
+}
+
+func openDefault(path string) error {
+	return exec.Command("open", path).Run()
+}
+
+// openTerminal opens dir in the terminal application, Terminal unless the
+// setting names another such as iTerm
+func openTerminal(terminal, dir string) error {
+	if terminal == "" {
+		terminal = "Terminal"
+	}
+	return exec.Command("open", "-a", terminal, dir).Run()
+}
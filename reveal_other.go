@@ -0,0 +1,60 @@
+//go:build !windows && !darwin
+
+package main
+
+import (
+	"errors"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// terminals are tried in order when neither the setting nor $TERMINAL names
+// one. x-terminal-emulator is the Debian alternative for the user's choice.
+var terminals = []string{
+	"x-terminal-emulator", "gnome-terminal", "konsole", "xfce4-terminal",
+	"mate-terminal", "lxterminal", "alacritty", "kitty", "foot", "xterm",
+}
+
+// revealFile asks the file manager to show path over D-Bus, which
+// Nautilus, Dolphin, Nemo and others take. Without one the folder is opened
+// with nothing selected.
+func revealFile(path string) error {
+	if conn, err := dbus.SessionBus(); err == nil {
+		uri := (&url.URL{Scheme: "file", Path: path}).String()
+		obj := conn.Object("org.freedesktop.FileManager1", "/org/freedesktop/FileManager1")
+		if obj.Call("org.freedesktop.FileManager1.ShowItems", 0, []string{uri}, "").Err == nil {
+			return nil
+		}
+	}
+	return startDetached(exec.Command("xdg-open", filepath.Dir(path)))
+}
+
+func openDefault(path string) error {
+	return startDetached(exec.Command("xdg-open", path))
+}
+
+// openTerminal starts terminal, the one in $TERMINAL or the first of
+// terminals installed, in dir
+func openTerminal(terminal, dir string) error {
+	if terminal == "" {
+		terminal = os.Getenv("TERMINAL")
+	}
+	if terminal == "" {
+		for _, t := range terminals {
+			if hasCommand(t) {
+				terminal = t
+				break
+			}
+		}
+	}
+	if terminal == "" {
+		return errors.New("no terminal found, set one in the terminal setting")
+	}
+	cmd := exec.Command(terminal)
+	cmd.Dir = dir
+	return startDetached(cmd)
+}
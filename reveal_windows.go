@@ -0,0 +1,37 @@
+//go:build windows
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+)
+
+// revealFile runs explorer /select. Explorer does its own parsing of the
+// command line and wants the path quoted after the comma, so the line is
+// built by hand; Windows file names cannot contain quotes.
+func revealFile(path string) error {
+	explorer := filepath.Join(os.Getenv("SystemRoot"), "explorer.exe")
+	cmd := exec.Command(explorer)
+	cmd.SysProcAttr = &syscall.SysProcAttr{CmdLine: `explorer.exe /select,"` + path + `"`}
+	// explorer exits with 1 even when it worked, so only starting it counts
+	return startDetached(cmd)
+}
+
+func openDefault(path string) error {
+	return shellExecute("open", path, "", "")
+}
+
+// openTerminal starts terminal, Windows Terminal or cmd.exe in dir
+func openTerminal(terminal, dir string) error {
+	switch {
+	case terminal != "":
+		return shellExecute("open", terminal, "", dir)
+	case hasCommand("wt.exe"):
+		return shellExecute("open", "wt.exe", "-d .", dir)
+	default:
+		return shellExecute("open", "cmd.exe", "", dir)
+	}
+}
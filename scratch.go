@@ -0,0 +1,273 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	// scratchDelay is how long a scratch has to be left alone before its
+	// changes are written
+	scratchDelay = time.Second
+	// scratchWarn is the number of scratches past which the user is told
+	// to tidy up with a scratch:warning event
+	scratchWarn = 100
+	// maxScratches is the number of scratches NewScratch refuses to go past
+	maxScratches = 1000
+)
+
+// Scratch is a buffer kept in the config folder rather than a file of the
+// user's, so it survives restarts without ever being given a name
+type Scratch struct {
+	ID       string    `json:"id"`
+	Title    string    `json:"title"` // the first line of the content
+	Content  string    `json:"content"`
+	Modified time.Time `json:"modified"`
+}
+
+// scratch is a Scratch as held in memory
+type scratch struct {
+	content  string
+	modified time.Time
+	pending  bool        // changed since last written
+	timer    *time.Timer // writes it once the changes pause
+}
+
+// NewScratch creates an empty scratch and returns its ID
+func (a *App) NewScratch() (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	scratches := a.scratchesLocked()
+	if len(scratches) >= maxScratches {
+		return "", fmt.Errorf("there are already %d scratches, delete or promote some first", len(scratches))
+	}
+	id := "scratch-" + strconv.FormatInt(time.Now().UnixMilli(), 36)
+	for n := 2; scratches[id] != nil; n++ {
+		id = fmt.Sprintf("scratch-%s-%d", strconv.FormatInt(time.Now().UnixMilli(), 36), n)
+	}
+	s := &scratch{modified: time.Now(), pending: true}
+	scratches[id] = s
+	a.scheduleScratchLocked(id, s)
+	if len(scratches) > scratchWarn {
+		a.log.Warn("many scratches", "count", len(scratches))
+		runtime.EventsEmit(a.ctx, "scratch:warning", len(scratches))
+	}
+	return id, nil
+}
+
+// UpdateScratch records the content of a scratch. It is written to disk
+// once the changes pause for a second.
+func (a *App) UpdateScratch(id, content string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	s, ok := a.scratchesLocked()[id]
+	if !ok {
+		return fmt.Errorf("no scratch %q", id)
+	}
+	if s.content == content {
+		return nil
+	}
+	s.content = content
+	s.modified = time.Now()
+	s.pending = true
+	a.scheduleScratchLocked(id, s)
+	return nil
+}
+
+// ListScratches returns the scratches, most recently changed first
+func (a *App) ListScratches() []Scratch {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	list := []Scratch{}
+	for id, s := range a.scratchesLocked() {
+		list = append(list, Scratch{ID: id, Title: scratchTitle(s.content), Content: s.content, Modified: s.modified})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].Modified.Equal(list[j].Modified) {
+			return list[i].Modified.After(list[j].Modified)
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
+
+// PromoteScratch saves a scratch as the file path, opens that as a document
+// and removes the scratch
+func (a *App) PromoteScratch(id, path string) (Document, error) {
+	a.mu.Lock()
+	s, ok := a.scratchesLocked()[id]
+	var content string
+	if ok {
+		content = s.content
+	}
+	a.mu.Unlock()
+	if !ok {
+		return Document{}, fmt.Errorf("no scratch %q", id)
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return Document{}, newFileError(path, err)
+	}
+	if err := writeFile(abs, []byte(convertLineEndings(content, defaultLineEnding())), 0o644); err != nil {
+		return Document{}, newFileError(abs, err)
+	}
+	if err := a.DeleteScratch(id); err != nil {
+		return Document{}, err
+	}
+	a.log.Info("promoted scratch", "id", id, "path", abs)
+	return a.OpenDocument(abs)
+}
+
+// DeleteScratch removes a scratch and its file
+func (a *App) DeleteScratch(id string) error {
+	a.scratchMu.Lock()
+	defer a.scratchMu.Unlock()
+	a.mu.Lock()
+	scratches := a.scratchesLocked()
+	s, ok := scratches[id]
+	if ok {
+		if s.timer != nil {
+			s.timer.Stop()
+		}
+		delete(scratches, id)
+	}
+	a.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("no scratch %q", id)
+	}
+	path, err := scratchPath(id)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return newFileError(path, err)
+	}
+	return nil
+}
+
+// flushScratches writes every scratch with unwritten changes
+func (a *App) flushScratches() {
+	a.mu.Lock()
+	var ids []string
+	for id, s := range a.scratches {
+		if s.timer != nil {
+			s.timer.Stop()
+		}
+		if s.pending {
+			ids = append(ids, id)
+		}
+	}
+	a.mu.Unlock()
+	for _, id := range ids {
+		a.writeScratch(id)
+	}
+}
+
+// scheduleScratchLocked (re)starts the timer that writes s. a.mu must be
+// held.
+func (a *App) scheduleScratchLocked(id string, s *scratch) {
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+	s.timer = time.AfterFunc(scratchDelay, func() {
+		a.writeScratch(id)
+	})
+}
+
+// writeScratch writes a scratch to its file if it has unwritten changes
+func (a *App) writeScratch(id string) {
+	// held across the write, so a scratch deleted meanwhile is not written
+	// back
+	a.scratchMu.Lock()
+	defer a.scratchMu.Unlock()
+	a.mu.Lock()
+	s, ok := a.scratches[id]
+	if !ok || !s.pending {
+		a.mu.Unlock()
+		return
+	}
+	content := s.content
+	s.pending = false
+	a.mu.Unlock()
+
+	path, err := scratchPath(id)
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(path), 0o700)
+	}
+	if err == nil {
+		err = writeFile(path, []byte(content), 0o644)
+	}
+	if err != nil {
+		a.log.Warn("writing scratch failed", "id", id, "err", err)
+		a.mu.Lock()
+		s.pending = true
+		a.mu.Unlock()
+	}
+}
+
+// scratchesLocked returns the scratches by ID, loading them from the
+// scratch folder on first use. a.mu must be held.
+func (a *App) scratchesLocked() map[string]*scratch {
+	if a.scratches != nil {
+		return a.scratches
+	}
+	a.scratches = map[string]*scratch{}
+	dir, err := configPath("scratch")
+	if err != nil {
+		return a.scratches
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return a.scratches
+	}
+	for _, e := range entries {
+		id, ok := strings.CutSuffix(e.Name(), ".txt")
+		if !ok || !strings.HasPrefix(id, "scratch-") || !e.Type().IsRegular() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		text, _, _ := decodeText(data)
+		a.scratches[id] = &scratch{content: text, modified: info.ModTime()}
+	}
+	return a.scratches
+}
+
+// scratchPath returns the file of a scratch
+func scratchPath(id string) (string, error) {
+	if !strings.HasPrefix(id, "scratch-") || strings.ContainsAny(id, `/\.`) {
+		return "", fmt.Errorf("invalid scratch ID %q", id)
+	}
+	return configPath("scratch", id+".txt")
+}
+
+// scratchTitle names a scratch after its first line that is not blank
+func scratchTitle(content string) string {
+	const maxLen = 40
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if r := []rune(line); len(r) > maxLen {
+			return string(r[:maxLen]) + "..."
+		}
+		return line
+	}
+	return "Scratch"
+}
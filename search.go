@@ -0,0 +1,303 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path"
+	"path/filepath"
+	goruntime "runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// maxResultLine caps how much of a matching line is sent with each result
+const maxResultLine = 1000
+
+// SearchOptions controls a find-in-files search. Include and Exclude are
+// glob patterns; a pattern without a slash matches file or directory names,
+// one with a slash matches the path relative to the root and may use **.
+type SearchOptions struct {
+	FindOptions
+	Include       []string `json:"include"`
+	Exclude       []string `json:"exclude"`
+	MaxFileSize   int64    `json:"maxFileSize"` // bytes, 0 means maxFileSize
+	IncludeBinary bool     `json:"includeBinary"`
+}
+
+// SearchResult is one match, sent as a search:result event
+type SearchResult struct {
+	SearchID string `json:"searchId"`
+	Path     string `json:"path"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Length   int    `json:"length"`
+	Text     string `json:"text"`
+}
+
+// SearchSummary is sent as a search:done event when a search finishes
+type SearchSummary struct {
+	SearchID  string `json:"searchId"`
+	Files     int    `json:"files"`   // files searched
+	Matched   int    `json:"matched"` // files with at least one match
+	Matches   int    `json:"matches"`
+	Skipped   int    `json:"skipped"` // unreadable, binary or oversized
+	Truncated bool   `json:"truncated"`
+	Cancelled bool   `json:"cancelled"`
+}
+
+// SearchInFolder searches the files under root in the background and
+// returns an ID for CancelSearch. Results arrive as search:result events
+// followed by one search:done.
+func (a *App) SearchInFolder(root string, query string, opts SearchOptions) (string, error) {
+	m, err := newMatcher(query, opts.FindOptions)
+	if err != nil {
+		return "", err
+	}
+	root, err = filepath.Abs(root)
+	if err != nil {
+		return "", newFileError(root, err)
+	}
+	info, err := os.Stat(root)
+	if err != nil {
+		return "", newFileError(root, err)
+	}
+	if !info.IsDir() {
+		return "", &FileError{Code: "invalid_path", Message: root + " is not a folder"}
+	}
+	if opts.MaxFileSize <= 0 {
+		opts.MaxFileSize = maxFileSize
+	}
+
+	parent := a.bg
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithCancel(parent)
+
+	a.mu.Lock()
+	if a.searches == nil {
+		a.searches = make(map[string]context.CancelFunc)
+	}
+	a.searchSeq++
+	id := "search-" + strconv.Itoa(a.searchSeq)
+	a.searches[id] = cancel
+	a.mu.Unlock()
+
+	s := &search{app: a, id: id, root: root, opts: opts, m: m}
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		summary := s.run(ctx)
+		summary.Cancelled = ctx.Err() != nil
+
+		a.mu.Lock()
+		delete(a.searches, id)
+		a.mu.Unlock()
+		cancel()
+		runtime.EventsEmit(a.ctx, "search:done", summary)
+	}()
+	return id, nil
+}
+
+// CancelSearch stops a running search. Its search:done event still fires.
+func (a *App) CancelSearch(searchID string) {
+	a.mu.Lock()
+	cancel := a.searches[searchID]
+	a.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+}
+
+// search is one running SearchInFolder
+type search struct {
+	app  *App
+	id   string
+	root string
+	opts SearchOptions
+	m    *matcher
+
+	files, matched, matches, skipped atomic.Int64
+	truncated                        atomic.Bool
+}
+
+// run walks the tree on one goroutine and searches files on the others
+func (s *search) run(ctx context.Context) SearchSummary {
+	ctx, stop := context.WithCancel(ctx)
+	defer stop()
+
+	paths := make(chan string, 64)
+	var wg sync.WaitGroup
+	for i := 0; i < goruntime.NumCPU(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p := range paths {
+				if ctx.Err() != nil {
+					continue
+				}
+				if !s.searchFile(ctx, p) {
+					stop()
+				}
+			}
+		}()
+	}
+
+	s.walk(ctx, s.root, map[string]bool{}, paths)
+	close(paths)
+	wg.Wait()
+
+	return SearchSummary{
+		SearchID:  s.id,
+		Files:     int(s.files.Load()),
+		Matched:   int(s.matched.Load()),
+		Matches:   int(s.matches.Load()),
+		Skipped:   int(s.skipped.Load()),
+		Truncated: s.truncated.Load(),
+	}
+}
+
+// walk sends the files under dir to paths. Symlinks are followed, and
+// visited holds the resolved directories already seen so a loop is only
+// entered once. Directories that cannot be read are skipped.
+func (s *search) walk(ctx context.Context, dir string, visited map[string]bool, paths chan<- string) {
+	real, err := filepath.EvalSymlinks(dir)
+	if err != nil || visited[real] {
+		return
+	}
+	visited[real] = true
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		s.skipped.Add(1)
+		return
+	}
+	for _, e := range entries {
+		if ctx.Err() != nil {
+			return
+		}
+		p := filepath.Join(dir, e.Name())
+		rel, _ := filepath.Rel(s.root, p)
+		rel = filepath.ToSlash(rel)
+
+		isDir := e.IsDir()
+		if e.Type()&os.ModeSymlink != 0 {
+			info, err := os.Stat(p)
+			if err != nil {
+				continue // dangling link
+			}
+			isDir = info.IsDir()
+		}
+		if matchAny(s.opts.Exclude, rel) {
+			continue
+		}
+		if isDir {
+			s.walk(ctx, p, visited, paths)
+			continue
+		}
+		if len(s.opts.Include) > 0 && !matchAny(s.opts.Include, rel) {
+			continue
+		}
+		select {
+		case paths <- p:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// searchFile emits the matches in one file. It returns false once the
+// result limit is reached.
+func (s *search) searchFile(ctx context.Context, p string) bool {
+	info, err := os.Stat(p)
+	if err != nil || !info.Mode().IsRegular() || info.Size() > s.opts.MaxFileSize {
+		s.skipped.Add(1)
+		return true
+	}
+	data, err := os.ReadFile(p)
+	binary := err == nil && looksBinary(data)
+	if err != nil || (binary && !s.opts.IncludeBinary) {
+		s.skipped.Add(1)
+		return true
+	}
+	s.files.Add(1)
+
+	text := string(data)
+	if !binary {
+		text, _, _ = decodeText(data)
+	}
+	found := s.m.find(text, maxMatches)
+	if len(found.Matches) == 0 {
+		return true
+	}
+	s.matched.Add(1)
+
+	lines := strings.Split(text, "\n")
+	for _, m := range found.Matches {
+		if ctx.Err() != nil {
+			return true
+		}
+		if s.matches.Add(1) > maxMatches {
+			s.matches.Add(-1)
+			s.truncated.Store(true)
+			return false
+		}
+		line := strings.TrimSuffix(lines[m.Line-1], "\r")
+		runtime.EventsEmit(s.app.ctx, "search:result", SearchResult{
+			SearchID: s.id,
+			Path:     p,
+			Line:     m.Line,
+			Column:   m.Column,
+			Length:   m.Length,
+			Text:     truncateUTF8(line, maxResultLine),
+		})
+	}
+	return true
+}
+
+// matchAny reports whether rel matches any of patterns
+func matchAny(patterns []string, rel string) bool {
+	for _, p := range patterns {
+		if matchGlob(p, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob matches a slash-separated relative path against pattern. A
+// pattern without a slash is tried against the last element only, and a **
+// element matches any number of directories.
+func matchGlob(pattern, rel string) bool {
+	pattern = strings.TrimPrefix(filepath.ToSlash(pattern), "./")
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(rel))
+		return ok
+	}
+	return matchSegments(strings.Split(strings.Trim(pattern, "/"), "/"), strings.Split(rel, "/"))
+}
+
+func matchSegments(pattern, parts []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(parts); i++ {
+				if matchSegments(pattern[1:], parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], parts[0]); !ok {
+			return false
+		}
+		pattern, parts = pattern[1:], parts[1:]
+	}
+	return len(parts) == 0
+}
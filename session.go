@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// SessionState is what the editor needs to restore where the user left off
+type SessionState struct {
+	Files []SessionFile `json:"files"`
+	// Active indexes Files, -1 when no file tab was active
+	Active int            `json:"active"`
+	Window WindowGeometry `json:"window"`
+	// Zoom is the editor zoom level when the session was saved
+	Zoom float64 `json:"zoom,omitempty"`
+	// Skipped lists files from the saved session that no longer exist
+	Skipped []string `json:"skipped,omitempty"`
+}
+
+// SessionFile is an open file and where the cursor was in it
+type SessionFile struct {
+	Path   string `json:"path"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+// WindowGeometry is the size and position of the main window
+type WindowGeometry struct {
+	X         int  `json:"x"`
+	Y         int  `json:"y"`
+	Width     int  `json:"width"`
+	Height    int  `json:"height"`
+	Maximised bool `json:"maximised"`
+}
+
+// loadSession reads the saved session, moving files that have vanished
+// since into Skipped
+func loadSession() SessionState {
+	state := SessionState{Files: []SessionFile{}, Active: -1}
+	path, err := configPath("session.json")
+	if err != nil {
+		return state
+	}
+	var saved SessionState
+	if err := readJSON(path, &saved); err != nil {
+		return state
+	}
+
+	state.Window = saved.Window
+	if saved.Zoom != 0 {
+		state.Zoom = clampZoom(saved.Zoom)
+	}
+	for i, f := range saved.Files {
+		if _, err := os.Stat(f.Path); err != nil {
+			state.Skipped = append(state.Skipped, f.Path)
+			continue
+		}
+		if i == saved.Active {
+			state.Active = len(state.Files)
+		}
+		state.Files = append(state.Files, f)
+	}
+	return state
+}
+
+// writeSession persists state
+func writeSession(state SessionState) error {
+	path, err := configPath("session.json")
+	if err != nil {
+		return err
+	}
+	state.Skipped = nil
+	if state.Files == nil {
+		state.Files = []SessionFile{}
+	}
+	return writeJSON(path, state, 0o644)
+}
+
+// SaveSession stores the frontend's view of the session and writes it to disk
+func (a *App) SaveSession(state SessionState) error {
+	a.mu.Lock()
+	a.session = state
+	a.mu.Unlock()
+	return writeSession(state)
+}
+
+// LoadSession returns the session saved by the previous run
+func (a *App) LoadSession() SessionState {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.restored
+}
+
+// restoreWindow applies saved window geometry
+func restoreWindow(ctx context.Context, w WindowGeometry) {
+	if w.Width <= 0 || w.Height <= 0 {
+		return
+	}
+	runtime.WindowSetSize(ctx, w.Width, w.Height)
+	runtime.WindowSetPosition(ctx, w.X, w.Y)
+	if w.Maximised {
+		runtime.WindowMaximise(ctx)
+	}
+}
+
+// captureWindow records the current window geometry in the session. It has
+// to run while the window still exists, so it is called from beforeClose.
+func (a *App) captureWindow(ctx context.Context) {
+	var w WindowGeometry
+	w.Maximised = runtime.WindowIsMaximised(ctx)
+	if w.Maximised {
+		// keep the restored size from before the window was maximised
+		a.mu.Lock()
+		prev := a.session.Window
+		a.mu.Unlock()
+		w.X, w.Y, w.Width, w.Height = prev.X, prev.Y, prev.Width, prev.Height
+	} else {
+		w.X, w.Y = runtime.WindowGetPosition(ctx)
+		w.Width, w.Height = runtime.WindowGetSize(ctx)
+	}
+	a.mu.Lock()
+	a.session.Window = w
+	a.mu.Unlock()
+}
+
+// currentSession builds the session from the open documents, keeping the
+// cursor positions last reported by the frontend
+func (a *App) currentSession() SessionState {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	cursors := make(map[string]SessionFile, len(a.session.Files))
+	for _, f := range a.session.Files {
+		cursors[f.Path] = f
+	}
+
+	state := SessionState{Files: []SessionFile{}, Active: -1, Window: a.session.Window, Zoom: a.settings.Zoom}
+	for _, id := range a.order {
+		d := a.docs[id]
+		if d.Path == "" {
+			continue
+		}
+		if id == a.active {
+			state.Active = len(state.Files)
+		}
+		f, ok := cursors[d.Path]
+		if !ok {
+			f = SessionFile{Path: d.Path, Line: 1, Column: 1}
+		}
+		state.Files = append(state.Files, f)
+	}
+	return state
+}
@@ -0,0 +1,321 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// themes are the allowed values of Settings.Theme
+var themes = []string{"dark", "light", "high-contrast", "system"}
+
+// Settings are the user preferences persisted between runs
+type Settings struct {
+	FontFamily      string `json:"fontFamily"`
+	FontSize        int    `json:"fontSize"`
+	Theme           string `json:"theme"`
+	TabWidth        int    `json:"tabWidth"`
+	InsertSpaces    bool   `json:"insertSpaces"`
+	WordWrap        bool   `json:"wordWrap"`
+	ShowLineNumbers bool   `json:"showLineNumbers"`
+	// PersistClipboard keeps the clipboard history across restarts. It is
+	// off by default since copies often hold secrets.
+	PersistClipboard bool `json:"persistClipboard"`
+	// LargeFileMB is the size above which files open read-only in
+	// large-file mode
+	LargeFileMB int `json:"largeFileMB"`
+	// BackupMode is none, simple (a .bak next to the file) or versioned
+	// (timestamped copies in the config folder, the newest BackupKeep kept)
+	BackupMode string `json:"backupMode"`
+	BackupKeep int    `json:"backupKeep"`
+	// MinimizeToTray hides the window to the tray icon when it is closed
+	MinimizeToTray bool `json:"minimizeToTray"`
+	// GlobalHotkey brings up the window with a new quick note from any
+	// application. Empty turns it off.
+	GlobalHotkey string `json:"globalHotkey"`
+	// Zoom scales the editor, 1 being the normal size
+	Zoom float64 `json:"zoom"`
+	// SpellSkipCode leaves URLs, paths, camelCase and snake_case words out
+	// of spell checking
+	SpellSkipCode bool `json:"spellSkipCode"`
+	// WatchIgnore are glob patterns of the workspace files and folders
+	// whose changes are not reported
+	WatchIgnore []string `json:"watchIgnore"`
+	// UTF8BOM decides whether UTF-8 files are saved with a byte order mark:
+	// keep does what the file did when opened, always and never override it
+	UTF8BOM string `json:"utf8Bom"`
+	// Author fills {{AUTHOR}} in templates. Empty uses the account's name.
+	Author string `json:"author"`
+	// AlwaysOnTop keeps the window above other windows
+	AlwaysOnTop bool `json:"alwaysOnTop"`
+	// Opacity of the window in percent, where the platform supports it
+	Opacity int `json:"opacity"`
+	// Terminal is the program OpenTerminalHere starts. Empty uses the
+	// platform's usual terminal.
+	Terminal string `json:"terminal"`
+	// ShareURL is the paste service ShareText uploads to, sent as
+	// ShareFormat. ShareAPIKey, when set, goes along as a bearer token.
+	ShareURL    string `json:"shareUrl"`
+	ShareFormat string `json:"shareFormat"`
+	ShareAPIKey string `json:"shareApiKey"`
+	// UpdateCheck allows looking for new releases of UpdateRepo, given as
+	// owner/repo on GitHub. Off, nothing about updates is ever fetched.
+	UpdateCheck bool   `json:"updateCheck"`
+	UpdateRepo  string `json:"updateRepo"`
+}
+
+// defaultSettings are used when nothing has been saved yet
+func defaultSettings() Settings {
+	return Settings{
+		FontFamily:      "Consolas, 'Courier New', monospace",
+		FontSize:        14,
+		Theme:           "dark",
+		TabWidth:        4,
+		InsertSpaces:    true,
+		WordWrap:        false,
+		ShowLineNumbers: true,
+		LargeFileMB:     defaultLargeFileMB,
+		BackupMode:      "none",
+		BackupKeep:      defaultBackupKeep,
+		GlobalHotkey:    defaultHotkey,
+		Zoom:            defaultZoom,
+		SpellSkipCode:   true,
+		WatchIgnore:     append([]string{}, defaultWatchIgnore...),
+		UTF8BOM:         "keep",
+		Opacity:         defaultOpacity,
+		ShareURL:        defaultShareURL,
+		ShareFormat:     "raw",
+		UpdateCheck:     true,
+		UpdateRepo:      defaultUpdateRepo,
+	}
+}
+
+// validate returns a problem description per invalid field, keyed by its
+// JSON name
+func (s Settings) validate() map[string]string {
+	problems := map[string]string{}
+	if strings.TrimSpace(s.FontFamily) == "" {
+		problems["fontFamily"] = "must not be empty"
+	}
+	if s.FontSize < 6 || s.FontSize > 72 {
+		problems["fontSize"] = "must be between 6 and 72"
+	}
+	if !contains(themes, s.Theme) {
+		problems["theme"] = "must be one of " + strings.Join(themes, ", ")
+	}
+	if s.TabWidth < 1 || s.TabWidth > 16 {
+		problems["tabWidth"] = "must be between 1 and 16"
+	}
+	if s.LargeFileMB < 1 || s.LargeFileMB > 512 {
+		problems["largeFileMB"] = "must be between 1 and 512"
+	}
+	if !contains(backupModes, s.BackupMode) {
+		problems["backupMode"] = "must be one of " + strings.Join(backupModes, ", ")
+	}
+	if s.BackupKeep < 1 || s.BackupKeep > 1000 {
+		problems["backupKeep"] = "must be between 1 and 1000"
+	}
+	if s.GlobalHotkey != "" {
+		if _, err := parseHotkey(s.GlobalHotkey); err != nil {
+			problems["globalHotkey"] = err.Error()
+		}
+	}
+	for _, p := range s.WatchIgnore {
+		if _, err := path.Match(p, ""); err != nil || strings.TrimSpace(p) == "" {
+			problems["watchIgnore"] = fmt.Sprintf("invalid pattern %q", p)
+		}
+	}
+	if !contains(bomModes, s.UTF8BOM) {
+		problems["utf8Bom"] = "must be one of " + strings.Join(bomModes, ", ")
+	}
+	if s.Opacity < minOpacity || s.Opacity > maxOpacity {
+		problems["opacity"] = fmt.Sprintf("must be between %d and %d", minOpacity, maxOpacity)
+	}
+	if err := validShareURL(s.ShareURL); err != nil {
+		problems["shareUrl"] = err.Error()
+	}
+	if !contains(shareFormats, s.ShareFormat) {
+		problems["shareFormat"] = "must be one of " + strings.Join(shareFormats, ", ")
+	}
+	if err := validUpdateRepo(s.UpdateRepo); err != nil {
+		problems["updateRepo"] = err.Error()
+	}
+	if clampZoom(s.Zoom) != s.Zoom {
+		problems["zoom"] = "must be between 0.5 and 3.0 in steps of 0.1"
+	}
+	return problems
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// settingsError lists every field rejected by UpdateSettings
+func settingsError(problems map[string]string) error {
+	fields := make([]string, 0, len(problems))
+	for f := range problems {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+	msgs := make([]string, len(fields))
+	for i, f := range fields {
+		msgs[i] = f + ": " + problems[f]
+	}
+	return errors.New("invalid settings: " + strings.Join(msgs, "; "))
+}
+
+// applyPatch returns s with the JSON-named fields in patch replaced
+func (s Settings) applyPatch(patch map[string]any) (Settings, error) {
+	raw, err := json.Marshal(s)
+	if err != nil {
+		return s, err
+	}
+	var known map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &known); err != nil {
+		return s, err
+	}
+
+	next := s
+	problems := map[string]string{}
+	for key, value := range patch {
+		if _, ok := known[key]; !ok {
+			problems[key] = "unknown setting"
+			continue
+		}
+		field, err := json.Marshal(map[string]any{key: value})
+		if err != nil {
+			problems[key] = err.Error()
+			continue
+		}
+		if err := json.Unmarshal(field, &next); err != nil {
+			problems[key] = "wrong type"
+		}
+	}
+	for key, problem := range next.validate() {
+		if _, patched := patch[key]; patched {
+			problems[key] = problem
+		}
+	}
+	if len(problems) > 0 {
+		return s, settingsError(problems)
+	}
+	return next, nil
+}
+
+// loadSettings reads the saved settings. A file that cannot be used is set
+// aside as a timestamped backup and the defaults are returned instead.
+func loadSettings() Settings {
+	defaults := defaultSettings()
+	path, err := configPath("settings.json")
+	if err != nil {
+		return defaults
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return defaults
+	}
+	s := defaults
+	if err := json.Unmarshal(data, &s); err != nil || len(s.validate()) > 0 {
+		os.Rename(path, fmt.Sprintf("%s.corrupt-%s", path, time.Now().Format("20060102-150405")))
+		return defaults
+	}
+	return s
+}
+
+// saveSettings persists s. The file is private to the user since it holds
+// ShareAPIKey.
+func saveSettings(s Settings) error {
+	path, err := configPath("settings.json")
+	if err != nil {
+		return err
+	}
+	// writeFile keeps the mode of an existing file, and older versions left
+	// settings readable by everyone, so that is narrowed before the key goes in
+	if info, err := os.Stat(path); err == nil && info.Mode().Perm()&0o077 != 0 {
+		if err := os.Chmod(path, 0o600); err != nil {
+			return err
+		}
+	}
+	return writeJSON(path, s, 0o600)
+}
+
+// GetSettings returns the current user settings
+func (a *App) GetSettings() Settings {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.settings
+}
+
+// UpdateSettings applies the fields in patch, keyed by their JSON names,
+// and saves the result. Nothing is changed if any field is unknown or
+// invalid.
+func (a *App) UpdateSettings(patch map[string]any) (Settings, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	next, err := a.settings.applyPatch(patch)
+	if err != nil {
+		return a.settings, err
+	}
+	prev := a.settings
+	if next.GlobalHotkey != prev.GlobalHotkey {
+		// like SetGlobalHotkey, a hotkey that cannot be registered is not
+		// kept, though the rest of the patch still is
+		if err := a.registerHotkeyLocked(next.GlobalHotkey); err != nil {
+			a.log.Warn("global hotkey not registered", "hotkey", next.GlobalHotkey, "err", err)
+			runtime.EventsEmit(a.ctx, "hotkey:error", hotkeyError(next.GlobalHotkey, err))
+			next.GlobalHotkey = prev.GlobalHotkey
+		}
+	}
+	if err := saveSettings(next); err != nil {
+		if next.GlobalHotkey != prev.GlobalHotkey {
+			a.registerHotkeyLocked(prev.GlobalHotkey)
+		}
+		return a.settings, err
+	}
+	a.settings = next
+	a.settingsChangedLocked(prev, next)
+	return next, nil
+}
+
+// settingsChangedLocked applies the side effects of a settings change.
+// a.mu must be held.
+func (a *App) settingsChangedLocked(prev, next Settings) {
+	if prev.PersistClipboard != next.PersistClipboard {
+		if next.PersistClipboard {
+			saveClipboardHistory(a.clipboard)
+		} else {
+			removeClipboardHistory()
+		}
+	}
+	if prev.WordWrap != next.WordWrap || prev.AlwaysOnTop != next.AlwaysOnTop || prev.Opacity != next.Opacity {
+		// the menu reads the settings, so it is rebuilt once a.mu is free
+		go a.RefreshMenu()
+	}
+	if prev.AlwaysOnTop != next.AlwaysOnTop || prev.Opacity != next.Opacity {
+		go a.applyWindowSettings()
+	}
+	if !slices.Equal(prev.WatchIgnore, next.WatchIgnore) {
+		// rewatching walks the whole workspace, which is not done under a.mu
+		go a.watchTree()
+	}
+	if prev.UpdateCheck && !next.UpdateCheck {
+		// turning updates off also drops one found or on its way
+		if a.updateCancel != nil {
+			a.updateCancel()
+		}
+		a.clearUpdateLocked()
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestSaveSettingsPrivate(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("the config directory is only redirected through XDG_CONFIG_HOME on linux")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	path, err := configPath("settings.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// as left behind by a version that wrote settings world readable
+	if err := os.WriteFile(path, []byte("{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := defaultSettings()
+	s.ShareAPIKey = "secret"
+	if err := saveSettings(s); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := info.Mode().Perm(); got != 0o600 {
+		t.Errorf("settings perm = %o, want 600", got)
+	}
+}
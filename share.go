@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// defaultShareURL is a paste service that takes the text as the body
+	defaultShareURL = "https://paste.rs/"
+	// shareTimeout bounds a whole upload, response included
+	shareTimeout = 30 * time.Second
+	// shareExcerpt is how much of a failed upload's response is reported
+	shareExcerpt = 200
+)
+
+// shareFormats are the allowed values of Settings.ShareFormat: raw posts
+// the text as the request body, like paste.rs, and form uploads it as a
+// file field, like 0x0.st
+var shareFormats = []string{"raw", "form"}
+
+// shareClient gives up on a paste service that does not answer
+var shareClient = &http.Client{Timeout: shareTimeout}
+
+// shareVisibilities are the allowed values of ShareOptions.Visibility
+var shareVisibilities = []string{"", "public", "unlisted"}
+
+// ShareOptions are the optional parts of a ShareText upload
+type ShareOptions struct {
+	// Expiry is how long the paste should live, like "24h". Empty keeps
+	// the service's default.
+	Expiry string `json:"expiry,omitempty"`
+	// Visibility is public, or unlisted for a URL that is hard to guess
+	Visibility string `json:"visibility,omitempty"`
+	// Filename is sent to services that use its extension for highlighting
+	Filename string `json:"filename,omitempty"`
+}
+
+// ShareText uploads content to the paste service in the settings, copies
+// the URL it is given to the clipboard and returns it. Expiry and
+// visibility are only sent with the form format; asking for them from a raw
+// service is an error rather than a paste that outlives what was asked.
+func (a *App) ShareText(content string, opts ShareOptions) (string, error) {
+	if content == "" {
+		return "", errors.New("nothing to share")
+	}
+	if !contains(shareVisibilities, opts.Visibility) {
+		return "", fmt.Errorf("unknown visibility %q, expected public or unlisted", opts.Visibility)
+	}
+	var expiry time.Duration
+	if opts.Expiry != "" {
+		d, err := time.ParseDuration(opts.Expiry)
+		if err != nil || d <= 0 {
+			return "", fmt.Errorf("invalid expiry %q, expected a duration like 24h", opts.Expiry)
+		}
+		expiry = d
+	}
+
+	a.mu.Lock()
+	if a.shareCancel != nil {
+		a.mu.Unlock()
+		return "", errors.New("an upload is already in progress")
+	}
+	settings := a.settings
+	parent := a.bg
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithCancel(parent)
+	a.shareCancel = cancel
+	a.mu.Unlock()
+	defer func() {
+		a.mu.Lock()
+		a.shareCancel = nil
+		a.mu.Unlock()
+		cancel()
+	}()
+
+	req, err := shareRequest(ctx, settings, content, opts, expiry)
+	if err != nil {
+		return "", err
+	}
+	a.log.Info("sharing text", "service", req.URL.Host, "bytes", len(content))
+	resp, err := shareClient.Do(req)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.Canceled) {
+			return "", ErrCancelled
+		}
+		a.log.Warn("share failed", "service", req.URL.Host, "err", err)
+		return "", fmt.Errorf("uploading to %s failed: %w", req.URL.Host, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return "", fmt.Errorf("reading the reply from %s failed: %w", req.URL.Host, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		a.log.Warn("share rejected", "service", req.URL.Host, "status", resp.Status)
+		return "", fmt.Errorf("%s refused the upload with %s: %s", req.URL.Host, resp.Status, excerpt(body))
+	}
+	link := strings.TrimSpace(string(body))
+	if u, err := url.Parse(link); err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+		return "", fmt.Errorf("%s did not reply with a URL: %s", req.URL.Host, excerpt(body))
+	}
+	if err := a.CopyToClipboard(link); err != nil {
+		a.log.Warn("copying the share URL failed", "err", err)
+	}
+	return link, nil
+}
+
+// CancelShare stops the upload ShareText is waiting on
+func (a *App) CancelShare() {
+	a.mu.Lock()
+	cancel := a.shareCancel
+	a.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+}
+
+// shareRequest builds the upload of content in the service's format
+func shareRequest(ctx context.Context, s Settings, content string, opts ShareOptions, expiry time.Duration) (*http.Request, error) {
+	var body bytes.Buffer
+	contentType := "text/plain; charset=utf-8"
+	switch s.ShareFormat {
+	case "form":
+		w := multipart.NewWriter(&body)
+		name := opts.Filename
+		if name == "" {
+			name = "paste.txt"
+		}
+		f, err := w.CreateFormFile("file", name)
+		if err != nil {
+			return nil, err
+		}
+		io.WriteString(f, content)
+		if expiry > 0 {
+			// 0x0.st takes hours, rounded up so a paste never ends early
+			hours := (expiry + time.Hour - 1) / time.Hour
+			w.WriteField("expires", strconv.Itoa(int(hours)))
+		}
+		if opts.Visibility == "unlisted" {
+			w.WriteField("secret", "")
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		contentType = w.FormDataContentType()
+	default:
+		if expiry > 0 || opts.Visibility == "unlisted" {
+			return nil, errors.New("the paste service takes no expiry or visibility, switch to the form format for those")
+		}
+		body.WriteString(content)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.ShareURL, &body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("User-Agent", "wailspad")
+	if s.ShareAPIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+s.ShareAPIKey)
+	}
+	return req, nil
+}
+
+// excerpt returns the start of a response body for an error message
+func excerpt(body []byte) string {
+	text := strings.TrimSpace(string(body))
+	if text == "" {
+		return "(empty reply)"
+	}
+	if len(text) > shareExcerpt {
+		text = truncateUTF8(text, shareExcerpt) + "..."
+	}
+	if !utf8.ValidString(text) {
+		return "(binary reply)"
+	}
+	return text
+}
+
+// validShareURL checks the paste service URL of the settings
+func validShareURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return errors.New("must be an http or https URL")
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// shutdownBudget bounds how long shutdown may take. Steps still to run
+// when it is spent are skipped so a stuck disk cannot hang the exit.
+const shutdownBudget = 3 * time.Second
+
+// shutdownStep is one part of shutdown
+type shutdownStep struct {
+	name string
+	run  func()
+}
+
+// shutdown is called when the app is closing. In order of priority it stops
+// background work, writes the session, autosaves unsaved documents, writes
+// the settings and installs a staged update, skipping what is left once
+// shutdownBudget is spent. Only the first call does anything.
+func (a *App) shutdown(ctx context.Context) {
+	a.shutdownOnce.Do(func() {
+		done, skipped := runShutdownSteps(a.shutdownSteps(), time.After(shutdownBudget))
+		if len(skipped) > 0 {
+			a.log.Warn("shutdown ran out of time", "done", done, "skipped", skipped)
+		}
+		a.log.Info("shut down")
+		a.logs.close()
+	})
+}
+
+// shutdownSteps lists the work of shutdown, most important first
+func (a *App) shutdownSteps() []shutdownStep {
+	return []shutdownStep{
+		{"background", func() {
+			a.stopTray()
+			a.mu.Lock()
+			a.registerHotkeyLocked("")
+			a.stopTreeWatchLocked()
+			for _, d := range a.docs {
+				d.stopWatcher()
+			}
+			a.mu.Unlock()
+			if a.cancel != nil {
+				a.cancel()
+			}
+			a.wg.Wait()
+		}},
+		{"session", func() {
+			if err := writeSession(a.currentSession()); err != nil {
+				a.log.Error("writing the session failed", "err", err)
+			}
+		}},
+		{"autosave", func() {
+			a.autosave()
+			a.flushScratches()
+			// recovery files are kept only for what is still unsaved
+			a.mu.Lock()
+			defer a.mu.Unlock()
+			for _, d := range a.docs {
+				d.index.close()
+				if !d.Dirty {
+					removeRecovery(d.recoveryKey())
+				}
+			}
+		}},
+		{"settings", func() {
+			a.mu.Lock()
+			defer a.mu.Unlock()
+			if err := saveSettings(a.settings); err != nil {
+				a.log.Error("writing the settings failed", "err", err)
+			}
+		}},
+		{"update", a.installUpdate},
+	}
+}
+
+// runShutdownSteps runs steps one after another until expired fires, and
+// returns the names of the steps finished and of those not run. A step
+// that is still running then is counted as skipped and left to itself.
+func runShutdownSteps(steps []shutdownStep, expired <-chan time.Time) (done, skipped []string) {
+	for i, step := range steps {
+		finished := make(chan struct{})
+		go func() {
+			defer close(finished)
+			step.run()
+		}()
+		select {
+		case <-finished:
+			done = append(done, step.name)
+		case <-expired:
+			for _, s := range steps[i:] {
+				skipped = append(skipped, s.name)
+			}
+			return done, skipped
+		}
+	}
+	return done, nil
+}
+
+// watchSignals quits the app on SIGINT or SIGTERM, as when it was started
+// from a terminal, without asking about unsaved changes. Should the window
+// not close, a second signal runs shutdown and exits directly.
+func (a *App) watchSignals(ctx context.Context) {
+	sig := make(chan os.Signal, 2)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sig)
+	select {
+	case <-ctx.Done():
+		return
+	case s := <-sig:
+		a.log.Info("quitting on signal", "signal", s.String())
+		a.mu.Lock()
+		a.signalled = true
+		a.mu.Unlock()
+		// quitting waits on shutdown, which waits on this goroutine
+		go a.quit()
+	}
+	select {
+	case <-ctx.Done():
+	case s := <-sig:
+		a.log.Warn("exiting on second signal", "signal", s.String())
+		go func() {
+			a.shutdown(a.ctx)
+			os.Exit(1)
+		}()
+	}
+}
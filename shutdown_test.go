@@ -0,0 +1,60 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRunShutdownSteps(t *testing.T) {
+	tests := []struct {
+		name string
+		// stuck is the step that does not finish until the test is over, or
+		// "" when all of them finish
+		stuck       string
+		expire      bool // fire the budget once the stuck step is running
+		wantDone    []string
+		wantSkipped []string
+	}{
+		{"all in time", "", false, []string{"background", "session", "autosave", "settings"}, nil},
+		{"out of time in the middle", "autosave", true, []string{"background", "session"}, []string{"autosave", "settings"}},
+		{"out of time first", "background", true, nil, []string{"background", "session", "autosave", "settings"}},
+		{"out of time last", "settings", true, []string{"background", "session", "autosave"}, []string{"settings"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expired := make(chan time.Time)
+			release := make(chan struct{})
+			defer close(release)
+			var ran []string
+			step := func(name string) shutdownStep {
+				return shutdownStep{name, func() {
+					ran = append(ran, name)
+					if name == tt.stuck {
+						if tt.expire {
+							go func() { expired <- time.Time{} }()
+						}
+						<-release
+					}
+				}}
+			}
+			steps := []shutdownStep{step("background"), step("session"), step("autosave"), step("settings")}
+
+			done, skipped := runShutdownSteps(steps, expired)
+			if !slices.Equal(done, tt.wantDone) {
+				t.Errorf("done = %q, want %q", done, tt.wantDone)
+			}
+			if !slices.Equal(skipped, tt.wantSkipped) {
+				t.Errorf("skipped = %q, want %q", skipped, tt.wantSkipped)
+			}
+			// steps run in order and none is started after the budget is out
+			want := append(slices.Clone(tt.wantDone), tt.wantSkipped...)
+			if tt.stuck != "" {
+				want = want[:len(tt.wantDone)+1]
+			}
+			if !slices.Equal(ran, want) {
+				t.Errorf("ran %q, want %q", ran, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,351 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Snippet is a named piece of text to insert. Its body may hold
+// placeholders: $1, ${1} or ${1:default} for tab stops and $NAME, ${NAME}
+// or ${NAME:default} for variables. \$, \} and \\ escape.
+type Snippet struct {
+	Name string `json:"name"`
+	// Trigger is an abbreviation that expands to the snippet when typed
+	Trigger string `json:"trigger,omitempty"`
+	// Language limits the snippet to documents of that language ID. Empty
+	// means any language.
+	Language    string `json:"language,omitempty"`
+	Description string `json:"description,omitempty"`
+	Body        string `json:"body"`
+}
+
+// snippetVariables are the variables every snippet can use, resolved when
+// the snippet is expanded
+var snippetVariables = map[string]func(a *App) string{
+	"DATE":     func(*App) string { return time.Now().Format("2006-01-02") },
+	"TIME":     func(*App) string { return time.Now().Format("15:04:05") },
+	"YEAR":     func(*App) string { return time.Now().Format("2006") },
+	"FILENAME": func(a *App) string { return a.activeName() },
+	"FILEPATH": func(a *App) string { return a.activePath() },
+	"CLIPBOARD": func(a *App) string {
+		text, _ := runtime.ClipboardGetText(a.ctx)
+		return text
+	},
+}
+
+// ListSnippets returns the saved snippets by name
+func (a *App) ListSnippets() []Snippet {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	snippets, err := loadSnippets()
+	if err != nil {
+		return []Snippet{}
+	}
+	return snippets
+}
+
+// SaveSnippet stores s, replacing the snippet of the same name
+func (a *App) SaveSnippet(s Snippet) error {
+	s.Name = strings.TrimSpace(s.Name)
+	s.Trigger = strings.TrimSpace(s.Trigger)
+	if s.Name == "" {
+		return errors.New("a snippet needs a name")
+	}
+	if strings.IndexFunc(s.Trigger, unicode.IsSpace) >= 0 {
+		return fmt.Errorf("trigger %q must be a single word", s.Trigger)
+	}
+	if _, err := parseSnippet(s.Body); err != nil {
+		return err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	snippets, err := loadSnippets()
+	if err != nil {
+		return err
+	}
+	i := findSnippet(snippets, s.Name)
+	if i >= 0 {
+		snippets[i] = s
+	} else {
+		snippets = append(snippets, s)
+	}
+	return saveSnippets(snippets)
+}
+
+// DeleteSnippet removes the snippet called name
+func (a *App) DeleteSnippet(name string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	snippets, err := loadSnippets()
+	if err != nil {
+		return err
+	}
+	i := findSnippet(snippets, name)
+	if i < 0 {
+		return fmt.Errorf("no snippet %q", name)
+	}
+	return saveSnippets(append(snippets[:i], snippets[i+1:]...))
+}
+
+// ExpandSnippet returns the body of a snippet with its placeholders filled
+// in. vars supplies tab stops by number and variables by name, taking
+// precedence over the built-in variables, and may refer to other
+// placeholders itself. A placeholder with no value takes its default, or
+// nothing. Circular references are an error.
+func (a *App) ExpandSnippet(name string, vars map[string]string) (string, error) {
+	a.mu.Lock()
+	snippets, err := loadSnippets()
+	a.mu.Unlock()
+	if err != nil {
+		return "", err
+	}
+	i := findSnippet(snippets, name)
+	if i < 0 {
+		return "", fmt.Errorf("no snippet %q", name)
+	}
+	nodes, err := parseSnippet(snippets[i].Body)
+	if err != nil {
+		return "", err
+	}
+	e := &snippetExpander{app: a, vars: vars, parsed: map[string][]snippetNode{}}
+	var b strings.Builder
+	if err := e.expand(&b, nodes); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// activeName returns the name of the active document, or "" when none is
+// open
+func (a *App) activeName() string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if d, ok := a.docs[a.active]; ok {
+		return d.Name()
+	}
+	return ""
+}
+
+// activePath returns the path of the active document, or ""
+func (a *App) activePath() string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if d, ok := a.docs[a.active]; ok {
+		return d.Path
+	}
+	return ""
+}
+
+// snippetNode is literal text, or a placeholder when name is set
+type snippetNode struct {
+	text     string
+	name     string
+	fallback []snippetNode
+}
+
+// parseSnippet splits a snippet body into text and placeholders
+func parseSnippet(body string) ([]snippetNode, error) {
+	p := &snippetParser{src: []rune(body)}
+	nodes, err := p.parse(false)
+	if err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
+
+type snippetParser struct {
+	src []rune
+	pos int
+}
+
+// parse reads nodes up to the end of the body, or up to the } closing a
+// default when inDefault is set
+func (p *snippetParser) parse(inDefault bool) ([]snippetNode, error) {
+	var nodes []snippetNode
+	var text []rune
+	flush := func() {
+		if len(text) > 0 {
+			nodes = append(nodes, snippetNode{text: string(text)})
+			text = nil
+		}
+	}
+	for p.pos < len(p.src) {
+		r := p.src[p.pos]
+		switch {
+		case r == '\\' && p.pos+1 < len(p.src) && strings.ContainsRune(`$}\`, p.src[p.pos+1]):
+			text = append(text, p.src[p.pos+1])
+			p.pos += 2
+		case r == '}' && inDefault:
+			flush()
+			return nodes, nil
+		case r == '$':
+			n, ok, err := p.placeholder()
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				text = append(text, r)
+				p.pos++
+				continue
+			}
+			flush()
+			nodes = append(nodes, n)
+		default:
+			text = append(text, r)
+			p.pos++
+		}
+	}
+	if inDefault {
+		return nil, errors.New("snippet has an unclosed ${")
+	}
+	flush()
+	return nodes, nil
+}
+
+// placeholder reads the placeholder at a $. A $ not followed by a name is
+// plain text and reported as not ok.
+func (p *snippetParser) placeholder() (snippetNode, bool, error) {
+	start := p.pos
+	p.pos++ // the $
+	braced := p.pos < len(p.src) && p.src[p.pos] == '{'
+	if braced {
+		p.pos++
+	}
+	name := p.name()
+	if name == "" {
+		if braced {
+			return snippetNode{}, false, fmt.Errorf("snippet has a placeholder without a name at %d", start)
+		}
+		p.pos = start
+		return snippetNode{}, false, nil
+	}
+	n := snippetNode{name: name}
+	if !braced {
+		return n, true, nil
+	}
+	if p.pos < len(p.src) && p.src[p.pos] == ':' {
+		p.pos++
+		fallback, err := p.parse(true)
+		if err != nil {
+			return snippetNode{}, false, err
+		}
+		n.fallback = fallback
+	}
+	if p.pos >= len(p.src) || p.src[p.pos] != '}' {
+		return snippetNode{}, false, errors.New("snippet has an unclosed ${")
+	}
+	p.pos++
+	return n, true, nil
+}
+
+// name reads a tab stop number or a variable name
+func (p *snippetParser) name() string {
+	start := p.pos
+	if p.pos < len(p.src) && unicode.IsDigit(p.src[p.pos]) {
+		for p.pos < len(p.src) && unicode.IsDigit(p.src[p.pos]) {
+			p.pos++
+		}
+		return string(p.src[start:p.pos])
+	}
+	for p.pos < len(p.src) {
+		r := p.src[p.pos]
+		if r != '_' && !unicode.IsLetter(r) && !(p.pos > start && unicode.IsDigit(r)) {
+			break
+		}
+		p.pos++
+	}
+	return string(p.src[start:p.pos])
+}
+
+// snippetExpander fills in placeholders, tracking which vars are being
+// expanded to catch cycles
+type snippetExpander struct {
+	app    *App
+	vars   map[string]string
+	parsed map[string][]snippetNode
+	stack  []string
+}
+
+func (e *snippetExpander) expand(b *strings.Builder, nodes []snippetNode) error {
+	for _, n := range nodes {
+		if n.name == "" {
+			b.WriteString(n.text)
+			continue
+		}
+		value, ok := e.vars[n.name]
+		if !ok {
+			if builtin := snippetVariables[n.name]; builtin != nil {
+				// built-in values are inserted as they are
+				b.WriteString(builtin(e.app))
+				continue
+			}
+			if err := e.expand(b, n.fallback); err != nil {
+				return err
+			}
+			continue
+		}
+		for i, s := range e.stack {
+			if s == n.name {
+				cycle := append(append([]string{}, e.stack[i:]...), n.name)
+				return fmt.Errorf("circular snippet variables: %s", strings.Join(cycle, " -> "))
+			}
+		}
+		parsed, ok := e.parsed[n.name]
+		if !ok {
+			var err error
+			if parsed, err = parseSnippet(value); err != nil {
+				return fmt.Errorf("variable %s: %w", n.name, err)
+			}
+			e.parsed[n.name] = parsed
+		}
+		e.stack = append(e.stack, n.name)
+		err := e.expand(b, parsed)
+		e.stack = e.stack[:len(e.stack)-1]
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findSnippet returns the index of the snippet called name, or -1
+func findSnippet(snippets []Snippet, name string) int {
+	for i, s := range snippets {
+		if s.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// loadSnippets reads the saved snippets, sorted by name
+func loadSnippets() ([]Snippet, error) {
+	path, err := configPath("snippets.json")
+	if err != nil {
+		return nil, err
+	}
+	snippets := []Snippet{}
+	if err := readJSON(path, &snippets); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	sort.Slice(snippets, func(i, j int) bool {
+		return strings.ToLower(snippets[i].Name) < strings.ToLower(snippets[j].Name)
+	})
+	return snippets, nil
+}
+
+// saveSnippets persists snippets
+func saveSnippets(snippets []Snippet) error {
+	path, err := configPath("snippets.json")
+	if err != nil {
+		return err
+	}
+	return writeJSON(path, snippets, 0o644)
+}
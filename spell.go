@@ -0,0 +1,862 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+// defaultDictionary is used when no language is given
+const defaultDictionary = "en_US"
+
+// maxSuggestions caps what SuggestCorrections returns
+const maxSuggestions = 8
+
+// maxEdits is the most single edits of a word that are taken a second edit
+// further when looking for suggestions
+const maxEdits = 1000
+
+// bundledDictionaries ship with the app. go generate puts the SCOWL
+// hunspell en_US dictionary of the release named in fetch_dictionaries.go
+// in place of the starter list, with README_en_US.txt carrying its license
+// notice. Dictionaries of the same name in the dictionaries config folder
+// take the place of the bundled ones.
+//
+//go:generate go run fetch_dictionaries.go
+//go:embed dictionaries
+var bundledDictionaries embed.FS
+
+// Misspelling is a word CheckSpelling did not find in the dictionary.
+// Offset and Length count runes.
+type Misspelling struct {
+	Word   string `json:"word"`
+	Offset int    `json:"offset"`
+	Length int    `json:"length"`
+}
+
+// spellChecker holds the loaded dictionaries and the user's own words
+type spellChecker struct {
+	mu    sync.Mutex
+	dicts map[string]*dictionary
+	user  map[string]bool // nil until loaded
+}
+
+func newSpellChecker() *spellChecker {
+	return &spellChecker{dicts: make(map[string]*dictionary)}
+}
+
+// CheckSpelling returns the words in text missing from the lang dictionary
+// and the user dictionary. Nothing is reported for a language with no
+// dictionary.
+func (a *App) CheckSpelling(text string, lang string) []Misspelling {
+	a.mu.Lock()
+	skipCode := a.settings.SpellSkipCode
+	a.mu.Unlock()
+	d, user := a.spell.load(lang)
+	if d == nil {
+		return nil
+	}
+	out := []Misspelling{}
+	eachWord(text, skipCode, func(word string, offset, length int) {
+		if !d.check(word) && !checkUser(user, word) {
+			out = append(out, Misspelling{Word: word, Offset: offset, Length: length})
+		}
+	})
+	return out
+}
+
+// SuggestCorrections returns likely spellings of word, best first
+func (a *App) SuggestCorrections(word string, lang string) []string {
+	d, user := a.spell.load(lang)
+	if d == nil {
+		return []string{}
+	}
+	return d.suggest(strings.TrimSpace(word), user)
+}
+
+// ListDictionaries returns the languages a dictionary is available for,
+// bundled or found in the dictionaries config folder
+func (a *App) ListDictionaries() []string {
+	seen := map[string]bool{}
+	add := func(names []string) {
+		for _, n := range names {
+			seen[n] = true
+		}
+	}
+	if entries, err := fs.ReadDir(bundledDictionaries, "dictionaries"); err == nil {
+		add(dictionaryNames(entries))
+	}
+	if dir, err := configPath("dictionaries"); err == nil {
+		if entries, err := os.ReadDir(dir); err == nil {
+			add(dictionaryNames(entries))
+		}
+	}
+	langs := make([]string, 0, len(seen))
+	for n := range seen {
+		langs = append(langs, n)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// AddToUserDictionary stops word from being reported in any language
+func (a *App) AddToUserDictionary(word string) error {
+	word = strings.TrimSpace(word)
+	if word == "" || strings.IndexFunc(word, unicode.IsSpace) >= 0 {
+		return fmt.Errorf("%q is not a single word", word)
+	}
+	return a.spell.addUser(word)
+}
+
+// dictionaryNames returns the names with both a .dic and an .aff file
+func dictionaryNames(entries []fs.DirEntry) []string {
+	files := map[string]bool{}
+	for _, e := range entries {
+		files[e.Name()] = true
+	}
+	var names []string
+	for name := range files {
+		if base, ok := strings.CutSuffix(name, ".dic"); ok && files[base+".aff"] {
+			names = append(names, base)
+		}
+	}
+	return names
+}
+
+// load returns the dictionary for lang, reading it on first use, and the
+// user dictionary. The dictionary is nil when lang has none.
+func (s *spellChecker) load(lang string) (*dictionary, map[string]bool) {
+	lang = strings.ReplaceAll(strings.TrimSpace(lang), "-", "_")
+	if lang == "" {
+		lang = defaultDictionary
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.user == nil {
+		s.user = loadUserDictionary()
+	}
+	d, ok := s.dicts[lang]
+	if !ok {
+		d, _ = openDictionary(lang)
+		// a failed load is remembered too, so it is not retried per check
+		s.dicts[lang] = d
+	}
+	return d, s.user
+}
+
+func (s *spellChecker) addUser(word string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.user == nil {
+		s.user = loadUserDictionary()
+	}
+	if s.user[word] {
+		return nil
+	}
+	path, err := configPath("dictionary.txt")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(word + "\n"); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	// a copy, so callers still reading the old map are not raced
+	user := make(map[string]bool, len(s.user)+1)
+	for w := range s.user {
+		user[w] = true
+	}
+	user[word] = true
+	s.user = user
+	return nil
+}
+
+// loadUserDictionary reads the user's words, one per line
+func loadUserDictionary() map[string]bool {
+	words := map[string]bool{}
+	path, err := configPath("dictionary.txt")
+	if err != nil {
+		return words
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return words
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if w := strings.TrimSpace(line); w != "" {
+			words[w] = true
+		}
+	}
+	return words
+}
+
+// checkUser looks word up in the user dictionary, with the same case
+// allowances as dictionary.check
+func checkUser(user map[string]bool, word string) bool {
+	for _, w := range caseVariants(word) {
+		if user[w] {
+			return true
+		}
+	}
+	return false
+}
+
+// openDictionary reads lang.aff and lang.dic, preferring the config folder
+// over the bundled copies
+func openDictionary(lang string) (*dictionary, error) {
+	if strings.ContainsAny(lang, `/\.`) {
+		return nil, fmt.Errorf("invalid dictionary name %q", lang)
+	}
+	read := func(name string) ([]byte, error) {
+		if dir, err := configPath("dictionaries"); err == nil {
+			if data, err := os.ReadFile(filepath.Join(dir, name)); err == nil {
+				return data, nil
+			}
+		}
+		return bundledDictionaries.ReadFile("dictionaries/" + name)
+	}
+	aff, err := read(lang + ".aff")
+	if err != nil {
+		return nil, err
+	}
+	dic, err := read(lang + ".dic")
+	if err != nil {
+		return nil, err
+	}
+	return parseDictionary(aff, dic)
+}
+
+// eachWord calls fn with every word in text worth checking and its rune
+// offset and length. Words with digits are never checked, and with
+// skipCode neither are URLs, e-mail addresses, paths, camelCase or
+// snake_case identifiers.
+func eachWord(text string, skipCode bool, fn func(word string, offset, length int)) {
+	offset := 0 // in runes
+	for len(text) > 0 {
+		// whitespace separated chunks, so a URL can be skipped as a whole
+		n := 0
+		for n < len(text) {
+			r, size := utf8.DecodeRuneInString(text[n:])
+			if !unicode.IsSpace(r) {
+				break
+			}
+			n += size
+			offset++
+		}
+		text = text[n:]
+		end := strings.IndexFunc(text, unicode.IsSpace)
+		if end < 0 {
+			end = len(text)
+		}
+		chunk := text[:end]
+		text = text[end:]
+		if skipCode && codeChunk(chunk) {
+			offset += utf8.RuneCountInString(chunk)
+			continue
+		}
+		chunkWords(chunk, offset, skipCode, fn)
+		offset += utf8.RuneCountInString(chunk)
+	}
+}
+
+// codeChunk reports whether a whitespace separated chunk is a URL, e-mail
+// address or path rather than prose
+func codeChunk(chunk string) bool {
+	return strings.Contains(chunk, "://") ||
+		strings.HasPrefix(strings.ToLower(chunk), "www.") ||
+		strings.Contains(strings.Trim(chunk, "@"), "@") ||
+		strings.Count(chunk, "/") > 1 || strings.Contains(chunk, `\`)
+}
+
+// chunkWords splits a chunk into words: runs of letters, digits and
+// underscores, with apostrophes allowed between letters
+func chunkWords(chunk string, offset int, skipCode bool, fn func(word string, offset, length int)) {
+	runes := []rune(chunk)
+	isWord := func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsMark(r) || unicode.IsDigit(r) || r == '_'
+	}
+	for i := 0; i < len(runes); {
+		if !isWord(runes[i]) {
+			i++
+			continue
+		}
+		start := i
+		for i < len(runes) && (isWord(runes[i]) || isApostrophe(runes[i]) && i+1 < len(runes) && unicode.IsLetter(runes[i+1]) && i > start) {
+			i++
+		}
+		word := runes[start:i]
+		if len(word) > 1 && !skipWord(word, skipCode) {
+			fn(string(word), offset+start, len(word))
+		}
+	}
+}
+
+func isApostrophe(r rune) bool {
+	return r == '\'' || r == '’'
+}
+
+// skipWord reports whether a word is not to be spell checked
+func skipWord(word []rune, skipCode bool) bool {
+	for i, r := range word {
+		if unicode.IsDigit(r) {
+			return true
+		}
+		if !skipCode {
+			continue
+		}
+		if r == '_' {
+			return true
+		}
+		// camelCase: a capital after a lower case letter
+		if i > 0 && unicode.IsUpper(r) && unicode.IsLower(word[i-1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// caseVariants returns the spellings a word may be listed under: itself,
+// "Hello" as "hello", and "HELLO" as "hello" or "Hello"
+func caseVariants(word string) []string {
+	word = strings.ReplaceAll(word, "’", "'")
+	variants := []string{word}
+	first, size := utf8.DecodeRuneInString(word)
+	rest := word[size:]
+	switch {
+	case word == strings.ToUpper(word) && word != strings.ToLower(word):
+		lower := strings.ToLower(word)
+		variants = append(variants, lower, titleCase(lower))
+	case unicode.IsUpper(first) && rest == strings.ToLower(rest):
+		variants = append(variants, strings.ToLower(word))
+	}
+	return variants
+}
+
+func titleCase(word string) string {
+	first, size := utf8.DecodeRuneInString(word)
+	return string(unicode.ToUpper(first)) + word[size:]
+}
+
+// dictionary is a hunspell dictionary with every affixed form expanded up
+// front, which keeps lookups to a map access. Compounding and morphology
+// are not supported.
+type dictionary struct {
+	words     map[string]bool
+	try       string      // letters to try when suggesting, most likely first
+	rep       [][2]string // common misspellings, from what to what
+	noSuggest map[string]bool
+}
+
+// check reports whether word is spelt correctly
+func (d *dictionary) check(word string) bool {
+	for _, w := range caseVariants(word) {
+		if d.words[w] {
+			return true
+		}
+	}
+	return false
+}
+
+// suggest returns correctly spelt words close to word: replacements from
+// the REP table first, then words one edit away, then two
+func (d *dictionary) suggest(word string, user map[string]bool) []string {
+	if word == "" {
+		return []string{}
+	}
+	known := func(w string) bool {
+		return (d.check(w) || checkUser(user, w)) && !d.noSuggest[w]
+	}
+	var out []string
+	seen := map[string]bool{word: true}
+	add := func(w string) {
+		if !seen[w] && known(w) && len(out) < maxSuggestions {
+			seen[w] = true
+			out = append(out, w)
+		}
+	}
+
+	upper := word == strings.ToUpper(word) && utf8.RuneCountInString(word) > 1
+	title := !upper && unicode.IsUpper([]rune(word)[0])
+	base := word
+	if upper || title {
+		base = strings.ToLower(word)
+	}
+	recase := func(w string) string {
+		switch {
+		case upper:
+			return strings.ToUpper(w)
+		case title:
+			return titleCase(w)
+		}
+		return w
+	}
+
+	for _, r := range d.rep {
+		for i := strings.Index(base, r[0]); i >= 0; {
+			add(recase(base[:i] + r[1] + base[i+len(r[0]):]))
+			next := strings.Index(base[i+1:], r[0])
+			if next < 0 {
+				break
+			}
+			i += next + 1
+		}
+	}
+	ones := d.edits(base)
+	for _, w := range closest(base, ones, known) {
+		add(recase(w))
+	}
+	// two words run together
+	for i := range base {
+		if i > 0 && len(out) < maxSuggestions && known(base[:i]) && known(base[i:]) {
+			out = append(out, recase(base[:i]+" "+base[i:]))
+		}
+	}
+	if len(out) < 3 && len(ones) < maxEdits {
+		var twos []string
+		for _, one := range ones {
+			twos = append(twos, d.edits(one)...)
+		}
+		for _, w := range closest(base, twos, known) {
+			add(recase(w))
+		}
+	}
+	if out == nil {
+		return []string{}
+	}
+	return out
+}
+
+// closest returns the known candidates, most alike to word first
+func closest(word string, candidates []string, known func(string) bool) []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, c := range candidates {
+		if !seen[c] && known(c) {
+			seen[c] = true
+			out = append(out, c)
+		}
+	}
+	grams, letters := bigrams(word), sortedRunes(word)
+	score := make(map[string]int, len(out))
+	for _, c := range out {
+		for g := range bigrams(c) {
+			if grams[g] {
+				score[c]++
+			}
+		}
+		if sortedRunes(c) == letters {
+			// swapped letters are the likeliest slip of all
+			score[c] += 2
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return score[out[i]] > score[out[j]] })
+	return out
+}
+
+// bigrams returns the pairs of adjacent runes in word
+func bigrams(word string) map[string]bool {
+	runes := []rune(word)
+	grams := make(map[string]bool, len(runes))
+	for i := 0; i+1 < len(runes); i++ {
+		grams[string(runes[i:i+2])] = true
+	}
+	return grams
+}
+
+func sortedRunes(word string) string {
+	runes := []rune(word)
+	sort.Slice(runes, func(i, j int) bool { return runes[i] < runes[j] })
+	return string(runes)
+}
+
+// edits returns every string one deletion, swap, replacement or insertion
+// away from a lower case word, using the dictionary's lower case TRY letters
+func (d *dictionary) edits(word string) []string {
+	runes := []rune(word)
+	var try []rune
+	for _, r := range d.try {
+		if !unicode.IsUpper(r) {
+			try = append(try, r)
+		}
+	}
+	var out []string
+	for i := range runes {
+		if i+1 < len(runes) {
+			swapped := append([]rune{}, runes...)
+			swapped[i], swapped[i+1] = swapped[i+1], swapped[i]
+			out = append(out, string(swapped))
+		}
+		out = append(out, string(runes[:i])+string(runes[i+1:]))
+	}
+	for i := range runes {
+		for _, c := range try {
+			if c != runes[i] {
+				out = append(out, string(runes[:i])+string(c)+string(runes[i+1:]))
+			}
+		}
+	}
+	for i := 0; i <= len(runes); i++ {
+		for _, c := range try {
+			out = append(out, string(runes[:i])+string(c)+string(runes[i:]))
+		}
+	}
+	return out
+}
+
+// affix is one PFX or SFX rule
+type affix struct {
+	prefix bool
+	cross  bool // may combine with an affix of the other kind
+	strip  string
+	add    string
+	cond   []condPart
+}
+
+// condPart is one character position of an affix condition: a set of
+// runes, negated for [^...], or any rune for "."
+type condPart struct {
+	any    bool
+	negate bool
+	runes  string
+}
+
+// parseDictionary reads the supported subset of the hunspell .aff format,
+// SET, FLAG, AF, TRY, REP, PFX, SFX, NEEDAFFIX, FORBIDDENWORD and
+// NOSUGGEST, and expands the .dic stems with it
+func parseDictionary(aff, dic []byte) (*dictionary, error) {
+	enc := "UTF-8"
+	for _, line := range strings.Split(string(aff), "\n") {
+		if f := strings.Fields(line); len(f) >= 2 && f[0] == "SET" {
+			enc = f[1]
+			break
+		}
+	}
+	aff, err := decodeDictionary(aff, enc)
+	if err != nil {
+		return nil, err
+	}
+	dic, err = decodeDictionary(dic, enc)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &dictionary{words: make(map[string]bool), noSuggest: make(map[string]bool)}
+	affixes := map[string][]affix{}
+	var aliases [][]string
+	flagMode := ""
+	var needAffix, forbidden, noSuggest string
+	parseFlags := func(s string) []string {
+		if n, err := strconv.Atoi(s); err == nil && len(aliases) > 0 {
+			if n >= 1 && n <= len(aliases) {
+				return aliases[n-1]
+			}
+			return nil
+		}
+		return splitFlags(s, flagMode)
+	}
+
+	lines := strings.Split(string(aff), "\n")
+	for i := 0; i < len(lines); i++ {
+		f := strings.Fields(lines[i])
+		if len(f) < 2 || strings.HasPrefix(f[0], "#") {
+			continue
+		}
+		switch f[0] {
+		case "FLAG":
+			flagMode = f[1]
+		case "AF":
+			if len(aliases) == 0 && len(f) == 2 {
+				// the first AF line is the count
+				if _, err := strconv.Atoi(f[1]); err == nil {
+					continue
+				}
+			}
+			aliases = append(aliases, splitFlags(f[1], flagMode))
+		case "TRY":
+			d.try = f[1]
+		case "REP":
+			if len(f) >= 3 {
+				d.rep = append(d.rep, [2]string{strings.ReplaceAll(f[1], "_", " "), strings.ReplaceAll(f[2], "_", " ")})
+			}
+		case "NEEDAFFIX":
+			needAffix = f[1]
+		case "FORBIDDENWORD":
+			forbidden = f[1]
+		case "NOSUGGEST":
+			noSuggest = f[1]
+		case "PFX", "SFX":
+			if len(f) < 4 {
+				continue
+			}
+			// the header line: PFX flag cross count
+			flag, cross := f[1], f[2] == "Y"
+			count, err := strconv.Atoi(f[3])
+			if err != nil {
+				continue
+			}
+			for ; count > 0 && i+1 < len(lines); count-- {
+				i++
+				r := strings.Fields(lines[i])
+				if len(r) < 4 || r[0] != f[0] || r[1] != flag {
+					continue
+				}
+				rule := affix{prefix: f[0] == "PFX", cross: cross, strip: r[2], add: r[3]}
+				if rule.strip == "0" {
+					rule.strip = ""
+				}
+				// continuation flags after a slash are not supported
+				rule.add, _, _ = strings.Cut(rule.add, "/")
+				if rule.add == "0" {
+					rule.add = ""
+				}
+				cond := "."
+				if len(r) > 4 {
+					cond = r[4]
+				}
+				if rule.cond, err = parseCondition(cond); err != nil {
+					return nil, fmt.Errorf("%s rule %s: %w", f[0], flag, err)
+				}
+				affixes[flag] = append(affixes[flag], rule)
+			}
+		}
+	}
+
+	sc := bufio.NewScanner(bytes.NewReader(dic))
+	sc.Buffer(make([]byte, 64*1024), 1<<20)
+	first := true
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if first {
+			// the word count
+			first = false
+			if _, err := strconv.Atoi(line); err == nil {
+				continue
+			}
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if i := strings.IndexAny(line, " \t"); i >= 0 {
+			// morphological fields
+			line = line[:i]
+		}
+		word, flags := splitDicLine(line)
+		fl := parseFlags(flags)
+		has := func(flag string) bool {
+			for _, f := range fl {
+				if f == flag && flag != "" {
+					return true
+				}
+			}
+			return false
+		}
+		if has(forbidden) {
+			continue
+		}
+		forms := expand(word, fl, affixes)
+		if !has(needAffix) {
+			forms = append(forms, word)
+		}
+		for _, w := range forms {
+			d.words[w] = true
+			if has(noSuggest) {
+				d.noSuggest[w] = true
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if len(d.words) == 0 {
+		return nil, errors.New("dictionary has no words")
+	}
+	return d, nil
+}
+
+// decodeDictionary converts dictionary text in enc to UTF-8
+func decodeDictionary(data []byte, enc string) ([]byte, error) {
+	if strings.EqualFold(enc, "UTF-8") {
+		return data, nil
+	}
+	e, err := htmlindex.Get(enc)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported dictionary encoding %q", enc)
+	}
+	return e.NewDecoder().Bytes(data)
+}
+
+// splitDicLine separates a .dic entry into its word and flags. A slash in
+// the word itself is escaped with a backslash.
+func splitDicLine(line string) (word, flags string) {
+	for i := 0; i < len(line); i++ {
+		if line[i] == '\\' && i+1 < len(line) && line[i+1] == '/' {
+			i++
+			continue
+		}
+		if line[i] == '/' {
+			return strings.ReplaceAll(line[:i], `\/`, "/"), line[i+1:]
+		}
+	}
+	return strings.ReplaceAll(line, `\/`, "/"), ""
+}
+
+// splitFlags splits a flag string in the given FLAG mode: one character per
+// flag by default, pairs for long and comma separated numbers for num
+func splitFlags(s, mode string) []string {
+	var flags []string
+	switch mode {
+	case "long":
+		runes := []rune(s)
+		for i := 0; i+1 < len(runes); i += 2 {
+			flags = append(flags, string(runes[i:i+2]))
+		}
+	case "num":
+		for _, n := range strings.Split(s, ",") {
+			if n = strings.TrimSpace(n); n != "" {
+				flags = append(flags, n)
+			}
+		}
+	default:
+		for _, r := range s {
+			flags = append(flags, string(r))
+		}
+	}
+	return flags
+}
+
+// parseCondition parses an affix condition such as "[^aeiou]y"
+func parseCondition(s string) ([]condPart, error) {
+	if s == "." {
+		return nil, nil
+	}
+	var parts []condPart
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		switch runes[i] {
+		case '.':
+			parts = append(parts, condPart{any: true})
+		case '[':
+			end := i + 1
+			for end < len(runes) && runes[end] != ']' {
+				end++
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("unclosed [ in condition %q", s)
+			}
+			p := condPart{runes: string(runes[i+1 : end])}
+			if strings.HasPrefix(p.runes, "^") {
+				p.negate, p.runes = true, p.runes[1:]
+			}
+			parts = append(parts, p)
+			i = end
+		default:
+			parts = append(parts, condPart{runes: string(runes[i])})
+		}
+	}
+	return parts, nil
+}
+
+// matches reports whether runes satisfy the condition, which applies to
+// the start of a word for prefixes and its end for suffixes
+func (a *affix) matches(word []rune) bool {
+	if len(word) < len(a.cond) {
+		return false
+	}
+	at := 0
+	if !a.prefix {
+		at = len(word) - len(a.cond)
+	}
+	for i, p := range a.cond {
+		if p.any {
+			continue
+		}
+		if strings.ContainsRune(p.runes, word[at+i]) == p.negate {
+			return false
+		}
+	}
+	return true
+}
+
+// apply returns word with the affix added, and false when it does not fit
+func (a *affix) apply(word string) (string, bool) {
+	if !a.matches([]rune(word)) {
+		return "", false
+	}
+	if a.prefix {
+		if !strings.HasPrefix(word, a.strip) {
+			return "", false
+		}
+		return a.add + word[len(a.strip):], true
+	}
+	if !strings.HasSuffix(word, a.strip) {
+		return "", false
+	}
+	return word[:len(word)-len(a.strip)] + a.add, true
+}
+
+// expand returns the forms of a stem made by its affix flags, including
+// prefix and suffix combinations where both rules allow crossing
+func expand(stem string, flags []string, affixes map[string][]affix) []string {
+	var forms, suffixed []string
+	for _, f := range flags {
+		for i := range affixes[f] {
+			a := &affixes[f][i]
+			if a.prefix {
+				continue
+			}
+			if w, ok := a.apply(stem); ok {
+				forms = append(forms, w)
+				if a.cross {
+					suffixed = append(suffixed, w)
+				}
+			}
+		}
+	}
+	for _, f := range flags {
+		for i := range affixes[f] {
+			a := &affixes[f][i]
+			if !a.prefix {
+				continue
+			}
+			if !a.matches([]rune(stem)) {
+				continue
+			}
+			if w, ok := a.apply(stem); ok {
+				forms = append(forms, w)
+			}
+			if a.cross {
+				for _, s := range suffixed {
+					// the condition belongs to the stem, which the suffix
+					// leaves alone at this end
+					if strings.HasPrefix(s, a.strip) {
+						forms = append(forms, a.add+s[len(a.strip):])
+					}
+				}
+			}
+		}
+	}
+	return forms
+}
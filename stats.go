@@ -0,0 +1,99 @@
+package main
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// Counts are the numbers shown in the status bar for a piece of text
+type Counts struct {
+	Lines int `json:"lines"`
+	Words int `json:"words"`
+	Chars int `json:"chars"` // runes, not bytes
+	Bytes int `json:"bytes"` // size once saved in the document's encoding
+}
+
+// Stats is returned by GetStats
+type Stats struct {
+	Document  Counts  `json:"document"`
+	Selection *Counts `json:"selection,omitempty"`
+}
+
+var asciiSpace = [utf8.RuneSelf]bool{'\t': true, '\n': true, '\v': true, '\f': true, '\r': true, ' ': true}
+
+// countText measures text in a single pass. CRLF counts as one line break.
+func countText(text string, enc string) Counts {
+	c := Counts{Lines: 1}
+	inWord := false
+	utf16Units := 0
+	for i := 0; i < len(text); {
+		b := text[i]
+		var r rune
+		var space bool
+		if b < utf8.RuneSelf {
+			r, space = rune(b), asciiSpace[b]
+			i++
+		} else {
+			var size int
+			r, size = utf8.DecodeRuneInString(text[i:])
+			space = unicode.IsSpace(r)
+			i += size
+		}
+		c.Chars++
+		utf16Units++
+		if r > 0xFFFF {
+			utf16Units++
+		}
+
+		switch {
+		case r == '\n':
+			c.Lines++
+		case r == '\r':
+			if i >= len(text) || text[i] != '\n' {
+				c.Lines++
+			}
+		}
+		if space {
+			inWord = false
+		} else if !inWord {
+			inWord = true
+			c.Words++
+		}
+	}
+
+	switch enc {
+	case EncodingUTF8BOM:
+		c.Bytes = len(bomUTF8) + len(text)
+	case EncodingUTF16LE, EncodingUTF16BE:
+		c.Bytes = 2 + 2*utf16Units
+	case EncodingLatin1:
+		c.Bytes = c.Chars
+	default:
+		c.Bytes = len(text)
+	}
+	return c
+}
+
+// GetStats counts lines, words, characters and bytes in content, and in
+// selection when it is not empty. Bytes are measured in the active
+// document's encoding.
+func (a *App) GetStats(content string, selection string) Stats {
+	a.mu.Lock()
+	enc := EncodingUTF8
+	if d, ok := a.docs[a.active]; ok {
+		enc = d.Encoding
+	}
+	a.mu.Unlock()
+
+	stats := Stats{Document: countText(content, enc)}
+	if selection != "" {
+		sel := countText(selection, enc)
+		if enc == EncodingUTF8BOM {
+			sel.Bytes -= len(bomUTF8)
+		} else if enc == EncodingUTF16LE || enc == EncodingUTF16BE {
+			sel.Bytes -= 2
+		}
+		stats.Selection = &sel
+	}
+	return stats
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCountText(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		enc  string
+		want Counts
+	}{
+		{"empty", "", EncodingUTF8, Counts{Lines: 1}},
+		{"one line", "hello world", EncodingUTF8, Counts{Lines: 1, Words: 2, Chars: 11, Bytes: 11}},
+		{"lf", "a\nb\n", EncodingUTF8, Counts{Lines: 3, Words: 2, Chars: 4, Bytes: 4}},
+		{"crlf counts once", "a\r\nb\r\n", EncodingUTF8, Counts{Lines: 3, Words: 2, Chars: 6, Bytes: 6}},
+		{"lone cr", "a\rb", EncodingUTF8, Counts{Lines: 2, Words: 2, Chars: 3, Bytes: 3}},
+		{"only newlines", "\n\n", EncodingUTF8, Counts{Lines: 3, Chars: 2, Bytes: 2}},
+		{"unicode words", "héllo wörld 世界", EncodingUTF8, Counts{Lines: 1, Words: 3, Chars: 14, Bytes: 20}},
+		// U+3000 is an ideographic space and splits words like any other
+		{"unicode space", "日本\u3000語", EncodingUTF8, Counts{Lines: 1, Words: 2, Chars: 4, Bytes: 12}},
+		{"bom", "ab", EncodingUTF8BOM, Counts{Lines: 1, Words: 1, Chars: 2, Bytes: 5}},
+		{"utf-16 surrogates", "a\U0001F600", EncodingUTF16LE, Counts{Lines: 1, Words: 1, Chars: 2, Bytes: 8}},
+		{"latin-1", "café", EncodingLatin1, Counts{Lines: 1, Words: 1, Chars: 4, Bytes: 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := countText(tt.text, tt.enc); got != tt.want {
+				t.Errorf("countText(%q) = %+v, want %+v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func BenchmarkCountText(b *testing.B) {
+	// about 5MB of mixed ASCII and non-ASCII prose
+	text := strings.Repeat("The quick brown fox jumps over the lazy dog, naïve café 世界.\r\n", 5<<20/66)
+	b.SetBytes(int64(len(text)))
+	b.ResetTimer()
+	for range b.N {
+		countText(text, EncodingUTF8)
+	}
+}
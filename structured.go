@@ -0,0 +1,368 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Diagnostic is a problem found in a document. Line and Column are 1-based;
+// Column is 0 when the parser only knows the line.
+type Diagnostic struct {
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Message string `json:"message"`
+}
+
+func (d Diagnostic) Error() string {
+	if d.Column > 0 {
+		return fmt.Sprintf("line %d, column %d: %s", d.Line, d.Column, d.Message)
+	}
+	return fmt.Sprintf("line %d: %s", d.Line, d.Message)
+}
+
+// FormatStructured reformats JSON, XML or YAML with indent spaces per level.
+// JSON keys keep their order unless sortKeys is set, and an indent of 0
+// minifies JSON.
+func (a *App) FormatStructured(text string, format string, indent int, sortKeys bool) (string, error) {
+	if indent < 0 || indent > 16 {
+		return "", errors.New("indent must be between 0 and 16")
+	}
+	switch strings.ToLower(format) {
+	case "json":
+		return formatJSON(text, indent, sortKeys)
+	case "xml":
+		if d := validateXML(text); len(d) > 0 {
+			return "", d[0]
+		}
+		return formatXML(text, indent), nil
+	case "yaml", "yml":
+		return formatYAML(text, indent)
+	default:
+		return "", fmt.Errorf("unsupported format %q, expected json, xml or yaml", format)
+	}
+}
+
+// ValidateStructured parses text as JSON, XML or YAML and reports where it
+// is broken. A valid document returns no diagnostics.
+func (a *App) ValidateStructured(text string, format string) []Diagnostic {
+	switch strings.ToLower(format) {
+	case "json":
+		return validateJSON(text)
+	case "xml":
+		return validateXML(text)
+	case "yaml", "yml":
+		return validateYAML(text)
+	default:
+		return []Diagnostic{{Line: 1, Message: fmt.Sprintf("unsupported format %q, expected json, xml or yaml", format)}}
+	}
+}
+
+// validateJSON reports the first syntax error in text, including trailing
+// data after the top-level value
+func validateJSON(text string) []Diagnostic {
+	dec := json.NewDecoder(strings.NewReader(text))
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return []Diagnostic{jsonDiagnostic(text, err, dec.InputOffset())}
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		line, col := offsetToLineCol(text, int(dec.InputOffset()))
+		return []Diagnostic{{Line: line, Column: col, Message: "unexpected data after the top-level value"}}
+	}
+	return []Diagnostic{}
+}
+
+// jsonDiagnostic places a decoding error in text
+func jsonDiagnostic(text string, err error, fallback int64) Diagnostic {
+	offset := fallback
+	var se *json.SyntaxError
+	if errors.As(err, &se) {
+		// the offset is just past the offending byte
+		offset = se.Offset - 1
+		if offset < 0 {
+			offset = 0
+		}
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		offset = int64(len(text))
+		err = errors.New("unexpected end of JSON input")
+	}
+	line, col := offsetToLineCol(text, int(offset))
+	return Diagnostic{Line: line, Column: col, Message: strings.TrimPrefix(err.Error(), "json: ")}
+}
+
+func formatJSON(text string, indent int, sortKeys bool) (string, error) {
+	if d := validateJSON(text); len(d) > 0 {
+		return "", d[0]
+	}
+	src := []byte(text)
+	if sortKeys {
+		// decoding into maps and encoding again sorts object keys
+		dec := json.NewDecoder(bytes.NewReader(src))
+		dec.UseNumber()
+		var v any
+		if err := dec.Decode(&v); err != nil {
+			return "", err
+		}
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(v); err != nil {
+			return "", err
+		}
+		src = buf.Bytes()
+	}
+	var out bytes.Buffer
+	var err error
+	if indent == 0 {
+		err = json.Compact(&out, src)
+	} else {
+		err = json.Indent(&out, src, "", strings.Repeat(" ", indent))
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out.String()) + "\n", nil
+}
+
+// validateXML reports the first well-formedness error in text
+func validateXML(text string) []Diagnostic {
+	dec := xml.NewDecoder(strings.NewReader(text))
+	dec.Strict = true
+	for {
+		_, err := dec.Token()
+		if err == io.EOF {
+			return []Diagnostic{}
+		}
+		if err != nil {
+			line, col := dec.InputPos()
+			msg := err.Error()
+			var se *xml.SyntaxError
+			if errors.As(err, &se) {
+				msg = se.Msg
+				if se.Line != line {
+					line, col = se.Line, 0
+				}
+			}
+			return []Diagnostic{{Line: line, Column: col, Message: msg}}
+		}
+	}
+}
+
+// xmlToken is a piece of raw XML markup or text
+type xmlToken struct {
+	kind byte // '<' open, '/' close, 'e' empty element, 't' text, 'm' other markup
+	text string
+}
+
+// scanXML splits well-formed XML into markup and text without decoding it,
+// so CDATA sections, comments and entities come through untouched
+func scanXML(text string) []xmlToken {
+	var toks []xmlToken
+	for i := 0; i < len(text); {
+		if text[i] != '<' {
+			j := strings.IndexByte(text[i:], '<')
+			if j < 0 {
+				j = len(text) - i
+			}
+			toks = append(toks, xmlToken{'t', text[i : i+j]})
+			i += j
+			continue
+		}
+		rest := text[i:]
+		end := func(marker string) int {
+			if j := strings.Index(rest, marker); j >= 0 {
+				return j + len(marker)
+			}
+			return len(rest)
+		}
+		var n int
+		var kind byte = 'm'
+		switch {
+		case strings.HasPrefix(rest, "<![CDATA["):
+			n, kind = end("]]>"), 't'
+		case strings.HasPrefix(rest, "<!--"):
+			n = end("-->")
+		case strings.HasPrefix(rest, "<?"):
+			n = end("?>")
+		case strings.HasPrefix(rest, "<!"):
+			n = doctypeEnd(rest)
+		default:
+			n = tagEnd(rest)
+			switch {
+			case strings.HasPrefix(rest, "</"):
+				kind = '/'
+			case strings.HasSuffix(rest[:n], "/>"):
+				kind = 'e'
+			default:
+				kind = '<'
+			}
+		}
+		toks = append(toks, xmlToken{kind, rest[:n]})
+		i += n
+	}
+	return toks
+}
+
+// tagEnd returns the length of the tag at the start of s, skipping '>'
+// inside quoted attribute values
+func tagEnd(s string) int {
+	var quote byte
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '>':
+			return i + 1
+		}
+	}
+	return len(s)
+}
+
+// doctypeEnd returns the length of a <!DOCTYPE> declaration, which may hold
+// an internal subset in square brackets
+func doctypeEnd(s string) int {
+	depth := 0
+	for i := 2; i < len(s); i++ {
+		switch s[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case '>':
+			if depth <= 0 {
+				return i + 1
+			}
+		}
+	}
+	return len(s)
+}
+
+// formatXML reindents well-formed XML. Elements holding only text stay on
+// one line, and whitespace between elements is replaced by the indentation.
+func formatXML(text string, indent int) string {
+	toks := scanXML(text)
+	pad := strings.Repeat(" ", indent)
+	var sb strings.Builder
+	depth := 0
+	line := func(s string) {
+		if sb.Len() > 0 {
+			sb.WriteByte('\n')
+		}
+		sb.WriteString(strings.Repeat(pad, depth))
+		sb.WriteString(s)
+	}
+	for i := 0; i < len(toks); i++ {
+		t := toks[i]
+		switch t.kind {
+		case '<':
+			// <a>text</a> stays together
+			if i+2 < len(toks) && toks[i+1].kind == 't' && toks[i+2].kind == '/' {
+				line(t.text + toks[i+1].text + toks[i+2].text)
+				i += 2
+				continue
+			}
+			if i+1 < len(toks) && toks[i+1].kind == '/' {
+				line(t.text + toks[i+1].text)
+				i++
+				continue
+			}
+			line(t.text)
+			depth++
+		case '/':
+			if depth > 0 {
+				depth--
+			}
+			line(t.text)
+		case 't':
+			if strings.HasPrefix(t.text, "<![CDATA[") {
+				line(t.text)
+			} else if s := strings.TrimSpace(t.text); s != "" {
+				line(s)
+			}
+		default:
+			line(t.text)
+		}
+	}
+	sb.WriteByte('\n')
+	return sb.String()
+}
+
+var yamlLine = regexp.MustCompile(`line (\d+)(?:, column (\d+))?: (.*)`)
+
+// validateYAML reports the parse errors in every document of text
+func validateYAML(text string) []Diagnostic {
+	dec := yaml.NewDecoder(strings.NewReader(text))
+	for {
+		var n yaml.Node
+		err := dec.Decode(&n)
+		if err == io.EOF {
+			return []Diagnostic{}
+		}
+		if err != nil {
+			return yamlDiagnostics(err)
+		}
+	}
+}
+
+// yamlDiagnostics pulls positions out of yaml.v3's error messages
+func yamlDiagnostics(err error) []Diagnostic {
+	lines := []string{err.Error()}
+	var te *yaml.TypeError
+	if errors.As(err, &te) {
+		lines = te.Errors
+	}
+	diags := make([]Diagnostic, 0, len(lines))
+	for _, l := range lines {
+		d := Diagnostic{Line: 1, Message: strings.TrimPrefix(l, "yaml: ")}
+		if m := yamlLine.FindStringSubmatch(l); m != nil {
+			d.Line, _ = strconv.Atoi(m[1])
+			d.Column, _ = strconv.Atoi(m[2])
+			d.Message = m[3]
+		}
+		diags = append(diags, d)
+	}
+	return diags
+}
+
+// formatYAML re-encodes each document of text, keeping key order and
+// comments
+func formatYAML(text string, indent int) (string, error) {
+	if indent == 0 {
+		indent = 2
+	}
+	dec := yaml.NewDecoder(strings.NewReader(text))
+	var out bytes.Buffer
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(indent)
+	for {
+		var n yaml.Node
+		err := dec.Decode(&n)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", yamlDiagnostics(err)[0]
+		}
+		if err := enc.Encode(&n); err != nil {
+			return "", err
+		}
+	}
+	if err := enc.Close(); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
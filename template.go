@@ -0,0 +1,236 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TemplateInfo describes a template NewFromTemplate can start a document
+// from
+type TemplateInfo struct {
+	// Name is the template's file name, whose extension picks the language
+	Name     string `json:"name"`
+	Language string `json:"language"`
+	Size     int64  `json:"size"`
+}
+
+// defaultTemplates are written to the templates folder the first time it
+// is used
+var defaultTemplates = []struct{ name, content string }{
+	{"HTML5.html", `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <meta name="author" content="{{AUTHOR}}">
+  <title>Untitled</title>
+  <link rel="stylesheet" href="style.css">
+</head>
+<body>
+
+  <script src="main.js"></script>
+</body>
+</html>
+`},
+	{"Go main.go", `// Created by {{AUTHOR}} on {{DATE}}
+package main
+
+import "fmt"
+
+func main() {
+	fmt.Println("Hello, world")
+}
+`},
+	{"README.md", `# Project
+
+A short description of what this project does.
+
+## Getting started
+
+## Usage
+
+## License
+
+Copyright (c) {{YEAR}} {{AUTHOR}}
+`},
+	{"MIT License.txt", `MIT License
+
+Copyright (c) {{YEAR}} {{AUTHOR}}
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+`},
+}
+
+// ListTemplates returns the templates in the templates config folder by
+// name
+func (a *App) ListTemplates() []TemplateInfo {
+	dir, err := templatesDir()
+	if err != nil {
+		return []TemplateInfo{}
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return []TemplateInfo{}
+	}
+	list := []TemplateInfo{}
+	for _, e := range entries {
+		if !e.Type().IsRegular() {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		list = append(list, TemplateInfo{
+			Name:     e.Name(),
+			Language: detectLanguage(e.Name(), "").ID,
+			Size:     info.Size(),
+		})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return strings.ToLower(list[i].Name) < strings.ToLower(list[j].Name)
+	})
+	return list
+}
+
+// NewFromTemplate returns the content of a template with {{DATE}},
+// {{YEAR}} and {{AUTHOR}} filled in. Anything else between braces is left
+// as it is.
+func (a *App) NewFromTemplate(name string) (string, error) {
+	path, err := templatePath(name)
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", newFileError(path, err)
+	}
+	content, _, _ := decodeText(data)
+
+	a.mu.Lock()
+	author := a.settings.Author
+	a.mu.Unlock()
+	if author == "" {
+		author = userName()
+	}
+	now := time.Now()
+	return expandTemplate(content, map[string]string{
+		"DATE":   now.Format("2006-01-02"),
+		"YEAR":   now.Format("2006"),
+		"AUTHOR": author,
+	}), nil
+}
+
+// SaveAsTemplate stores content as the template called name. When a
+// template of that name exists and overwrite is not set nothing is written
+// and true is returned, so the user can be asked first.
+func (a *App) SaveAsTemplate(name, content string, overwrite bool) (bool, error) {
+	path, err := templatePath(name)
+	if err != nil {
+		return false, err
+	}
+	if _, err := os.Stat(path); err == nil && !overwrite {
+		return true, nil
+	}
+	if err := writeFile(path, []byte(content), 0o644); err != nil {
+		return false, newFileError(path, err)
+	}
+	return false, nil
+}
+
+// expandTemplate replaces each {{NAME}} in content that names one of vars.
+// Unknown names and unclosed braces stay as written.
+func expandTemplate(content string, vars map[string]string) string {
+	var b strings.Builder
+	for {
+		i := strings.Index(content, "{{")
+		if i < 0 {
+			break
+		}
+		j := strings.Index(content[i+2:], "}}")
+		if j < 0 {
+			break
+		}
+		name := strings.TrimSpace(content[i+2 : i+2+j])
+		value, ok := vars[name]
+		if !ok {
+			// keep the first brace and look again from the second, which
+			// may open a placeholder of its own as in {{{YEAR}}
+			b.WriteString(content[:i+1])
+			content = content[i+1:]
+			continue
+		}
+		b.WriteString(content[:i])
+		b.WriteString(value)
+		content = content[i+2+j+2:]
+	}
+	b.WriteString(content)
+	return b.String()
+}
+
+// templatePath returns the file of the template called name
+func templatePath(name string) (string, error) {
+	if err := validFileName(name); err != nil {
+		return "", err
+	}
+	dir, err := templatesDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// templatesDir returns the templates config folder, creating it with the
+// default templates the first time
+func templatesDir() (string, error) {
+	dir, err := configPath("templates")
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(dir); !errors.Is(err, fs.ErrNotExist) {
+		return dir, nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	for _, t := range defaultTemplates {
+		if err := writeFile(filepath.Join(dir, t.name), []byte(t.content), 0o644); err != nil {
+			return "", err
+		}
+	}
+	return dir, nil
+}
+
+// userName returns the full name of the user, or the login name
+func userName() string {
+	u, err := user.Current()
+	if err != nil {
+		return ""
+	}
+	if name, _, _ := strings.Cut(u.Name, ","); name != "" {
+		return name
+	}
+	return u.Username
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+// transforms are the operations accepted by TransformText
+var transforms = []string{
+	"upper", "lower", "title", "sentence",
+	"sort-asc", "sort-desc", "sort-asc-ci", "sort-desc-ci",
+	"dedupe", "reverse", "trim-trailing",
+	"tabs-to-spaces", "spaces-to-tabs",
+	"json-pretty", "json-minify",
+}
+
+// TransformText applies op to text. Line based operations keep the text's
+// line endings and whether it ends with a line break.
+func (a *App) TransformText(text string, op string) (string, error) {
+	a.mu.Lock()
+	settings := a.settings
+	a.mu.Unlock()
+
+	switch op {
+	case "upper":
+		return cases.Upper(language.Und).String(text), nil
+	case "lower":
+		return cases.Lower(language.Und).String(text), nil
+	case "title":
+		return cases.Title(language.Und).String(text), nil
+	case "sentence":
+		return sentenceCase(text), nil
+	case "sort-asc", "sort-desc", "sort-asc-ci", "sort-desc-ci":
+		return mapLines(text, func(lines []string) []string {
+			return sortLines(lines, strings.HasPrefix(op, "sort-desc"), strings.HasSuffix(op, "-ci"))
+		}), nil
+	case "dedupe":
+		return mapLines(text, dedupeLines), nil
+	case "reverse":
+		return mapLines(text, func(lines []string) []string {
+			for i, j := 0, len(lines)-1; i < j; i, j = i+1, j-1 {
+				lines[i], lines[j] = lines[j], lines[i]
+			}
+			return lines
+		}), nil
+	case "trim-trailing":
+		return mapLines(text, func(lines []string) []string {
+			for i, l := range lines {
+				lines[i] = strings.TrimRightFunc(l, unicode.IsSpace)
+			}
+			return lines
+		}), nil
+	case "tabs-to-spaces":
+		return mapLines(text, func(lines []string) []string {
+			for i, l := range lines {
+				if strings.ContainsRune(l, '\t') {
+					lines[i] = string(expandTabs(l, settings.TabWidth))
+				}
+			}
+			return lines
+		}), nil
+	case "spaces-to-tabs":
+		return mapLines(text, func(lines []string) []string {
+			for i, l := range lines {
+				lines[i] = tabifyIndent(l, settings.TabWidth)
+			}
+			return lines
+		}), nil
+	case "json-pretty", "json-minify":
+		return transformJSON(text, op == "json-pretty", settings)
+	default:
+		return "", fmt.Errorf("unknown operation %q, expected one of %s", op, strings.Join(transforms, ", "))
+	}
+}
+
+// splitLines breaks text into lines without their terminators. It returns
+// the first line break used, so the text can be joined back the same way,
+// and whether the text ended with one.
+func splitLines(text string) (lines []string, sep string, trailing bool) {
+	sep = "\n"
+	if i := strings.IndexAny(text, "\r\n"); i >= 0 {
+		if text[i] == '\r' && i+1 < len(text) && text[i+1] == '\n' {
+			sep = "\r\n"
+		} else {
+			sep = text[i : i+1]
+		}
+	}
+	normalised := convertLineEndings(text, LineEndingLF)
+	trailing = strings.HasSuffix(normalised, "\n")
+	normalised = strings.TrimSuffix(normalised, "\n")
+	return strings.Split(normalised, "\n"), sep, trailing
+}
+
+// mapLines applies fn to the lines of text and joins the result with the
+// text's own line break
+func mapLines(text string, fn func([]string) []string) string {
+	if text == "" {
+		return ""
+	}
+	lines, sep, trailing := splitLines(text)
+	out := strings.Join(fn(lines), sep)
+	if trailing {
+		out += sep
+	}
+	return out
+}
+
+// sortLines sorts lines stably, optionally descending and ignoring case
+func sortLines(lines []string, desc, fold bool) []string {
+	keys := lines
+	if fold {
+		keys = make([]string, len(lines))
+		lower := cases.Fold()
+		for i, l := range lines {
+			keys[i] = lower.String(l)
+		}
+	}
+	idx := make([]int, len(lines))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool {
+		if desc {
+			return keys[idx[i]] > keys[idx[j]]
+		}
+		return keys[idx[i]] < keys[idx[j]]
+	})
+	out := make([]string, len(lines))
+	for i, k := range idx {
+		out[i] = lines[k]
+	}
+	return out
+}
+
+// dedupeLines drops repeated lines, keeping the first of each
+func dedupeLines(lines []string) []string {
+	seen := make(map[string]struct{}, len(lines))
+	out := lines[:0]
+	for _, l := range lines {
+		if _, ok := seen[l]; ok {
+			continue
+		}
+		seen[l] = struct{}{}
+		out = append(out, l)
+	}
+	return out
+}
+
+// tabifyIndent replaces each full tab stop of leading spaces with a tab
+func tabifyIndent(line string, width int) string {
+	n := 0
+	for n < len(line) && line[n] == ' ' {
+		n++
+	}
+	if n < width {
+		return line
+	}
+	return strings.Repeat("\t", n/width) + strings.Repeat(" ", n%width) + line[n:]
+}
+
+// sentenceCase lowercases text and capitalises the first letter of each
+// sentence
+func sentenceCase(text string) string {
+	lower := cases.Lower(language.Und).String(text)
+	title := cases.Title(language.Und)
+	var sb strings.Builder
+	sb.Grow(len(lower))
+	start := true
+	for i := 0; i < len(lower); {
+		r, size := utf8.DecodeRuneInString(lower[i:])
+		switch {
+		case start && unicode.IsLetter(r):
+			// Title handles letters like ß that become more than one rune
+			sb.WriteString(title.String(lower[i : i+size]))
+			start = false
+		case r == '.' || r == '!' || r == '?':
+			sb.WriteRune(r)
+			start = true
+		default:
+			if start && !unicode.IsSpace(r) && !unicode.IsPunct(r) {
+				// a digit or symbol starts the sentence, leave the rest as is
+				start = false
+			}
+			sb.WriteRune(r)
+		}
+		i += size
+	}
+	return sb.String()
+}
+
+// transformJSON pretty prints or minifies JSON without reordering keys.
+// Pretty printing indents per the tab settings.
+func transformJSON(text string, pretty bool, s Settings) (string, error) {
+	var out bytes.Buffer
+	var err error
+	if pretty {
+		indent := "\t"
+		if s.InsertSpaces {
+			indent = strings.Repeat(" ", s.TabWidth)
+		}
+		err = json.Indent(&out, []byte(text), "", indent)
+	} else {
+		err = json.Compact(&out, []byte(text))
+	}
+	if err != nil {
+		var se *json.SyntaxError
+		if errors.As(err, &se) {
+			line, col := offsetToLineCol(text, int(se.Offset))
+			return "", fmt.Errorf("invalid JSON at line %d, column %d: %v", line, col, se)
+		}
+		return "", fmt.Errorf("invalid JSON: %w", err)
+	}
+	result := out.String()
+	if pretty {
+		if _, sep, _ := splitLines(text); sep != "\n" {
+			result = strings.ReplaceAll(result, "\n", sep)
+		}
+	}
+	return result, nil
+}
+
+// offsetToLineCol converts a byte offset into a 1-based line and rune column
+func offsetToLineCol(text string, offset int) (int, int) {
+	if offset > len(text) {
+		offset = len(text)
+	}
+	line, col := 1, 1
+	for _, r := range text[:offset] {
+		if r == '\n' {
+			line++
+			col = 1
+		} else {
+			col++
+		}
+	}
+	return line, col
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// moveToTrash asks the Finder to move path to the trash, which keeps the
+// Put Back information
+func moveToTrash(path string) error {
+	script := fmt.Sprintf(`tell application "Finder" to delete POSIX file %q`, path)
+	out, err := exec.Command("osascript", "-e", script).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("moving %s to the trash: %s", path, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+//go:build !windows && !darwin
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// errNoTrash is returned for a file on a drive with no usable trash
+var errNoTrash = errors.New("there is no trash on this drive")
+
+// moveToTrash moves path to the trash as the freedesktop.org trash spec
+// lays out: the home trash for files on the same drive, otherwise a
+// .Trash-uid folder at the top of the file's drive
+func moveToTrash(path string) error {
+	dev, err := deviceOf(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	home, err := homeTrash()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(home, 0o700); err == nil {
+		if d, err := deviceOf(home); err == nil && d == dev {
+			return trashInto(home, path, path)
+		}
+	}
+
+	top, err := mountPoint(filepath.Dir(path), dev)
+	if err != nil {
+		return err
+	}
+	trash := filepath.Join(top, ".Trash-"+strconv.Itoa(os.Getuid()))
+	if err := os.MkdirAll(trash, 0o700); err != nil {
+		return errNoTrash
+	}
+	rel, err := filepath.Rel(top, path)
+	if err != nil {
+		return err
+	}
+	// paths in a drive's own trash are relative to the top of the drive
+	return trashInto(trash, path, rel)
+}
+
+// homeTrash returns the trash folder in the user's data folder
+func homeTrash() (string, error) {
+	if dir := os.Getenv("XDG_DATA_HOME"); filepath.IsAbs(dir) {
+		return filepath.Join(dir, "Trash"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share", "Trash"), nil
+}
+
+// trashInto moves path into the files folder of trash, recording where it
+// came from as origin in a .trashinfo file
+func trashInto(trash, path, origin string) error {
+	files, info := filepath.Join(trash, "files"), filepath.Join(trash, "info")
+	if err := os.MkdirAll(files, 0o700); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(info, 0o700); err != nil {
+		return err
+	}
+	record := fmt.Sprintf("[Trash Info]\nPath=%s\nDeletionDate=%s\n",
+		(&url.URL{Path: origin}).EscapedPath(), time.Now().Format("2006-01-02T15:04:05"))
+
+	// the info file is created first and exclusively, which claims the name
+	base := filepath.Base(path)
+	for n := 1; ; n++ {
+		name := base
+		if n > 1 {
+			ext := filepath.Ext(base)
+			name = fmt.Sprintf("%s.%d%s", base[:len(base)-len(ext)], n, ext)
+		}
+		infoPath := filepath.Join(info, name+".trashinfo")
+		f, err := os.OpenFile(infoPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		_, err = f.WriteString(record)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err == nil {
+			err = os.Rename(path, filepath.Join(files, name))
+		}
+		if err != nil {
+			os.Remove(infoPath)
+		}
+		return err
+	}
+}
+
+// deviceOf returns the ID of the drive path is on
+func deviceOf(path string) (uint64, error) {
+	var st syscall.Stat_t
+	if err := syscall.Stat(path, &st); err != nil {
+		return 0, err
+	}
+	return uint64(st.Dev), nil
+}
+
+// mountPoint returns the top folder of the drive dir is on
+func mountPoint(dir string, dev uint64) (string, error) {
+	dir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir, nil
+		}
+		if d, err := deviceOf(parent); err != nil || d != dev {
+			return dir, nil
+		}
+		dir = parent
+	}
+}
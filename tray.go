@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// TrayAvailable reports whether the tray icon is showing, which is what
+// MinimizeToTray needs to be of any use
+func (a *App) TrayAvailable() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.tray != nil
+}
+
+// hideToTrayLocked reports whether closing the window should only hide it.
+// It consumes a pending quit request. a.mu must be held.
+func (a *App) hideToTrayLocked() bool {
+	quitting := a.quitting
+	a.quitting = false
+	if quitting || a.tray == nil || !a.settings.MinimizeToTray {
+		return false
+	}
+	a.hidden = true
+	return true
+}
+
+// toggleWindow shows the window if it is hidden and hides it otherwise
+func (a *App) toggleWindow() {
+	a.mu.Lock()
+	a.hidden = !a.hidden
+	hidden := a.hidden
+	a.mu.Unlock()
+	if hidden {
+		runtime.WindowHide(a.ctx)
+	} else {
+		a.showWindow()
+	}
+}
+
+// showWindow brings the window back from the tray or the taskbar
+func (a *App) showWindow() {
+	a.mu.Lock()
+	a.hidden = false
+	a.mu.Unlock()
+	runtime.WindowUnminimise(a.ctx)
+	runtime.WindowShow(a.ctx)
+}
+
+// quit exits for real, past minimize-to-tray. The unsaved changes prompt
+// still runs and can call it off.
+func (a *App) quit() {
+	a.mu.Lock()
+	a.quitting = true
+	a.mu.Unlock()
+	a.showWindow()
+	runtime.Quit(a.ctx)
+}
+
+// trayPNG draws the tray icon, a page of text
+func trayPNG() []byte {
+	const size = 32
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	page := color.RGBA{R: 240, G: 240, B: 235, A: 255}
+	ink := color.RGBA{R: 27, G: 38, B: 54, A: 255}
+	for y := 2; y < size-2; y++ {
+		for x := 6; x < size-6; x++ {
+			c := page
+			if x == 6 || x == size-7 || y == 2 || y == size-3 {
+				c = ink
+			}
+			img.Set(x, y, c)
+		}
+	}
+	for y := 8; y < size-6; y += 4 {
+		for x := 10; x < size-10; x++ {
+			img.Set(x, y, ink)
+		}
+	}
+	var buf bytes.Buffer
+	png.Encode(&buf, img)
+	return buf.Bytes()
+}
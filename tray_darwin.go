@@ -0,0 +1,12 @@
+//go:build darwin
+
+package main
+
+// trayIcon is unused on macOS. The systray package and Wails both define
+// an AppDelegate class, so they cannot be linked into one binary; the Dock
+// icon covers the same ground.
+type trayIcon struct{}
+
+func (a *App) startTray()   {}
+func (a *App) refreshTray() {}
+func (a *App) stopTray()    {}
@@ -0,0 +1,21 @@
+//go:build !darwin && !windows
+
+package main
+
+import "github.com/godbus/dbus/v5"
+
+// trayHostAvailable checks for a StatusNotifierItem host on the session bus,
+// which is what shows tray icons on Linux desktops
+func trayHostAvailable() bool {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return false
+	}
+	var owned bool
+	err = conn.BusObject().Call("org.freedesktop.DBus.NameHasOwner", 0, "org.kde.StatusNotifierWatcher").Store(&owned)
+	return err == nil && owned
+}
+
+func trayIconData() []byte {
+	return trayPNG()
+}
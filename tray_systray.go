@@ -0,0 +1,136 @@
+//go:build !darwin
+
+package main
+
+import (
+	"path/filepath"
+	"sync"
+
+	"fyne.io/systray"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// trayIcon is the running tray icon and its menu
+type trayIcon struct {
+	end    func()
+	mu     sync.Mutex
+	recent *systray.MenuItem
+	items  []*systray.MenuItem // entries of the recent submenu
+	stop   chan struct{}       // closed when the entries are replaced
+}
+
+// startTray puts the icon in the system tray. Without a tray to show it
+// in, as on GNOME without the AppIndicator extension, nothing is shown and
+// the window closes normally.
+func (a *App) startTray() {
+	if !trayHostAvailable() {
+		return
+	}
+	t := &trayIcon{}
+	ready := make(chan struct{})
+	start, end := systray.RunWithExternalLoop(func() { close(ready) }, nil)
+	t.end = end
+	start()
+
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		select {
+		case <-ready:
+		case <-a.bg.Done():
+			return
+		}
+		a.trayMenu(t)
+	}()
+}
+
+// trayMenu builds the menu and handles its clicks until shutdown
+func (a *App) trayMenu(t *trayIcon) {
+	systray.SetIcon(trayIconData())
+	systray.SetTitle("wailspad")
+	systray.SetTooltip("wailspad")
+
+	newItem := systray.AddMenuItem("New", "Create an empty document")
+	t.recent = systray.AddMenuItem("Open Recent", "")
+	toggle := systray.AddMenuItem("Show/Hide Window", "")
+	systray.AddSeparator()
+	quit := systray.AddMenuItem("Quit", "Quit wailspad")
+
+	a.mu.Lock()
+	a.tray = t
+	recent := append([]string{}, a.recent...)
+	a.mu.Unlock()
+	a.setTrayRecent(t, recent)
+
+	for {
+		select {
+		case <-newItem.ClickedCh:
+			d := a.NewDocument()
+			a.showWindow()
+			runtime.EventsEmit(a.ctx, "file:opened", d)
+		case <-toggle.ClickedCh:
+			a.toggleWindow()
+		case <-quit.ClickedCh:
+			a.quit()
+		case <-a.bg.Done():
+			return
+		}
+	}
+}
+
+// refreshTray rebuilds the Open Recent submenu
+func (a *App) refreshTray() {
+	a.mu.Lock()
+	t := a.tray
+	recent := append([]string{}, a.recent...)
+	a.mu.Unlock()
+	if t != nil {
+		a.setTrayRecent(t, recent)
+	}
+}
+
+func (a *App) setTrayRecent(t *trayIcon, files []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.stop != nil {
+		close(t.stop)
+	}
+	for _, item := range t.items {
+		item.Remove()
+	}
+	t.items = nil
+	t.stop = make(chan struct{})
+	stop := t.stop
+
+	if len(files) == 0 {
+		empty := t.recent.AddSubMenuItem("No recent files", "")
+		empty.Disable()
+		t.items = append(t.items, empty)
+		return
+	}
+	for _, path := range files {
+		item := t.recent.AddSubMenuItem(filepath.Base(path), path)
+		t.items = append(t.items, item)
+		go func() {
+			for {
+				select {
+				case <-item.ClickedCh:
+					a.openAndShow(path)
+				case <-stop:
+					return
+				}
+			}
+		}()
+	}
+}
+
+// stopTray removes the icon
+func (a *App) stopTray() {
+	a.mu.Lock()
+	t := a.tray
+	a.tray = nil
+	a.mu.Unlock()
+	if t != nil {
+		t.end()
+	}
+}
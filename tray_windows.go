@@ -0,0 +1,28 @@
+//go:build windows
+
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// trayHostAvailable is always true, Windows always has a notification area
+func trayHostAvailable() bool {
+	return true
+}
+
+// trayIconData wraps the PNG icon in an ICO file, which is what the
+// notification area takes
+func trayIconData() []byte {
+	img := trayPNG()
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, [3]uint16{0, 1, 1})
+	binary.Write(&buf, binary.LittleEndian, struct {
+		Width, Height, Colors, Reserved uint8
+		Planes, BitCount                uint16
+		Size, Offset                    uint32
+	}{32, 32, 0, 0, 1, 32, uint32(len(img)), 6 + 16})
+	buf.Write(img)
+	return buf.Bytes()
+}
@@ -0,0 +1,325 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	// treeQuiet is how long the workspace has to be still before changes
+	// are reported
+	treeQuiet = 200 * time.Millisecond
+	// treeMaxDelay bounds how long a steady stream of changes is held
+	treeMaxDelay = time.Second
+)
+
+// defaultWatchIgnore are left out of workspace change events
+var defaultWatchIgnore = []string{".git", "node_modules"}
+
+// PathRename is an entry of an fs:renamed event
+type PathRename struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// treeWatcher reports changes anywhere under the workspace as batched
+// fs:created, fs:removed, fs:renamed and fs:modified events, each with the
+// list of paths affected
+type treeWatcher struct {
+	root   string
+	ignore []string
+	fsw    *fsnotify.Watcher
+	dirs   map[string]bool // folders watched, owned by run
+	stop   context.CancelFunc
+}
+
+// treeBatch collects the changes of one burst, merging repeated changes to
+// the same path
+type treeBatch struct {
+	kinds   map[string]string // created, removed or modified; "" cancelled out
+	order   []string
+	renames []PathRename
+	moved   string // the path of a rename waiting for its other half
+}
+
+// SetWatchIgnorePatterns changes the glob patterns of the files and folders
+// left out of workspace change events. A pattern without a slash matches a
+// name at any depth, like node_modules.
+func (a *App) SetWatchIgnorePatterns(patterns []string) error {
+	if patterns == nil {
+		patterns = []string{}
+	}
+	_, err := a.UpdateSettings(map[string]any{"watchIgnore": patterns})
+	return err
+}
+
+// CloseFolder closes the workspace and stops watching it
+func (a *App) CloseFolder() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.workspace = ""
+	a.stopTreeWatchLocked()
+}
+
+// watchTree starts watching the workspace folder in place of any earlier
+// one. The watches are added in the background, a big tree takes a while.
+func (a *App) watchTree() {
+	a.mu.Lock()
+	a.stopTreeWatchLocked()
+	root, ignore, bg := a.workspace, a.settings.WatchIgnore, a.bg
+	if root == "" || bg == nil {
+		a.mu.Unlock()
+		return
+	}
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		a.mu.Unlock()
+		a.log.Warn("watching the folder failed", "path", root, "err", err)
+		runtime.EventsEmit(a.ctx, "fs:error", err.Error())
+		return
+	}
+	ctx, cancel := context.WithCancel(bg)
+	t := &treeWatcher{root: root, ignore: ignore, fsw: fsw, dirs: make(map[string]bool), stop: cancel}
+	a.treeWatch = t
+	a.mu.Unlock()
+
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		// closing the watcher releases every watch it holds
+		defer fsw.Close()
+		if err := t.addTree(root, nil); err != nil {
+			a.log.Warn("watching the folder failed", "path", root, "err", err)
+			runtime.EventsEmit(a.ctx, "fs:error", err.Error())
+		}
+		a.log.Debug("watching folder", "path", root, "folders", len(t.dirs))
+		t.run(ctx, func(event string, data any) {
+			if event == "fs:error" {
+				a.log.Warn("folder watch error", "path", root, "err", data)
+			} else {
+				a.log.Debug(event, "paths", data)
+			}
+			runtime.EventsEmit(a.ctx, event, data)
+		})
+	}()
+}
+
+// stopTreeWatchLocked stops the workspace watcher. a.mu must be held.
+func (a *App) stopTreeWatchLocked() {
+	if a.treeWatch != nil {
+		a.treeWatch.stop()
+		a.treeWatch = nil
+	}
+}
+
+// run handles file system events until ctx is done
+func (t *treeWatcher) run(ctx context.Context, emit func(event string, data any)) {
+	batch := newTreeBatch()
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+	defer timer.Stop()
+	var first time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-t.fsw.Events:
+			if !ok {
+				return
+			}
+			if !t.handle(ev, batch) {
+				continue
+			}
+			now := time.Now()
+			if first.IsZero() {
+				first = now
+			}
+			wait := treeQuiet
+			if left := first.Add(treeMaxDelay).Sub(now); left < wait {
+				wait = left
+			}
+			timer.Reset(wait)
+		case err, ok := <-t.fsw.Errors:
+			if !ok {
+				return
+			}
+			if errors.Is(err, fsnotify.ErrEventOverflow) {
+				// events were lost, the sidebar has to reread the tree
+				batch.add(t.root, "modified")
+				timer.Reset(treeQuiet)
+				continue
+			}
+			emit("fs:error", err.Error())
+		case <-timer.C:
+			batch.flush(emit)
+			batch = newTreeBatch()
+			first = time.Time{}
+		}
+	}
+}
+
+// handle adds an event to the batch, reporting whether it was of interest
+func (t *treeWatcher) handle(ev fsnotify.Event, b *treeBatch) bool {
+	if t.ignored(ev.Name) {
+		return false
+	}
+	switch {
+	case ev.Has(fsnotify.Create):
+		moved := b.moved
+		b.moved = ""
+		if info, err := os.Lstat(ev.Name); err == nil && info.IsDir() {
+			// anything made inside a new folder before the watch was in
+			// place is reported as created too
+			created := b
+			if moved != "" {
+				created = nil
+			}
+			if err := t.addTree(ev.Name, created); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				b.add(t.root, "modified")
+			}
+		}
+		if moved != "" {
+			b.renames = append(b.renames, PathRename{From: moved, To: ev.Name})
+			return true
+		}
+		b.add(ev.Name, "created")
+	case ev.Has(fsnotify.Rename):
+		t.unwatch(ev.Name)
+		b.settleMove()
+		// the new name arrives as the Create that follows
+		b.moved = ev.Name
+	case ev.Has(fsnotify.Remove):
+		t.unwatch(ev.Name)
+		b.add(ev.Name, "removed")
+	case ev.Has(fsnotify.Write):
+		b.add(ev.Name, "modified")
+	default:
+		return false
+	}
+	return true
+}
+
+// addTree watches dir and the folders below it. With a batch, the entries
+// found are recorded as created.
+func (t *treeWatcher) addTree(dir string, b *treeBatch) error {
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == dir {
+				return err
+			}
+			// a folder that vanished or cannot be read is passed over
+			return nil
+		}
+		if p != dir && t.ignored(p) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if b != nil && p != dir {
+			b.add(p, "created")
+		}
+		if !d.IsDir() || t.dirs[p] {
+			return nil
+		}
+		if err := t.fsw.Add(p); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			// the usual cause is running out of inotify watches
+			return fmt.Errorf("watching %s: %w", p, err)
+		}
+		t.dirs[p] = true
+		return nil
+	})
+}
+
+// unwatch drops the watches on a folder that is gone and everything below
+// it, so none are left behind on deleted folders
+func (t *treeWatcher) unwatch(p string) {
+	for d := range t.dirs {
+		if _, ok := underPath(p, d); ok {
+			// the kernel may have dropped it already
+			t.fsw.Remove(d)
+			delete(t.dirs, d)
+		}
+	}
+}
+
+// ignored reports whether p or a folder it is in matches an ignore pattern
+func (t *treeWatcher) ignored(p string) bool {
+	rel, ok := underPath(t.root, p)
+	if !ok || rel == "." {
+		return false
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	for i := range parts {
+		prefix := strings.Join(parts[:i+1], "/")
+		for _, pattern := range t.ignore {
+			if matchGlob(pattern, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func newTreeBatch() *treeBatch {
+	return &treeBatch{kinds: make(map[string]string)}
+}
+
+// add records a change to p, folding it into an earlier change in the
+// same batch: created then removed cancels out, removed then created is a
+// modification and a created file stays created however often written
+func (b *treeBatch) add(p, kind string) {
+	b.settleMove()
+	prev, seen := b.kinds[p]
+	switch {
+	case !seen:
+		b.order = append(b.order, p)
+	case prev == "created" && kind == "removed":
+		kind = ""
+	case prev == "created":
+		kind = "created"
+	case prev == "removed" && kind == "created":
+		kind = "modified"
+	}
+	b.kinds[p] = kind
+}
+
+// settleMove turns a rename whose new name never showed up, a move out of
+// the workspace, into a removal
+func (b *treeBatch) settleMove() {
+	if moved := b.moved; moved != "" {
+		b.moved = ""
+		b.add(moved, "removed")
+	}
+}
+
+// flush emits the batch, one event per kind of change
+func (b *treeBatch) flush(emit func(event string, data any)) {
+	b.settleMove()
+	if len(b.renames) > 0 {
+		emit("fs:renamed", b.renames)
+	}
+	for _, kind := range []string{"removed", "created", "modified"} {
+		var paths []string
+		for _, p := range b.order {
+			if b.kinds[p] == kind {
+				paths = append(paths, p)
+			}
+		}
+		if len(paths) > 0 {
+			emit("fs:"+kind, paths)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// defaultUndoMemory caps the snapshot bytes kept across all documents
+	defaultUndoMemory = 50 << 20
+	// coalesceWindow merges pushes that arrive in quick succession
+	coalesceWindow = 500 * time.Millisecond
+)
+
+// UndoResult is returned by Undo and Redo. OK is false when there was
+// nothing to step to.
+type UndoResult struct {
+	Content string `json:"content"`
+	OK      bool   `json:"ok"`
+}
+
+// snapshot is a saved document state
+type snapshot struct {
+	content string
+	at      time.Time
+	seq     uint64 // global push order, used to evict the oldest first
+}
+
+// undoStack is the history of one document. undo ends with the current
+// state; redo holds states that were undone, most recent last.
+type undoStack struct {
+	undo []snapshot
+	redo []snapshot
+}
+
+// undoManager keeps snapshot histories for all documents within a shared
+// memory budget
+type undoManager struct {
+	mu     sync.Mutex
+	stacks map[string]*undoStack
+	limit  int
+	used   int
+	seq    uint64
+	now    func() time.Time
+}
+
+func newUndoManager(limit int) *undoManager {
+	return &undoManager{stacks: make(map[string]*undoStack), limit: limit, now: time.Now}
+}
+
+// push records content as the latest state of a document. Pushing the
+// current state again does nothing, and any push clears the redo stack.
+// With coalesce set, a push within coalesceWindow of the previous one
+// replaces it.
+func (m *undoManager) push(id, content string, coalesce bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := m.stacks[id]
+	if s == nil {
+		s = &undoStack{}
+		m.stacks[id] = s
+	}
+	now := m.now()
+	if n := len(s.undo); n > 0 {
+		top := &s.undo[n-1]
+		if top.content == content {
+			return
+		}
+		// the bottom snapshot is the base state and is never replaced
+		if coalesce && n > 1 && now.Sub(top.at) < coalesceWindow {
+			m.used += len(content) - len(top.content)
+			top.content = content
+			top.at = now
+			m.clearRedo(s)
+			m.evict()
+			return
+		}
+	}
+	m.clearRedo(s)
+	m.seq++
+	s.undo = append(s.undo, snapshot{content: content, at: now, seq: m.seq})
+	m.used += len(content)
+	m.evict()
+}
+
+func (m *undoManager) clearRedo(s *undoStack) {
+	for _, snap := range s.redo {
+		m.used -= len(snap.content)
+	}
+	s.redo = nil
+}
+
+// evict drops the oldest snapshots across all documents until the history
+// fits in the memory limit. Undone states count against it too and go from
+// the far end of the redo stack. The current state of each document is kept.
+func (m *undoManager) evict() {
+	for m.used > m.limit {
+		var oldest *undoStack
+		var oldestSeq uint64
+		redo := false
+		for _, s := range m.stacks {
+			if len(s.undo) > 1 && (oldest == nil || s.undo[0].seq < oldestSeq) {
+				oldest, oldestSeq, redo = s, s.undo[0].seq, false
+			}
+			if len(s.redo) > 0 && (oldest == nil || s.redo[0].seq < oldestSeq) {
+				oldest, oldestSeq, redo = s, s.redo[0].seq, true
+			}
+		}
+		if oldest == nil {
+			// only current states are left, and those are needed
+			return
+		}
+		if redo {
+			m.used -= len(oldest.redo[0].content)
+			oldest.redo = oldest.redo[1:]
+		} else {
+			m.used -= len(oldest.undo[0].content)
+			oldest.undo = oldest.undo[1:]
+		}
+	}
+}
+
+// step moves one state back (undo) or forward (redo)
+func (m *undoManager) step(id string, back bool) UndoResult {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := m.stacks[id]
+	if s == nil {
+		return UndoResult{}
+	}
+	if back {
+		if len(s.undo) < 2 {
+			return UndoResult{}
+		}
+		top := s.undo[len(s.undo)-1]
+		s.undo = s.undo[:len(s.undo)-1]
+		s.redo = append(s.redo, top)
+	} else {
+		if len(s.redo) == 0 {
+			return UndoResult{}
+		}
+		top := s.redo[len(s.redo)-1]
+		s.redo = s.redo[:len(s.redo)-1]
+		s.undo = append(s.undo, top)
+	}
+	// a state that was stepped to should not coalesce with the next push
+	cur := &s.undo[len(s.undo)-1]
+	cur.at = time.Time{}
+	return UndoResult{Content: cur.content, OK: true}
+}
+
+// forget drops the history of a closed document
+func (m *undoManager) forget(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if s := m.stacks[id]; s != nil {
+		for _, snap := range s.undo {
+			m.used -= len(snap.content)
+		}
+		m.clearRedo(s)
+		delete(m.stacks, id)
+	}
+}
+
+// setLimit changes the memory budget, evicting history if it shrank
+func (m *undoManager) setLimit(limit int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.limit = limit
+	m.evict()
+}
+
+// PushSnapshot records the content of a document in its undo history
+func (a *App) PushSnapshot(docID string, content string) {
+	a.undo.push(docID, content, true)
+}
+
+// Undo steps a document back to its previous snapshot
+func (a *App) Undo(docID string) UndoResult {
+	return a.applyUndo(docID, a.undo.step(docID, true))
+}
+
+// Redo steps a document forward to the snapshot it was undone from
+func (a *App) Redo(docID string) UndoResult {
+	return a.applyUndo(docID, a.undo.step(docID, false))
+}
+
+// applyUndo puts the stepped-to content into the document buffer
+func (a *App) applyUndo(docID string, res UndoResult) UndoResult {
+	if !res.OK {
+		return res
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if d, ok := a.docs[docID]; ok && !d.LargeFile && d.Content != res.Content {
+		d.Content = res.Content
+		d.Dirty = true
+		d.version++
+	}
+	return res
+}
+
+// SetUndoMemoryLimit sets how many megabytes of snapshots are kept across
+// all documents
+func (a *App) SetUndoMemoryLimit(megabytes int) {
+	if megabytes < 1 {
+		megabytes = 1
+	}
+	a.undo.setLimit(megabytes << 20)
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// fakeClock is a now for undoManager that only moves when told to
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newTestUndo(limit int) (*undoManager, *fakeClock) {
+	m := newUndoManager(limit)
+	clock := &fakeClock{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	m.now = clock.now
+	return m, clock
+}
+
+// undoAll steps back as far as the history goes, returning each state
+func undoAll(m *undoManager, id string) []string {
+	var states []string
+	for {
+		res := m.step(id, true)
+		if !res.OK {
+			return states
+		}
+		states = append(states, res.Content)
+	}
+}
+
+func TestUndoCoalescing(t *testing.T) {
+	tests := []struct {
+		name string
+		gap  time.Duration
+		want []string // states undone to, from a, ab, abc pushed gap apart
+	}{
+		{"inside the window", 100 * time.Millisecond, []string{""}},
+		{"just inside", coalesceWindow - time.Millisecond, []string{""}},
+		{"at the window", coalesceWindow, []string{"ab", "a", ""}},
+		{"outside the window", time.Second, []string{"ab", "a", ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, clock := newTestUndo(1 << 20)
+			m.push("doc", "", true)
+			for _, s := range []string{"a", "ab", "abc"} {
+				clock.advance(tt.gap)
+				m.push("doc", s, true)
+			}
+			got := undoAll(m, "doc")
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("undid to %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUndoBaseNotCoalesced(t *testing.T) {
+	m, _ := newTestUndo(1 << 20)
+	m.push("doc", "base", true)
+	m.push("doc", "edit", true)
+	if res := m.step("doc", true); !res.OK || res.Content != "base" {
+		t.Fatalf("undo = %+v, want base", res)
+	}
+}
+
+func TestUndoRedoClearedByEdit(t *testing.T) {
+	m, clock := newTestUndo(1 << 20)
+	for _, s := range []string{"one", "two", "three"} {
+		m.push("doc", s, true)
+		clock.advance(time.Second)
+	}
+	m.step("doc", true)
+	if res := m.step("doc", false); !res.OK || res.Content != "three" {
+		t.Fatalf("redo = %+v, want three", res)
+	}
+	m.step("doc", true)
+	m.push("doc", "other", true)
+	if res := m.step("doc", false); res.OK {
+		t.Fatalf("redo after an edit = %+v, want nothing", res)
+	}
+	if m.used != len("one")+len("two")+len("other") {
+		t.Errorf("used = %d after the redo states were dropped", m.used)
+	}
+}
+
+func TestUndoStepNotCoalesced(t *testing.T) {
+	m, clock := newTestUndo(1 << 20)
+	m.push("doc", "a", true)
+	clock.advance(time.Second)
+	m.push("doc", "ab", true)
+	m.step("doc", true)
+	// straight after undoing, an edit is a state of its own
+	m.push("doc", "ax", true)
+	if res := m.step("doc", true); !res.OK || res.Content != "a" {
+		t.Fatalf("undo = %+v, want a", res)
+	}
+}
+
+func TestUndoEvictsOldestFirst(t *testing.T) {
+	m, clock := newTestUndo(12)
+	// pushed in the order a1 b1 a2 b2 a3, 2 bytes each
+	for _, p := range [][2]string{{"a", "a1"}, {"b", "b1"}, {"a", "a2"}, {"b", "b2"}, {"a", "a3"}} {
+		m.push(p[0], p[1], true)
+		clock.advance(time.Second)
+	}
+	if m.used != 10 {
+		t.Fatalf("used = %d, want 10", m.used)
+	}
+	m.setLimit(6)
+	// a1 and b1 are the oldest and go first, leaving a2 a3 and b2
+	if got := undoAll(m, "a"); len(got) != 1 || got[0] != "a2" {
+		t.Errorf("a undid to %q, want [a2]", got)
+	}
+	if got := undoAll(m, "b"); len(got) != 0 {
+		t.Errorf("b undid to %q, want nothing", got)
+	}
+	if m.used > 6 {
+		t.Errorf("used = %d, over the limit of 6", m.used)
+	}
+}
+
+func TestUndoKeepsCurrentStates(t *testing.T) {
+	m, _ := newTestUndo(1)
+	m.push("a", "current a", true)
+	m.push("b", "current b", true)
+	// over the limit, but a document's current state is never dropped
+	if len(m.stacks["a"].undo) != 1 || len(m.stacks["b"].undo) != 1 {
+		t.Fatalf("current states evicted: a %d, b %d", len(m.stacks["a"].undo), len(m.stacks["b"].undo))
+	}
+}
+
+func TestUndoEvictsRedo(t *testing.T) {
+	m, clock := newTestUndo(100)
+	for _, c := range []string{"base", "1111111111", "2222222222", "3333333333"} {
+		m.push("a", c, true)
+		clock.advance(time.Second)
+	}
+	// everything undone is now on the redo side
+	undoAll(m, "a")
+	m.push("b", "bb", true)
+	m.setLimit(16)
+	if m.used > 16 {
+		t.Fatalf("used = %d, over the limit of 16", m.used)
+	}
+	// the far end of the redo stack goes first, the next redo is kept
+	var redone []string
+	for {
+		res := m.step("a", false)
+		if !res.OK {
+			break
+		}
+		redone = append(redone, res.Content)
+	}
+	if !slices.Equal(redone, []string{"1111111111"}) {
+		t.Errorf("redid %q, want [1111111111]", redone)
+	}
+	if got := m.step("b", true); got.OK {
+		t.Errorf("b undid to %q past its only state", got.Content)
+	}
+}
@@ -0,0 +1,805 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"cmp"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	goruntime "runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// version is the version of this build, set when releasing with
+// -ldflags "-X main.version=1.2.3". Builds without one are not updated.
+var version = "dev"
+
+const (
+	// defaultUpdateRepo is the GitHub repository releases are checked in
+	defaultUpdateRepo = "sean5446/wailspad"
+	// updateDelay is how long after startup updates are first checked for,
+	// so the check does not compete with opening the session
+	updateDelay = 10 * time.Second
+	// maxUpdateSize bounds a download and the executable taken out of it
+	maxUpdateSize = 512 << 20
+)
+
+// updateAPI is where the GitHub REST API is reached
+var updateAPI = "https://api.github.com"
+
+var (
+	// updateClient gives up on a check that GitHub does not answer
+	updateClient = &http.Client{Timeout: 15 * time.Second}
+	// downloadClient only bounds the wait for a reply, a download taking as
+	// long as it takes until it is cancelled
+	downloadClient = &http.Client{Transport: &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		ResponseHeaderTimeout: 30 * time.Second,
+	}}
+)
+
+// updateRepoPattern is an owner/repo pair as GitHub allows them
+var updateRepoPattern = regexp.MustCompile(`^[A-Za-z0-9](?:[A-Za-z0-9-]*[A-Za-z0-9])?/[A-Za-z0-9._-]+$`)
+
+// UpdateInfo is the result of CheckForUpdates
+type UpdateInfo struct {
+	// Checked is false when there was no finding out, Message saying why
+	Checked   bool   `json:"checked"`
+	Available bool   `json:"available"`
+	Current   string `json:"current"`
+	Latest    string `json:"latest,omitempty"`
+	Notes     string `json:"notes,omitempty"`
+	PageURL   string `json:"pageUrl,omitempty"`
+	// AssetURL is the download for this OS and architecture, empty when
+	// the release has none
+	AssetURL  string `json:"assetUrl,omitempty"`
+	AssetName string `json:"assetName,omitempty"`
+	AssetSize int64  `json:"assetSize,omitempty"`
+	Message   string `json:"message,omitempty"`
+}
+
+// UpdateProgress is sent as update:progress while DownloadUpdate runs
+type UpdateProgress struct {
+	Bytes int64 `json:"bytes"`
+	Total int64 `json:"total"` // 0 when the size is not known
+	Done  bool  `json:"done"`
+}
+
+// updateState is what is known of the update the last check found
+type updateState struct {
+	info        UpdateInfo
+	checksumURL string
+	downloaded  string // the verified executable, once downloaded
+	staged      string // its copy next to ours, swapped in on exit
+}
+
+// githubRelease is the part of a GitHub release used here
+type githubRelease struct {
+	TagName string        `json:"tag_name"`
+	Body    string        `json:"body"`
+	HTMLURL string        `json:"html_url"`
+	Assets  []githubAsset `json:"assets"`
+}
+
+type githubAsset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+	Size int64  `json:"size"`
+}
+
+// CheckForUpdates looks up the latest release of the repository in the
+// settings and compares it with this build. Failing to check is not an
+// error: Checked is left false and Message says what went wrong.
+func (a *App) CheckForUpdates() UpdateInfo {
+	a.mu.Lock()
+	settings := a.settings
+	parent := a.bg
+	a.mu.Unlock()
+	if parent == nil {
+		parent = context.Background()
+	}
+
+	info := UpdateInfo{Current: version}
+	if !settings.UpdateCheck {
+		info.Message = "update checks are turned off in the settings"
+		return info
+	}
+	current, ok := parseSemver(version)
+	if !ok {
+		info.Message = "this is a development build, which is not updated"
+		return info
+	}
+	rel, err := fetchRelease(parent, settings.UpdateRepo)
+	if err != nil {
+		a.log.Warn("update check failed", "repo", settings.UpdateRepo, "err", err)
+		info.Message = "couldn't check for updates: " + err.Error()
+		return info
+	}
+	latest, ok := parseSemver(rel.TagName)
+	if !ok {
+		a.log.Warn("latest release is not a version", "repo", settings.UpdateRepo, "tag", rel.TagName)
+		info.Message = fmt.Sprintf("couldn't check for updates: the latest release, %q, is not a version number", rel.TagName)
+		return info
+	}
+
+	info.Checked = true
+	info.Latest = strings.TrimPrefix(rel.TagName, "v")
+	info.Notes = rel.Body
+	info.PageURL = rel.HTMLURL
+	info.Available = latest.compare(current) > 0
+	a.log.Info("checked for updates", "current", version, "latest", rel.TagName, "available", info.Available)
+	if !info.Available {
+		return info
+	}
+	state := &updateState{info: info}
+	if asset, ok := pickAsset(rel.Assets, goruntime.GOOS, goruntime.GOARCH); ok {
+		info.AssetURL, info.AssetName, info.AssetSize = asset.URL, asset.Name, asset.Size
+		if sums, ok := checksumAsset(rel.Assets, asset.Name); ok {
+			state.checksumURL = sums.URL
+		}
+		state.info = info
+	} else {
+		info.Message = fmt.Sprintf("the release has no download for %s/%s", goruntime.GOOS, goruntime.GOARCH)
+	}
+
+	a.mu.Lock()
+	// checking again keeps a download of the same release
+	if a.update == nil || a.update.info.AssetURL != info.AssetURL {
+		a.clearUpdateLocked()
+		a.update = state
+	}
+	a.mu.Unlock()
+	return info
+}
+
+// DownloadUpdate downloads the asset the last CheckForUpdates found,
+// sending update:progress as it goes, and checks it against the checksums
+// published with the release. Only that asset can be downloaded, and one
+// the release has no checksum for is refused.
+func (a *App) DownloadUpdate(url string) error {
+	a.mu.Lock()
+	u := a.update
+	switch {
+	case u == nil || url == "" || u.info.AssetURL != url:
+		a.mu.Unlock()
+		return errors.New("check for updates first, only the download found can be installed")
+	case !a.settings.UpdateCheck:
+		a.mu.Unlock()
+		return errors.New("updates are turned off in the settings")
+	case u.checksumURL == "":
+		a.mu.Unlock()
+		return errors.New("the release publishes no checksum for its download, so it cannot be verified")
+	case a.updateCancel != nil:
+		a.mu.Unlock()
+		return errors.New("the update is already downloading")
+	}
+	parent := a.bg
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithCancel(parent)
+	a.updateCancel = cancel
+	info, checksumURL := u.info, u.checksumURL
+	a.mu.Unlock()
+	defer func() {
+		a.mu.Lock()
+		a.updateCancel = nil
+		a.mu.Unlock()
+		cancel()
+	}()
+
+	exe, err := a.downloadUpdate(ctx, info, checksumURL)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.Canceled) {
+			return ErrCancelled
+		}
+		a.log.Warn("update download failed", "asset", info.AssetName, "err", err)
+		return err
+	}
+	a.mu.Lock()
+	if a.update != u {
+		// a check for a newer release came in meanwhile
+		a.mu.Unlock()
+		os.RemoveAll(filepath.Dir(exe))
+		return errors.New("a newer release was found while downloading, download that instead")
+	}
+	if u.downloaded != "" {
+		os.RemoveAll(filepath.Dir(u.downloaded))
+	}
+	u.downloaded = exe
+	a.mu.Unlock()
+	a.log.Info("downloaded update", "version", info.Latest, "path", exe)
+	runtime.EventsEmit(a.ctx, "update:progress", UpdateProgress{Bytes: info.AssetSize, Total: info.AssetSize, Done: true})
+	return nil
+}
+
+// CancelUpdateDownload stops the download DownloadUpdate is waiting on
+func (a *App) CancelUpdateDownload() {
+	a.mu.Lock()
+	cancel := a.updateCancel
+	a.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+}
+
+// ApplyUpdateOnExit puts the downloaded update next to the running
+// executable, to replace it when the app exits. Copying it now rather than
+// on exit means a folder that cannot be written to is reported while the
+// user can still do something about it.
+func (a *App) ApplyUpdateOnExit() error {
+	a.mu.Lock()
+	u := a.update
+	var downloaded string
+	if u != nil {
+		downloaded = u.downloaded
+	}
+	a.mu.Unlock()
+	if downloaded == "" {
+		return errors.New("download the update first")
+	}
+	exe, err := currentExecutable()
+	if err != nil {
+		return err
+	}
+	staged := exe + ".new"
+	if err := copyExecutable(downloaded, staged); err != nil {
+		os.Remove(staged)
+		return fmt.Errorf("wailspad cannot replace itself in %s, install the update by hand: %w", filepath.Dir(exe), err)
+	}
+	a.mu.Lock()
+	u.staged = staged
+	a.mu.Unlock()
+	a.log.Info("update staged for exit", "version", u.info.Latest, "path", staged)
+	return nil
+}
+
+// checkForUpdatesLater checks for updates a little after startup and tells
+// the frontend with update:available when there is one
+func (a *App) checkForUpdatesLater(ctx context.Context) {
+	select {
+	case <-ctx.Done():
+		return
+	case <-time.After(updateDelay):
+	}
+	if info := a.CheckForUpdates(); info.Available {
+		runtime.EventsEmit(a.ctx, "update:available", info)
+	}
+}
+
+// installUpdate swaps in the staged update. It runs last in shutdown.
+func (a *App) installUpdate() {
+	a.mu.Lock()
+	var staged string
+	if a.update != nil {
+		staged = a.update.staged
+	}
+	a.mu.Unlock()
+	if staged == "" {
+		return
+	}
+	exe, err := currentExecutable()
+	if err == nil {
+		err = replaceExecutable(staged, exe)
+	}
+	if err != nil {
+		a.log.Error("installing the update failed", "err", err)
+		return
+	}
+	a.log.Info("update installed", "path", exe)
+}
+
+// clearUpdateLocked forgets the update found last, removing what was
+// downloaded of it. a.mu must be held.
+func (a *App) clearUpdateLocked() {
+	if a.update == nil {
+		return
+	}
+	if a.update.downloaded != "" {
+		os.RemoveAll(filepath.Dir(a.update.downloaded))
+	}
+	if a.update.staged != "" {
+		os.Remove(a.update.staged)
+	}
+	a.update = nil
+}
+
+// downloadUpdate downloads and verifies the asset into a temporary folder
+// and returns the executable in it
+func (a *App) downloadUpdate(ctx context.Context, info UpdateInfo, checksumURL string) (string, error) {
+	want, err := fetchChecksum(ctx, checksumURL, info.AssetName)
+	if err != nil {
+		return "", err
+	}
+	if err := validFileName(info.AssetName); err != nil {
+		return "", err
+	}
+	dir, err := os.MkdirTemp("", "wailspad-update-")
+	if err != nil {
+		return "", err
+	}
+	exe, err := func() (string, error) {
+		asset := filepath.Join(dir, info.AssetName)
+		last := time.Now()
+		got, err := downloadFile(ctx, info.AssetURL, asset, func(read, total int64) {
+			if time.Since(last) < 250*time.Millisecond {
+				return
+			}
+			last = time.Now()
+			runtime.EventsEmit(a.ctx, "update:progress", UpdateProgress{Bytes: read, Total: total})
+		})
+		if err != nil {
+			return "", err
+		}
+		if !strings.EqualFold(got, want) {
+			return "", fmt.Errorf("%s does not match its published checksum, it may have been tampered with", info.AssetName)
+		}
+		return extractExecutable(asset, dir)
+	}()
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	return exe, nil
+}
+
+// fetchRelease asks GitHub for the latest release of repo. Its errors are
+// worded to be shown as they are.
+func fetchRelease(ctx context.Context, repo string) (githubRelease, error) {
+	var rel githubRelease
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, updateAPI+"/repos/"+repo+"/releases/latest", nil)
+	if err != nil {
+		return rel, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("User-Agent", "wailspad/"+version)
+	resp, err := updateClient.Do(req)
+	if err != nil {
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return rel, errors.New("GitHub did not answer in time")
+		}
+		return rel, errors.New("GitHub could not be reached")
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return rel, fmt.Errorf("%s has no releases", repo)
+	case resp.StatusCode == http.StatusTooManyRequests,
+		resp.StatusCode == http.StatusForbidden && resp.Header.Get("X-RateLimit-Remaining") == "0":
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			return rel, fmt.Errorf("GitHub's rate limit was reached, try again after %s", time.Unix(reset, 0).Format("15:04"))
+		}
+		return rel, errors.New("GitHub's rate limit was reached, try again later")
+	case resp.StatusCode != http.StatusOK:
+		return rel, fmt.Errorf("GitHub answered %s", resp.Status)
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 4<<20)).Decode(&rel); err != nil {
+		return rel, errors.New("GitHub's answer could not be read")
+	}
+	return rel, nil
+}
+
+// fetchChecksum returns the SHA-256 the checksums file at url gives for
+// name. Both a sha256sum listing and a file holding just the one sum are
+// understood.
+func fetchChecksum(ctx context.Context, url, name string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", "wailspad/"+version)
+	resp, err := downloadClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("downloading the checksums failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("downloading the checksums failed: %s", resp.Status)
+	}
+	scanner := bufio.NewScanner(io.LimitReader(resp.Body, 1<<20))
+	lone := ""
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		switch {
+		case len(fields) == 1 && isSHA256(fields[0]):
+			lone = fields[0]
+		case len(fields) >= 2 && isSHA256(fields[0]) && strings.TrimPrefix(fields[len(fields)-1], "*") == name:
+			// sha256sum marks binary mode with a * before the name
+			return fields[0], nil
+		}
+	}
+	if lone != "" {
+		return lone, nil
+	}
+	return "", fmt.Errorf("the checksums published with the release do not cover %s", name)
+}
+
+func isSHA256(s string) bool {
+	b, err := hex.DecodeString(s)
+	return err == nil && len(b) == sha256.Size
+}
+
+// downloadFile streams url into path and returns its SHA-256 in hex
+func downloadFile(ctx context.Context, url, path string, progress func(read, total int64)) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", "wailspad/"+version)
+	resp, err := downloadClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("downloading the update failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("downloading the update failed: %s", resp.Status)
+	}
+	if resp.ContentLength > maxUpdateSize {
+		return "", fmt.Errorf("the update is too large at %d bytes", resp.ContentLength)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	w := io.MultiWriter(f, h)
+	buf := make([]byte, 64<<10)
+	var read int64
+	for {
+		n, err := resp.Body.Read(buf)
+		if n > 0 {
+			if read += int64(n); read > maxUpdateSize {
+				return "", errors.New("the update is too large")
+			}
+			if _, err := w.Write(buf[:n]); err != nil {
+				return "", err
+			}
+			progress(read, max(resp.ContentLength, 0))
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("downloading the update failed: %w", err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// extractExecutable returns the executable of the downloaded asset, taking
+// it out of a zip or tar.gz archive into dir first
+func extractExecutable(asset, dir string) (string, error) {
+	name := strings.ToLower(filepath.Base(asset))
+	out := filepath.Join(dir, executableName())
+	var err error
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		err = extractZip(asset, out)
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		err = extractTarGz(asset, out)
+	default:
+		// the asset is the executable itself
+		out = asset
+	}
+	if err != nil {
+		return "", err
+	}
+	if err := os.Chmod(out, 0o755); err != nil {
+		return "", err
+	}
+	return out, nil
+}
+
+func extractZip(archive, out string) error {
+	r, err := zip.OpenReader(archive)
+	if err != nil {
+		return fmt.Errorf("the update is not a valid zip file: %w", err)
+	}
+	defer r.Close()
+	for _, f := range r.File {
+		if !f.Mode().IsRegular() || !isExecutableName(path.Base(f.Name)) {
+			continue
+		}
+		src, err := f.Open()
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+		return writeExtracted(src, out)
+	}
+	return errors.New("the update holds no wailspad executable")
+}
+
+func extractTarGz(archive, out string) error {
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("the update is not a valid tar.gz file: %w", err)
+	}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return errors.New("the update holds no wailspad executable")
+		}
+		if err != nil {
+			return fmt.Errorf("the update is not a valid tar.gz file: %w", err)
+		}
+		if hdr.Typeflag == tar.TypeReg && isExecutableName(path.Base(hdr.Name)) {
+			return writeExtracted(tr, out)
+		}
+	}
+}
+
+// writeExtracted writes an executable read out of an archive to out
+func writeExtracted(src io.Reader, out string) error {
+	dst, err := os.OpenFile(out, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o700)
+	if err != nil {
+		return err
+	}
+	n, err := io.Copy(dst, io.LimitReader(src, maxUpdateSize+1))
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil && n > maxUpdateSize {
+		err = errors.New("the executable in the update is too large")
+	}
+	return err
+}
+
+// copyExecutable copies the executable from to to, replacing it
+func copyExecutable(from, to string) error {
+	src, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(to, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o755)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, src)
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		// O_CREATE leaves the mode of a file left from before alone
+		err = os.Chmod(to, 0o755)
+	}
+	return err
+}
+
+// currentExecutable returns the path of the running executable, symlinks
+// resolved so the file itself is replaced rather than the link
+func currentExecutable() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(exe)
+}
+
+// executableName is the file name of the running executable
+func executableName() string {
+	if exe, err := os.Executable(); err == nil {
+		return filepath.Base(exe)
+	}
+	if goruntime.GOOS == "windows" {
+		return "wailspad.exe"
+	}
+	return "wailspad"
+}
+
+// isExecutableName reports whether an archive entry is our executable
+func isExecutableName(name string) bool {
+	return name == executableName() || strings.EqualFold(strings.TrimSuffix(name, ".exe"), "wailspad")
+}
+
+// updateOSNames and updateArchNames are how release assets name the
+// platforms they are built for
+var (
+	updateOSNames = map[string][]string{
+		"windows": {"windows", "win", "win64", "win32"},
+		"darwin":  {"darwin", "macos", "mac", "osx"},
+		"linux":   {"linux"},
+	}
+	updateArchNames = map[string][]string{
+		"amd64": {"amd64", "x64", "win64"},
+		"arm64": {"arm64", "aarch64"},
+		"386":   {"386", "i386", "x86", "win32"},
+	}
+)
+
+// installerSuffixes mark assets that install rather than hold an
+// executable to swap in
+var installerSuffixes = []string{".msi", ".dmg", ".pkg", ".deb", ".rpm", ".appimage", ".sha256", ".sig", ".asc", ".txt"}
+
+// pickAsset chooses the release asset built for goos and goarch. An asset
+// naming the OS but no architecture is taken when there is nothing better,
+// as is a universal macOS build.
+func pickAsset(assets []githubAsset, goos, goarch string) (githubAsset, bool) {
+	var best githubAsset
+	bestScore := 0
+	for _, asset := range assets {
+		name := strings.ToLower(asset.Name)
+		if strings.Contains(name, "installer") || strings.Contains(name, "setup") || hasSuffixAny(name, installerSuffixes) {
+			continue
+		}
+		// x86_64 would be split up with the rest
+		name = strings.NewReplacer("x86_64", "amd64", "x86-64", "amd64").Replace(name)
+		tokens := strings.FieldsFunc(name, func(r rune) bool {
+			return r == '-' || r == '_' || r == '.' || r == ' '
+		})
+		if !anyToken(tokens, updateOSNames[goos]) && !(goos == "windows" && strings.HasSuffix(name, ".exe")) {
+			continue
+		}
+		score := 1
+		switch {
+		case anyToken(tokens, updateArchNames[goarch]):
+			score = 3
+		case goos == "darwin" && anyToken(tokens, []string{"universal"}):
+			score = 2
+		default:
+			for arch, names := range updateArchNames {
+				if arch != goarch && anyToken(tokens, names) {
+					// built for another architecture
+					score = 0
+				}
+			}
+		}
+		if score > bestScore {
+			best, bestScore = asset, score
+		}
+	}
+	return best, bestScore > 0
+}
+
+// checksumAsset finds the checksums file covering the asset name: one of
+// its own, name.sha256, or a listing for the whole release
+func checksumAsset(assets []githubAsset, name string) (githubAsset, bool) {
+	for _, a := range assets {
+		if a.Name == name+".sha256" || a.Name == name+".sha256sum" {
+			return a, true
+		}
+	}
+	for _, a := range assets {
+		lower := strings.ToLower(a.Name)
+		if strings.Contains(lower, "checksums") || strings.HasPrefix(lower, "sha256sums") {
+			return a, true
+		}
+	}
+	return githubAsset{}, false
+}
+
+func anyToken(tokens, names []string) bool {
+	for _, t := range tokens {
+		if contains(names, t) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasSuffixAny(s string, suffixes []string) bool {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(s, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// validUpdateRepo checks the owner/repo of the settings
+func validUpdateRepo(repo string) error {
+	if !updateRepoPattern.MatchString(repo) {
+		return errors.New("must be a GitHub repository as owner/repo")
+	}
+	return nil
+}
+
+// semver is a semantic version. Build metadata is dropped since it does
+// not take part in comparing.
+type semver struct {
+	major, minor, patch int
+	pre                 []string // the dot separated parts after -
+}
+
+// parseSemver parses a version like v1.2.3-beta.1. Missing minor and patch
+// numbers count as 0, as release tags like v2.1 are common.
+func parseSemver(s string) (semver, bool) {
+	var v semver
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	s, _, _ = strings.Cut(s, "+")
+	core, pre, hasPre := strings.Cut(s, "-")
+	parts := strings.Split(core, ".")
+	if len(parts) > 3 {
+		return v, false
+	}
+	nums := [3]int{}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 || strings.HasPrefix(p, "+") {
+			return v, false
+		}
+		nums[i] = n
+	}
+	v.major, v.minor, v.patch = nums[0], nums[1], nums[2]
+	if hasPre {
+		v.pre = strings.Split(pre, ".")
+		for _, p := range v.pre {
+			if p == "" {
+				return v, false
+			}
+		}
+	}
+	return v, true
+}
+
+// compare returns -1, 0 or 1 as v is older than, the same as or newer than
+// w. A pre-release comes before the release it leads up to.
+func (v semver) compare(w semver) int {
+	if c := cmp.Compare(v.major, w.major); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(v.minor, w.minor); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(v.patch, w.patch); c != 0 {
+		return c
+	}
+	switch {
+	case len(v.pre) == 0 && len(w.pre) == 0:
+		return 0
+	case len(v.pre) == 0:
+		return 1
+	case len(w.pre) == 0:
+		return -1
+	}
+	for i := 0; i < len(v.pre) && i < len(w.pre); i++ {
+		a, aErr := strconv.Atoi(v.pre[i])
+		b, bErr := strconv.Atoi(w.pre[i])
+		var c int
+		switch {
+		case aErr == nil && bErr == nil:
+			c = cmp.Compare(a, b)
+		case aErr == nil:
+			// numeric parts come before alphanumeric ones
+			c = -1
+		case bErr == nil:
+			c = 1
+		default:
+			c = strings.Compare(v.pre[i], w.pre[i])
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(len(v.pre), len(w.pre))
+}
@@ -0,0 +1,11 @@
+//go:build !windows
+
+package main
+
+import "os"
+
+// replaceExecutable renames staged over exe. The running process keeps the
+// file it was started from open, so this is safe before it exits.
+func replaceExecutable(staged, exe string) error {
+	return os.Rename(staged, exe)
+}
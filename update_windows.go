@@ -0,0 +1,49 @@
+//go:build windows
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// replaceExecutable leaves replacing exe to a batch script, since Windows
+// keeps the executable of a running process locked. The script waits for
+// this process to exit, gives up after a minute, moves staged over exe and
+// deletes itself.
+func replaceExecutable(staged, exe string) error {
+	pid := os.Getpid()
+	// % would start a variable in a batch file
+	quote := func(p string) string { return `"` + strings.ReplaceAll(p, "%", "%%") + `"` }
+	script := fmt.Sprintf(`@echo off
+set tries=0
+:wait
+tasklist /FI "PID eq %[1]d" /NH 2>NUL | find " %[1]d " >NUL
+if errorlevel 1 goto replace
+set /a tries+=1
+if %%tries%% geq 60 goto done
+timeout /t 1 /nobreak >NUL
+goto wait
+:replace
+move /y %[2]s %[3]s >NUL
+:done
+del "%%~f0"
+`, pid, quote(staged), quote(exe))
+	path := filepath.Join(os.TempDir(), fmt.Sprintf("wailspad-update-%d.cmd", pid))
+	if err := os.WriteFile(path, []byte(strings.ReplaceAll(script, "\n", "\r\n")), 0o600); err != nil {
+		return err
+	}
+	const createNoWindow = 0x08000000
+	cmd := exec.Command(filepath.Join(os.Getenv("SystemRoot"), "System32", "cmd.exe"))
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CmdLine:       `cmd.exe /c "` + path + `"`,
+		HideWindow:    true,
+		CreationFlags: createNoWindow,
+	}
+	// the script outlives this process, which must not wait on it
+	return cmd.Start()
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// watchInterval is how often the open file is checked for outside changes
+const watchInterval = time.Second
+
+// FileChange is emitted as file:changed-externally when the open file is
+// modified or removed by another program
+type FileChange struct {
+	ID         string    `json:"id"`
+	Path       string    `json:"path"`
+	OldModTime time.Time `json:"oldModTime"`
+	NewModTime time.Time `json:"newModTime"`
+	Removed    bool      `json:"removed"`
+	Dirty      bool      `json:"dirty"`
+	// Reloaded holds the new contents when the buffer was reloaded automatically
+	Reloaded *Document `json:"reloaded,omitempty"`
+}
+
+// fileStamp identifies a version of a file on disk
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+func statStamp(path string) fileStamp {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{modTime: info.ModTime(), size: info.Size()}
+}
+
+// fileWatcher polls a single file until stopped
+type fileWatcher struct {
+	path string
+	stop context.CancelFunc
+}
+
+// SetAutoReload controls whether clean buffers follow outside changes
+// without asking
+func (a *App) SetAutoReload(enabled bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.autoReload = enabled
+}
+
+// watchLocked records the on-disk state of the document and makes sure its
+// file is the one being polled, replacing any watcher for a previous path.
+// a.mu must be held.
+func (a *App) watchLocked(d *Document) {
+	d.stamp = statStamp(d.Path)
+	if d.watcher != nil && d.watcher.path == d.Path {
+		return
+	}
+	d.stopWatcher()
+	if a.bg == nil || d.Path == "" {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(a.bg)
+	d.watcher = &fileWatcher{path: d.Path, stop: cancel}
+	id, path := d.ID, d.Path
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		ticker := time.NewTicker(watchInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				a.checkFile(id, path)
+			}
+		}
+	}()
+}
+
+// stopWatcher tears down the document's watcher, if any
+func (d *Document) stopWatcher() {
+	if d.watcher != nil {
+		d.watcher.stop()
+		d.watcher = nil
+	}
+}
+
+// checkFile compares a document's file against its last known state and
+// reports a change
+func (a *App) checkFile(id string, path string) {
+	// hold off while one of our own saves is in flight
+	a.fileMu.Lock()
+	defer a.fileMu.Unlock()
+
+	cur := statStamp(path)
+	a.mu.Lock()
+	d, ok := a.docs[id]
+	if !ok || d.Path != path || cur == d.stamp {
+		a.mu.Unlock()
+		return
+	}
+	old := d.stamp
+	d.stamp = cur
+	dirty, autoReload := d.Dirty, a.autoReload
+	a.mu.Unlock()
+	a.log.Info("changed on disk", "path", path, "removed", cur == fileStamp{}, "dirty", dirty)
+
+	change := FileChange{
+		ID:         id,
+		Path:       path,
+		OldModTime: old.modTime,
+		NewModTime: cur.modTime,
+		Removed:    cur == fileStamp{},
+		Dirty:      dirty,
+	}
+	if !dirty && autoReload && !change.Removed {
+		if doc, err := a.reloadDocument(id, path); err == nil {
+			change.Reloaded = &doc
+		}
+	}
+	runtime.EventsEmit(a.ctx, "file:changed-externally", change)
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// opacity bounds in percent, below the minimum the window is hard to find
+const (
+	minOpacity     = 30
+	maxOpacity     = 100
+	defaultOpacity = 100
+)
+
+// errOpacityUnsupported is returned by SetOpacity where windows cannot be
+// made translucent. It matches errors.ErrUnsupported.
+var errOpacityUnsupported = fmt.Errorf("window opacity is not supported on this platform: %w", errors.ErrUnsupported)
+
+// ToggleAlwaysOnTop keeps the window above other windows, or stops doing
+// so, and returns whether it now is
+func (a *App) ToggleAlwaysOnTop() bool {
+	a.mu.Lock()
+	on := !a.settings.AlwaysOnTop
+	a.mu.Unlock()
+	s, _ := a.UpdateSettings(map[string]any{"alwaysOnTop": on})
+	return s.AlwaysOnTop
+}
+
+// ToggleFullscreen switches the window in or out of full screen and returns
+// whether it is now full screen
+func (a *App) ToggleFullscreen() bool {
+	if a.ctx == nil {
+		return false
+	}
+	if runtime.WindowIsFullscreen(a.ctx) {
+		runtime.WindowUnfullscreen(a.ctx)
+		return false
+	}
+	runtime.WindowFullscreen(a.ctx)
+	return true
+}
+
+// SetOpacity makes the window translucent, percent being clamped to 30-100,
+// and saves it with the settings
+func (a *App) SetOpacity(percent int) error {
+	if !opacitySupported() {
+		return errOpacityUnsupported
+	}
+	percent = min(maxOpacity, max(minOpacity, percent))
+	if err := setWindowOpacity(percent); err != nil {
+		return err
+	}
+	_, err := a.UpdateSettings(map[string]any{"opacity": percent})
+	return err
+}
+
+// OpacitySupported reports whether SetOpacity works on this platform, so
+// the frontend can leave the control out
+func (a *App) OpacitySupported() bool {
+	return opacitySupported()
+}
+
+// applyWindowSettings puts the saved always on top and opacity settings
+// into effect
+func (a *App) applyWindowSettings() {
+	if a.ctx == nil {
+		return
+	}
+	a.mu.Lock()
+	onTop, opacity := a.settings.AlwaysOnTop, a.settings.Opacity
+	a.mu.Unlock()
+	runtime.WindowSetAlwaysOnTop(a.ctx, onTop)
+	if opacitySupported() {
+		setWindowOpacity(opacity)
+	}
+}
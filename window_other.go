@@ -0,0 +1,13 @@
+//go:build !windows
+
+package main
+
+// opacitySupported is false: WebKitGTK and WKWebView windows are not made
+// translucent through Wails, and on Wayland it is up to the compositor
+func opacitySupported() bool {
+	return false
+}
+
+func setWindowOpacity(percent int) error {
+	return errOpacityUnsupported
+}
@@ -0,0 +1,75 @@
+//go:build windows
+
+package main
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	procEnumWindows                = user32.NewProc("EnumWindows")
+	procGetWindowThreadProcessId   = user32.NewProc("GetWindowThreadProcessId")
+	procGetClassNameW              = user32.NewProc("GetClassNameW")
+	procGetWindowLongW             = user32.NewProc("GetWindowLongW")
+	procSetWindowLongW             = user32.NewProc("SetWindowLongW")
+	procSetLayeredWindowAttributes = user32.NewProc("SetLayeredWindowAttributes")
+)
+
+const (
+	wsExLayered = 0x00080000
+	lwaAlpha    = 0x2
+	// wailsWindowClass is the class of the window Wails creates
+	wailsWindowClass = "wailsWindow"
+)
+
+// gwlExStyle is GWL_EXSTYLE, a variable so it can be passed as a uintptr
+var gwlExStyle int32 = -20
+
+func opacitySupported() bool {
+	return true
+}
+
+// setWindowOpacity makes the main window a layered window with the given
+// alpha. At 100% the layered style is dropped, so it draws as usual.
+func setWindowOpacity(percent int) error {
+	hwnd := mainWindow()
+	if hwnd == 0 {
+		return errors.New("the window is not open")
+	}
+	style, _, _ := procGetWindowLongW.Call(hwnd, uintptr(gwlExStyle))
+	if percent >= maxOpacity {
+		procSetWindowLongW.Call(hwnd, uintptr(gwlExStyle), style&^wsExLayered)
+		return nil
+	}
+	procSetWindowLongW.Call(hwnd, uintptr(gwlExStyle), style|wsExLayered)
+	alpha := uintptr(percent * 255 / 100)
+	if ret, _, err := procSetLayeredWindowAttributes.Call(hwnd, 0, alpha, lwaAlpha); ret == 0 {
+		return err
+	}
+	return nil
+}
+
+// mainWindow finds the Wails window of this process
+func mainWindow() uintptr {
+	pid := uint32(os.Getpid())
+	var found uintptr
+	cb := syscall.NewCallback(func(hwnd, _ uintptr) uintptr {
+		var owner uint32
+		procGetWindowThreadProcessId.Call(hwnd, uintptr(unsafe.Pointer(&owner)))
+		if owner != pid {
+			return 1
+		}
+		var class [64]uint16
+		procGetClassNameW.Call(hwnd, uintptr(unsafe.Pointer(&class[0])), uintptr(len(class)))
+		if syscall.UTF16ToString(class[:]) != wailsWindowClass {
+			return 1
+		}
+		found = hwnd
+		return 0
+	})
+	procEnumWindows.Call(cb, 0)
+	return found
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// FileEntry is one item of a folder listed by ListDir
+type FileEntry struct {
+	Name    string    `json:"name"`
+	Path    string    `json:"path"`
+	IsDir   bool      `json:"isDir"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+}
+
+// DocumentRenamed is sent as a file:renamed event when RenamePath moves the
+// file of an open document
+type DocumentRenamed struct {
+	ID      string `json:"id"`
+	OldPath string `json:"oldPath"`
+	Path    string `json:"path"`
+	Name    string `json:"name"`
+}
+
+// OpenFolder asks the user for a folder and makes it the workspace the
+// file operations below are confined to. Cancelling returns "".
+func (a *App) OpenFolder() (string, error) {
+	dir, err := runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Open Folder",
+	})
+	if err != nil || dir == "" {
+		return "", err
+	}
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return "", newFileError(dir, err)
+	}
+	a.log.Info("opened folder", "path", dir)
+	a.mu.Lock()
+	a.workspace = dir
+	a.mu.Unlock()
+	a.watchTree()
+	return dir, nil
+}
+
+// ListDir returns the entries of a folder, folders first and then by name.
+// Entries that cannot be read are left out.
+func (a *App) ListDir(path string) []FileEntry {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return []FileEntry{}
+	}
+	list := make([]FileEntry, 0, len(entries))
+	for _, e := range entries {
+		full := filepath.Join(path, e.Name())
+		// follow symlinks, so a link to a folder opens like one
+		info, err := os.Stat(full)
+		if err != nil {
+			continue
+		}
+		list = append(list, FileEntry{
+			Name:    e.Name(),
+			Path:    full,
+			IsDir:   info.IsDir(),
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].IsDir != list[j].IsDir {
+			return list[i].IsDir
+		}
+		return strings.ToLower(list[i].Name) < strings.ToLower(list[j].Name)
+	})
+	return list
+}
+
+// CreateFile creates an empty file called name in dir and returns its path
+func (a *App) CreateFile(dir, name string) (string, error) {
+	path, err := a.newWorkspacePath(dir, name)
+	if err != nil {
+		return "", err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return "", newFileError(path, err)
+	}
+	if err := f.Close(); err != nil {
+		return "", newFileError(path, err)
+	}
+	a.log.Info("created file", "path", path)
+	return path, nil
+}
+
+// CreateDir creates a folder called name in dir and returns its path
+func (a *App) CreateDir(dir, name string) (string, error) {
+	path, err := a.newWorkspacePath(dir, name)
+	if err != nil {
+		return "", err
+	}
+	if err := os.Mkdir(path, 0o755); err != nil {
+		return "", newFileError(path, err)
+	}
+	a.log.Info("created folder", "path", path)
+	return path, nil
+}
+
+// RenamePath moves a file or folder within the workspace. Documents open
+// from it follow it to the new path, each with a file:renamed event.
+func (a *App) RenamePath(oldPath, newPath string) error {
+	from, err := a.workspacePath(oldPath)
+	if err != nil {
+		return err
+	}
+	to, err := a.workspacePath(newPath)
+	if err != nil {
+		return err
+	}
+	if err := validFileName(filepath.Base(to)); err != nil {
+		return err
+	}
+	if _, err := os.Lstat(to); err == nil && !strings.EqualFold(from, to) {
+		// os.Rename would silently replace a file
+		return newFileError(to, fs.ErrExist)
+	}
+	if err := os.Rename(from, to); err != nil {
+		a.log.Warn("rename failed", "from", from, "to", to, "err", err)
+		return newFileError(from, err)
+	}
+	a.log.Info("renamed", "from", from, "to", to)
+
+	var renamed []DocumentRenamed
+	a.mu.Lock()
+	a.moveBookmarksLocked(from, to)
+	for _, id := range a.order {
+		d := a.docs[id]
+		rel, ok := underPath(from, d.Path)
+		if !ok {
+			continue
+		}
+		old := d.Path
+		d.Path = filepath.Join(to, rel)
+		a.watchLocked(d)
+		renamed = append(renamed, DocumentRenamed{ID: id, OldPath: old, Path: d.Path, Name: d.Name()})
+	}
+	a.mu.Unlock()
+	for _, r := range renamed {
+		runtime.EventsEmit(a.ctx, "file:renamed", r)
+	}
+	return nil
+}
+
+// DeletePath moves a file or folder in the workspace to the trash
+func (a *App) DeletePath(path string) error {
+	target, err := a.workspacePath(path)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Lstat(target); err != nil {
+		return newFileError(target, err)
+	}
+	if err := moveToTrash(target); err != nil {
+		a.log.Warn("trashing failed", "path", target, "err", err)
+		return newFileError(target, err)
+	}
+	a.log.Info("moved to the trash", "path", target)
+	return nil
+}
+
+// newWorkspacePath joins a new entry name to a folder in the workspace
+func (a *App) newWorkspacePath(dir, name string) (string, error) {
+	if err := validFileName(name); err != nil {
+		return "", err
+	}
+	return a.workspacePath(filepath.Join(dir, name))
+}
+
+// workspacePath resolves path and checks that it is inside the workspace,
+// following symlinks so a link cannot lead out of it. The workspace root
+// itself is refused, it is not for the file operations to change.
+func (a *App) workspacePath(path string) (string, error) {
+	a.mu.Lock()
+	root := a.workspace
+	a.mu.Unlock()
+	if root == "" {
+		return "", &FileError{Code: "no_workspace", Message: "no folder is open"}
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", newFileError(path, err)
+	}
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", newFileError(root, err)
+	}
+	// the entry itself may not exist yet or be a link to act on, so only
+	// its folder is resolved
+	dir, err := filepath.EvalSymlinks(filepath.Dir(abs))
+	if err != nil {
+		return "", newFileError(path, err)
+	}
+	rel, ok := underPath(realRoot, filepath.Join(dir, filepath.Base(abs)))
+	if !ok {
+		return "", &FileError{Code: "outside_workspace", Message: path + " is not inside the open folder"}
+	}
+	if rel == "." {
+		return "", &FileError{Code: "outside_workspace", Message: "the open folder itself cannot be changed"}
+	}
+	return abs, nil
+}
+
+// underPath reports whether path is root or inside it, and returns path
+// relative to root
+func underPath(root, path string) (string, bool) {
+	if path == "" {
+		return "", false
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) || filepath.IsAbs(rel) {
+		return "", false
+	}
+	return rel, true
+}
+
+// validFileName checks a name for a new file or folder
+func validFileName(name string) error {
+	switch {
+	case strings.TrimSpace(name) == "", name == ".", name == "..":
+		return &FileError{Code: "invalid_path", Message: fmt.Sprintf("%q is not a valid name", name)}
+	case strings.ContainsAny(name, `/\`) || strings.ContainsRune(name, 0):
+		return &FileError{Code: "invalid_path", Message: fmt.Sprintf("%q must not contain a path separator", name)}
+	case strings.ContainsAny(name, `<>:"|?*`) && filepath.Separator == '\\':
+		return &FileError{Code: "invalid_path", Message: fmt.Sprintf(`%q must not contain any of <>:"|?*`, name)}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import "math"
+
+// zoom bounds and step, as a factor of the normal size
+const (
+	minZoom     = 0.5
+	maxZoom     = 3.0
+	zoomStep    = 0.1
+	defaultZoom = 1.0
+)
+
+// clampZoom rounds level to the nearest step within the zoom bounds
+func clampZoom(level float64) float64 {
+	if math.IsNaN(level) {
+		return defaultZoom
+	}
+	level = math.Round(level/zoomStep) * zoomStep
+	// undo the float noise from the multiplication, 1.1 not 1.1000000000000001
+	level = math.Round(level*100) / 100
+	return math.Min(maxZoom, math.Max(minZoom, level))
+}
+
+// GetZoom returns the zoom level of the editor
+func (a *App) GetZoom() float64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.settings.Zoom
+}
+
+// SetZoom changes the zoom level and saves it with the settings. The level
+// is clamped to 0.5-3.0 in steps of 0.1 and the value applied is returned.
+func (a *App) SetZoom(level float64) float64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	level = clampZoom(level)
+	if level != a.settings.Zoom {
+		next := a.settings
+		next.Zoom = level
+		if saveSettings(next) == nil {
+			a.settings = next
+		}
+	}
+	return a.settings.Zoom
+}
+
+// ResetZoom goes back to the normal size and returns it
+func (a *App) ResetZoom() float64 {
+	return a.SetZoom(defaultZoom)
+}